@@ -21,14 +21,14 @@ func (a Array) Valid() error {
 	if a.Items == nil {
 		return ErrValidation{
 			Children: map[string]error{
-				"items": errors.New("cannot be nil"),
+				".items": errors.New("cannot be nil"),
 			},
 		}
 	}
 	if err := a.Items.Valid(); err != nil {
 		return ErrValidation{
 			Children: map[string]error{
-				"items": err,
+				".items": err,
 			},
 		}
 	}
@@ -51,7 +51,7 @@ func (a Array) Validate(v interface{}) error {
 		errs := map[string]error{}
 		for i, sv := range s {
 			if err := a.Items.Validate(sv); err != nil {
-				errs[fmt.Sprintf("item at index %d", i)] = err
+				errs[indexPath(i)] = err
 			}
 		}
 		if len(errs) > 0 {
@@ -73,7 +73,7 @@ func (a Array) Validate(v interface{}) error {
 		for i, l := 0, rv.Len(); i < l; i++ {
 			item := rv.Index(i)
 			if err := a.Items.Validate(item.Interface()); err != nil {
-				errs[fmt.Sprintf("item at index %d", l)] = err
+				errs[indexPath(i)] = err
 			}
 		}
 		if len(errs) > 0 {
@@ -0,0 +1,328 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// RecordBuilder builds a Record with chained calls, for example:
+//
+//	user, err := NewRecord("com.example.User").
+//		Field("id", Long).
+//		OptionalField("email", String).
+//		Enum("status", "Status", "ACTIVE", "BANNED").
+//		Build()
+//
+// Named types declared with Enum, Fixed and RecordField, and nested records,
+// take the namespace of the enclosing record unless their name has one.
+// Field types may use unbound References, created with NewReference, to named
+// types declared anywhere in the record, including the record itself. Errors
+// are collected by each call and reported by Build.
+type RecordBuilder struct {
+	nf     NameFields
+	doc    string
+	fields []builderField
+	errs   map[string]error
+}
+
+// builderField is a field, whose type is made by build if it is set.
+type builderField struct {
+	Field
+	build func(namespace string) (Schema, error)
+}
+
+// FieldOption sets an optional attribute of a field added by a RecordBuilder.
+type FieldOption func(*Field)
+
+// WithDefault sets the default of a field, which may be of any Go type
+// accepted by Encode, or a number of any Go type. Build converts it to the
+// value ParseDefault makes for the field type.
+func WithDefault(v interface{}) FieldOption {
+	return func(f *Field) { f.Default = &v }
+}
+
+// WithFieldDoc sets the doc of a field.
+func WithFieldDoc(doc string) FieldOption {
+	return func(f *Field) { f.Doc = doc }
+}
+
+// WithOrder sets the sort order of a field: "ascending", "descending" or
+// "ignore".
+func WithOrder(order string) FieldOption {
+	return func(f *Field) { f.Order = order }
+}
+
+// WithAliases sets the aliases of a field.
+func WithAliases(aliases ...string) FieldOption {
+	return func(f *Field) { f.Aliases = aliases }
+}
+
+// NewRecord returns a RecordBuilder for a record named name, which may
+// include a namespace.
+func NewRecord(name string) *RecordBuilder {
+	return &RecordBuilder{nf: qualify(NameFields{Name: name}, ""), errs: map[string]error{}}
+}
+
+// Namespace sets the namespace of the record, unless its name has one.
+func (b *RecordBuilder) Namespace(namespace string) *RecordBuilder {
+	b.nf = qualify(b.nf, namespace)
+	return b
+}
+
+// Doc sets the doc of the record.
+func (b *RecordBuilder) Doc(doc string) *RecordBuilder {
+	b.doc = doc
+	return b
+}
+
+// Aliases sets the aliases of the record.
+func (b *RecordBuilder) Aliases(aliases ...string) *RecordBuilder {
+	b.nf.Aliases = aliases
+	return b
+}
+
+// Field adds a field of schema s.
+func (b *RecordBuilder) Field(name string, s Schema, opts ...FieldOption) *RecordBuilder {
+	if s == nil {
+		b.errs[fieldPath(name)] = errors.New(`missing type`)
+		return b
+	}
+	return b.add(name, s, nil, opts)
+}
+
+// OptionalField adds a field which is either null or of schema s, with a
+// default of null.
+func (b *RecordBuilder) OptionalField(name string, s Schema, opts ...FieldOption) *RecordBuilder {
+	if s == nil {
+		b.errs[fieldPath(name)] = errors.New(`missing type`)
+		return b
+	}
+	opts = append([]FieldOption{WithDefault(nil)}, opts...)
+	return b.add(name, Union{Null, s}, nil, opts)
+}
+
+// Enum adds a field of a new enum named typeName with the given symbols.
+func (b *RecordBuilder) Enum(name, typeName string, symbols ...string) *RecordBuilder {
+	nf := qualify(NameFields{Name: typeName}, "")
+	return b.add(name, nil, func(namespace string) (Schema, error) {
+		return Enum{NameFields: qualify(nf, namespace), Symbols: symbols}, nil
+	}, nil)
+}
+
+// EnumField is Enum with field options.
+func (b *RecordBuilder) EnumField(name, typeName string, symbols []string, opts ...FieldOption) *RecordBuilder {
+	b.Enum(name, typeName, symbols...)
+	for _, opt := range opts {
+		opt(&b.fields[len(b.fields)-1].Field)
+	}
+	return b
+}
+
+// Fixed adds a field of a new fixed named typeName of size bytes.
+func (b *RecordBuilder) Fixed(name, typeName string, size uint, opts ...FieldOption) *RecordBuilder {
+	nf := qualify(NameFields{Name: typeName}, "")
+	return b.add(name, nil, func(namespace string) (Schema, error) {
+		return Fixed{NameFields: qualify(nf, namespace), Size: size}, nil
+	}, opts)
+}
+
+// RecordField adds a field of the record built by r.
+func (b *RecordBuilder) RecordField(name string, r *RecordBuilder, opts ...FieldOption) *RecordBuilder {
+	if r == nil {
+		b.errs[fieldPath(name)] = errors.New(`missing type`)
+		return b
+	}
+	return b.add(name, nil, func(namespace string) (Schema, error) {
+		return r.build(namespace)
+	}, opts)
+}
+
+func (b *RecordBuilder) add(name string, s Schema, build func(string) (Schema, error), opts []FieldOption) *RecordBuilder {
+	for _, f := range b.fields {
+		if f.Name == name {
+			b.errs[fieldPath(name)] = errors.New(`duplicate field`)
+			return b
+		}
+	}
+	f := builderField{Field: Field{Name: name, Type: s}, build: build}
+	for _, opt := range opts {
+		opt(&f.Field)
+	}
+	b.fields = append(b.fields, f)
+	return b
+}
+
+// Build returns the record, after binding references and checking that it is
+// valid. Errors are an ErrValidation with the path of each problem.
+func (b *RecordBuilder) Build() (Record, error) {
+	r, err := b.build("")
+	if err != nil {
+		return Record{}, err
+	}
+	defined := map[string]Schema{}
+	if err := defineNamed(r, defined); err != nil {
+		return Record{}, err
+	}
+	var refs []Reference
+	collectReferences(r, map[string]bool{}, &refs)
+	for _, ref := range refs {
+		t, ok := defined[ref.Name]
+		if !ok {
+			if _, ok := defined[qualify(NameFields{Name: ref.Name}, r.Namespace).Fullname()]; ok && r.Namespace != "" {
+				return Record{}, fmt.Errorf(`unknown type "%s", references need full names: did you mean "%s.%s"?`, ref.Name, r.Namespace, ref.Name)
+			}
+			return Record{}, fmt.Errorf(`unknown type "%s"`, ref.Name)
+		}
+		if err := ref.Bind(t); err != nil {
+			return Record{}, err
+		}
+	}
+	convertDefaults(r)
+	if err := r.Valid(); err != nil {
+		return Record{}, err
+	}
+	return r, nil
+}
+
+// convertDefaults converts the defaults set with WithDefault in the records
+// of r to the values ParseDefault makes, as if r had been parsed from JSON.
+// Defaults which cannot be converted are left for Valid to report.
+func convertDefaults(r Record) {
+	_ = Walk(r, func(n Node) error {
+		rec, ok := n.Schema.(Record)
+		if !ok {
+			return nil
+		}
+		for i, f := range rec.Fields {
+			if f.Default == nil {
+				continue
+			}
+			if v, err := convertDefault(f.Type, *f.Default); err == nil {
+				rec.Fields[i].Default = &v
+			}
+		}
+		return nil
+	})
+}
+
+// convertDefault converts v, of any Go type accepted by Encode or a number of
+// another Go type, through JSON to the default of schema s.
+func convertDefault(s Schema, v interface{}) (interface{}, error) {
+	if j, err := defaultJSON(s, v); err == nil {
+		v = j
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	j, err := unmarshalDefault(b)
+	if err != nil {
+		return nil, err
+	}
+	return parseDefault(s, j)
+}
+
+// MustBuild is like Build but panics on error. It is meant for schemas known
+// at compile time, such as test fixtures.
+func (b *RecordBuilder) MustBuild() Record {
+	r, err := b.Build()
+	if err != nil {
+		panic(fmt.Sprintf(`avro: build record "%s": %s`, b.nf.Fullname(), err))
+	}
+	return r
+}
+
+// build makes the record and the types of its fields, with namespace as the
+// namespace of the enclosing record.
+func (b *RecordBuilder) build(namespace string) (Record, error) {
+	r := Record{NameFields: qualify(b.nf, namespace), Doc: b.doc, Fields: make([]Field, len(b.fields))}
+	errs := map[string]error{}
+	for k, err := range b.errs {
+		errs[k] = err
+	}
+	if err := r.NameFields.Valid(); err != nil {
+		errs[".name"] = err
+	}
+	for i, f := range b.fields {
+		if f.build != nil {
+			t, err := f.build(r.Namespace)
+			if err != nil {
+				errs[fieldPath(f.Name)] = err
+				continue
+			}
+			f.Type = t
+		}
+		r.Fields[i] = f.Field
+	}
+	if len(errs) > 0 {
+		return Record{}, ErrValidation{Children: errs}
+	}
+	return r, nil
+}
+
+// defineNamed records the named types declared in s by fullname. A name may
+// be declared twice only with the same definition.
+func defineNamed(s Schema, defined map[string]Schema) error {
+	switch s := s.(type) {
+	case Record, Enum, Fixed:
+		name := s.(NamedSchema).Fullname()
+		if d, ok := defined[name]; ok {
+			if !reflect.DeepEqual(d, s) {
+				return fmt.Errorf(`"%s" is defined more than once`, name)
+			}
+			return nil
+		}
+		defined[name] = s
+		if r, ok := s.(Record); ok {
+			for _, f := range r.Fields {
+				if err := defineNamed(f.Type, defined); err != nil {
+					return err
+				}
+			}
+		}
+	case Array:
+		return defineNamed(s.Items, defined)
+	case Map:
+		return defineNamed(s.Values, defined)
+	case Union:
+		for _, t := range s {
+			if err := defineNamed(t, defined); err != nil {
+				return err
+			}
+		}
+	case Logical:
+		return defineNamed(s.Schema, defined)
+	}
+	return nil
+}
+
+// collectReferences appends the unbound references in s to refs. Records in
+// visited are not walked again.
+func collectReferences(s Schema, visited map[string]bool, refs *[]Reference) {
+	switch s := s.(type) {
+	case Reference:
+		if s.Target() == nil {
+			*refs = append(*refs, s)
+		}
+	case Record:
+		if visited[s.Fullname()] {
+			return
+		}
+		visited[s.Fullname()] = true
+		for _, f := range s.Fields {
+			collectReferences(f.Type, visited, refs)
+		}
+	case Array:
+		collectReferences(s.Items, visited, refs)
+	case Map:
+		collectReferences(s.Values, visited, refs)
+	case Union:
+		for _, t := range s {
+			collectReferences(t, visited, refs)
+		}
+	case Logical:
+		collectReferences(s.Schema, visited, refs)
+	}
+}
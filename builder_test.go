@@ -0,0 +1,104 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRecordBuilder(t *testing.T) {
+	is := is.New(t)
+
+	r, err := NewRecord("com.example.User").
+		Doc("a user").
+		Field("id", Long, WithFieldDoc("unique id")).
+		OptionalField("email", String).
+		Enum("status", "Status", "ACTIVE", "BANNED").
+		Fixed("hash", "other.Hash", 4).
+		RecordField("home", NewRecord("Address").Field("street", String)).
+		Field("age", Int, WithDefault(18), WithOrder("descending"), WithAliases("years")).
+		OptionalField("friend", NewReference("com.example.User")).
+		Build()
+	is.NoErr(err)
+	is.Equal(r.Fullname(), "com.example.User")
+	is.Equal(r.Doc, "a user")
+	is.Equal(len(r.Fields), 7)
+
+	f, _ := r.GetField("id")
+	is.Equal(f.Doc, "unique id") // field option
+	f, _ = r.GetField("email")
+	is.Equal(f.Type, Union{Null, String}) // optional is a union with null
+	is.Equal(*f.Default, nil)             // defaulting to null
+	f, _ = r.GetField("status")
+	is.Equal(f.Type.(Enum).Fullname(), "com.example.Status") // enum inherits the namespace
+	f, _ = r.GetField("hash")
+	is.Equal(f.Type.(Fixed).Fullname(), "other.Hash") // unless it has its own
+	f, _ = r.GetField("home")
+	is.Equal(f.Type.(Record).Fullname(), "com.example.Address") // nested record inherits too
+	f, _ = r.GetField("age")
+	is.Equal(*f.Default, int32(18)) // converted to the field type
+	is.Equal(f.Order, "descending")
+	is.Equal(f.Aliases, []string{"years"})
+	f, _ = r.GetField("friend")
+	is.Equal(f.Type.(Union)[1].(Reference).Target().(Record).Fullname(), "com.example.User") // reference is bound
+
+	b, err := Encode(r, map[string]interface{}{
+		"id": 1, "email": nil, "status": GenericEnum{Symbol: "ACTIVE"}, "hash": [4]byte{},
+		"home": map[string]interface{}{"street": "x"}, "age": 3, "friend": nil,
+	})
+	is.NoErr(err) // usable schema
+	is.True(len(b) > 0)
+
+	s := NewRecord("User").Namespace("ns").Field("id", Long).MustBuild()
+	is.Equal(s.Fullname(), "ns.User") // namespace option
+}
+
+func TestRecordBuilder_defaults(t *testing.T) {
+	is := is.New(t)
+
+	r, err := NewRecord("R").
+		Field("ratio", Float, WithDefault(0.5)).
+		Field("n", Long, WithDefault(int32(3))).
+		Field("d", Double, WithDefault(1)).
+		Field("b", Bytes, WithDefault([]byte{0xff})).
+		Field("tags", Array{Items: String}, WithDefault([]string{"a"})).
+		Build()
+	is.NoErr(err) // numbers of other Go types are converted
+	want := map[string]interface{}{
+		"ratio": float32(0.5),
+		"n":     int64(3),
+		"d":     float64(1),
+		"b":     []byte{0xff},
+		"tags":  []interface{}{"a"},
+	}
+	for _, f := range r.Fields {
+		is.Equal(*f.Default, want[f.Name]) // as ParseDefault makes them
+	}
+}
+
+func TestRecordBuilder_errors(t *testing.T) {
+	is := is.New(t)
+
+	_, err := NewRecord("User").Field("id", Long).Field("id", Int).Field("x", nil).Build()
+	is.True(err != nil) // duplicate field and missing type
+	paths := map[string]bool{}
+	for _, pe := range err.(ErrValidation).Flatten() {
+		paths[pe.Path] = true
+	}
+	is.True(paths["$.id"]) // duplicate reported by path
+	is.True(paths["$.x"])  // missing type reported by path
+
+	_, err = NewRecord("1User").Build()
+	is.True(err != nil) // invalid name
+	_, err = NewRecord("User").Field("n", Int, WithDefault("x")).Build()
+	is.True(err != nil) // invalid default
+	_, err = NewRecord("ns.User").Field("next", NewReference("User")).Build()
+	is.True(err != nil) // reference needs a full name
+	_, err = NewRecord("User").Enum("a", "E", "X").Enum("b", "E", "Y").Build()
+	is.True(err != nil) // conflicting definitions
+
+	defer func() {
+		is.True(recover() != nil) // MustBuild panics
+	}()
+	NewRecord("").MustBuild()
+}
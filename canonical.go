@@ -0,0 +1,152 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Canonical returns the Parsing Canonical Form of s, as defined by the
+// specification: attributes irrelevant to reading data, such as docs,
+// aliases, defaults and logical types, are removed, names are replaced by
+// fullnames, primitives are written as strings and no whitespace is used.
+// Schemas with the same canonical form read and write data the same way.
+func Canonical(s Schema) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New(`cannot canonicalize nil schema`)
+	}
+	var b bytes.Buffer
+	if err := writeCanonical(&b, s, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeCanonical writes the canonical form of s to b. Named types already in
+// defined are written as their fullname.
+func writeCanonical(b *bytes.Buffer, s Schema, defined map[string]bool) error {
+	if r, ok := s.(Reference); ok {
+		if r.Target() == nil {
+			return fmt.Errorf(`reference "%s" is not bound`, r.Name)
+		}
+		if defined[r.Name] {
+			writeCanonicalString(b, r.Name)
+			return nil
+		}
+		s = r.Target()
+	}
+	if n, ok := s.(NamedSchema); ok {
+		name := n.Fullname()
+		if defined[name] {
+			writeCanonicalString(b, name)
+			return nil
+		}
+		defined[name] = true
+		b.WriteString(`{"name":`)
+		writeCanonicalString(b, name)
+		b.WriteString(`,"type":`)
+		writeCanonicalString(b, s.Type())
+	}
+	switch s := s.(type) {
+	case Primitive:
+		writeCanonicalString(b, string(s))
+		return nil
+	case Record:
+		b.WriteString(`,"fields":[`)
+		for i, f := range s.Fields {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(`{"name":`)
+			writeCanonicalString(b, f.Name)
+			b.WriteString(`,"type":`)
+			if err := writeCanonical(b, f.Type, defined); err != nil {
+				return fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+			b.WriteByte('}')
+		}
+		b.WriteString(`]}`)
+		return nil
+	case Enum:
+		b.WriteString(`,"symbols":[`)
+		for i, sym := range s.Symbols {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonicalString(b, sym)
+		}
+		b.WriteString(`]}`)
+		return nil
+	case Fixed:
+		b.WriteString(`,"size":`)
+		b.WriteString(strconv.FormatUint(uint64(s.Size), 10))
+		b.WriteByte('}')
+		return nil
+	case Array:
+		b.WriteString(`{"type":"array","items":`)
+		if err := writeCanonical(b, s.Items, defined); err != nil {
+			return err
+		}
+		b.WriteByte('}')
+		return nil
+	case Map:
+		b.WriteString(`{"type":"map","values":`)
+		if err := writeCanonical(b, s.Values, defined); err != nil {
+			return err
+		}
+		b.WriteByte('}')
+		return nil
+	case Union:
+		b.WriteByte('[')
+		for i, t := range s {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeCanonical(b, t, defined); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+		return nil
+	case Logical:
+		return writeCanonical(b, s.Schema, defined)
+	}
+	return fmt.Errorf(`cannot canonicalize schema type "%s"`, s.Type())
+}
+
+func writeCanonicalString(b *bytes.Buffer, s string) {
+	j, _ := json.Marshal(s)
+	b.Write(j)
+}
+
+// emptyFingerprint is the CRC-64-AVRO fingerprint of empty input.
+const emptyFingerprint uint64 = 0xc15d213aa4d7a795
+
+var fingerprintTable = func() [256]uint64 {
+	var t [256]uint64
+	for i := range t {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (emptyFingerprint & -(fp & 1))
+		}
+		t[i] = fp
+	}
+	return t
+}()
+
+// Fingerprint64 returns the 64-bit Rabin fingerprint (CRC-64-AVRO) of the
+// Parsing Canonical Form of s, as used by single-object encoding and schema
+// registries to identify schemas.
+func Fingerprint64(s Schema) (uint64, error) {
+	c, err := Canonical(s)
+	if err != nil {
+		return 0, err
+	}
+	fp := emptyFingerprint
+	for _, b := range c {
+		fp = (fp >> 8) ^ fingerprintTable[byte(fp)^b]
+	}
+	return fp, nil
+}
@@ -0,0 +1,52 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCanonical(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(`{
+		"type": "record", "name": "Foo", "namespace": "x.y", "doc": "a foo",
+		"fields": [
+			{"name": "a", "type": {"type": "array", "items": "Foo"}, "default": []},
+			{"name": "t", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "e", "type": {"type": "enum", "name": "E", "symbols": ["A", "B"], "aliases": ["F"]}},
+			{"name": "f", "type": ["null", {"type": "fixed", "name": "z.F", "size": 2}, "E"], "order": "ignore"},
+			{"name": "m", "type": {"type": "map", "values": {"type": "string"}}}
+		]
+	}`))
+	is.NoErr(err)
+	c, err := Canonical(s)
+	is.NoErr(err)
+	is.Equal(string(c), `{"name":"x.y.Foo","type":"record","fields":[`+
+		`{"name":"a","type":{"type":"array","items":"x.y.Foo"}},`+
+		`{"name":"t","type":"long"},`+
+		`{"name":"e","type":{"name":"x.y.E","type":"enum","symbols":["A","B"]}},`+
+		`{"name":"f","type":["null",{"name":"z.F","type":"fixed","size":2},"x.y.E"]},`+
+		`{"name":"m","type":{"type":"map","values":"string"}}]}`) // stripped, ordered and fully named
+
+	_, err = Canonical(NewReference("Unbound"))
+	is.True(err != nil) // unbound reference
+}
+
+func TestFingerprint64(t *testing.T) {
+	is := is.New(t)
+
+	// Values from the test suite of the specification.
+	fp, err := Fingerprint64(Null)
+	is.NoErr(err)
+	is.Equal(int64(fp), int64(7195948357588979594))
+	fp, err = Fingerprint64(Int)
+	is.NoErr(err)
+	is.Equal(int64(fp), int64(8247732601305521295))
+
+	a, _ := Fingerprint64(Record{NameFields: NameFields{Name: "R"}, Fields: []Field{{Name: "a", Type: Int, Doc: "x"}}})
+	b, _ := Fingerprint64(Record{NameFields: NameFields{Name: "R"}, Fields: []Field{{Name: "a", Type: Int}}})
+	is.Equal(a, b) // docs do not change the fingerprint
+	c, _ := Fingerprint64(Record{NameFields: NameFields{Name: "R"}, Fields: []Field{{Name: "a", Type: Long}}})
+	is.True(a != c) // types do
+}
@@ -0,0 +1,243 @@
+package avro
+
+import (
+	"errors"
+	"sort"
+)
+
+// EqualOption configures SchemasEqual.
+type EqualOption func(*equalOptions)
+
+type equalOptions struct {
+	ignoreDocs    bool
+	ignoreAliases bool
+	canonical     bool
+}
+
+// IgnoreDocs makes SchemasEqual ignore the docs of types and fields.
+func IgnoreDocs() EqualOption {
+	return func(o *equalOptions) { o.ignoreDocs = true }
+}
+
+// IgnoreAliases makes SchemasEqual ignore the aliases of types and fields.
+func IgnoreAliases() EqualOption {
+	return func(o *equalOptions) { o.ignoreAliases = true }
+}
+
+// CanonicalOnly makes SchemasEqual compare the Parsing Canonical Forms of the
+// schemas, which ignores everything but what the binary encoding depends on.
+func CanonicalOnly() EqualOption {
+	return func(o *equalOptions) { o.canonical = true }
+}
+
+// SchemasEqual reports whether schemas a and b are the same: named types have
+// the same full names, aliases, docs and contents, fields the same defaults,
+// orders and types, and so on. References are compared as the types they are
+// bound to, so a schema equals a copy defining its named types elsewhere.
+// Aliases are compared regardless of order.
+func SchemasEqual(a, b Schema, opts ...EqualOption) bool {
+	var o equalOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if o.canonical {
+		ac, err := Canonical(a)
+		if err != nil {
+			return false
+		}
+		bc, err := Canonical(b)
+		return err == nil && string(ac) == string(bc)
+	}
+	c := schemaComparer{equalOptions: o, compared: map[[2]string]bool{}}
+	return c.equal(a, b)
+}
+
+type schemaComparer struct {
+	equalOptions
+	compared map[[2]string]bool // pairs of named types compared, or being compared
+}
+
+func (c schemaComparer) equal(a, b Schema) bool {
+	a, b = resolve(a), resolve(b)
+	// Unbound references are equal to the types they name.
+	if r, ok := a.(Reference); ok {
+		bn, ok := b.(NamedSchema)
+		return ok && bn.Fullname() == r.Name
+	}
+	if r, ok := b.(Reference); ok {
+		an, ok := a.(NamedSchema)
+		return ok && an.Fullname() == r.Name
+	}
+	if an, ok := a.(NamedSchema); ok {
+		bn, ok := b.(NamedSchema)
+		if !ok || a.Type() != b.Type() || !c.namesEqual(an.GetNameFields(), bn.GetNameFields()) {
+			return false
+		}
+		// Recursive types are equal if they are equal elsewhere.
+		pair := [2]string{an.Fullname(), bn.Fullname()}
+		if c.compared[pair] {
+			return true
+		}
+		c.compared[pair] = true
+	}
+	switch a := a.(type) {
+	case Primitive:
+		return a == b
+	case Record:
+		b, ok := b.(Record)
+		if !ok || !c.docsEqual(a.Doc, b.Doc) || len(a.Fields) != len(b.Fields) {
+			return false
+		}
+		for i, af := range a.Fields {
+			if !c.fieldsEqual(af, b.Fields[i]) {
+				return false
+			}
+		}
+		return true
+	case Enum:
+		b, ok := b.(Enum)
+		return ok && c.docsEqual(a.Doc, b.Doc) && stringsEqual(a.Symbols, b.Symbols) && a.Default == b.Default
+	case Fixed:
+		b, ok := b.(Fixed)
+		return ok && a.Size == b.Size
+	case Array:
+		b, ok := b.(Array)
+		return ok && c.equal(a.Items, b.Items)
+	case Map:
+		b, ok := b.(Map)
+		return ok && c.equal(a.Values, b.Values)
+	case Union:
+		b, ok := b.(Union)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !c.equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case Logical:
+		b, ok := b.(Logical)
+		return ok && a.LogicalType == b.LogicalType && a.Precision == b.Precision && a.Scale == b.Scale &&
+			c.equal(a.Schema, b.Schema)
+	}
+	return false
+}
+
+func (c schemaComparer) namesEqual(a, b NameFields) bool {
+	if a.Fullname() != b.Fullname() {
+		return false
+	}
+	if c.ignoreAliases {
+		return true
+	}
+	qualified := func(nf NameFields) []string {
+		aliases := make([]string, len(nf.Aliases))
+		for i, alias := range nf.Aliases {
+			aliases[i] = qualify(NameFields{Name: alias}, nf.Namespace).Fullname()
+		}
+		return aliases
+	}
+	return aliasesEqual(qualified(a), qualified(b))
+}
+
+func (c schemaComparer) docsEqual(a, b string) bool {
+	return c.ignoreDocs || a == b
+}
+
+func (c schemaComparer) fieldsEqual(a, b Field) bool {
+	if a.Name != b.Name || orderOf(a) != orderOf(b) || !c.docsEqual(a.Doc, b.Doc) {
+		return false
+	}
+	if !c.ignoreAliases && !aliasesEqual(a.Aliases, b.Aliases) {
+		return false
+	}
+	if (a.Default == nil) != (b.Default == nil) || a.Default != nil && jsonDefault(a) != jsonDefault(b) {
+		return false
+	}
+	return c.equal(a.Type, b.Type)
+}
+
+// aliasesEqual reports whether a and b have the same aliases in any order.
+func aliasesEqual(a, b []string) bool {
+	a, b = append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return stringsEqual(a, b)
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a deep copy of s, sharing nothing with it: field defaults,
+// aliases and symbols are copied, and References are replaced by new ones
+// bound to the copies of their types. Defaults of Go types other than those
+// made by parsing a schema are copied by value.
+func Clone(s Schema) (Schema, error) {
+	if s == nil {
+		return nil, errors.New(`cannot clone nil schema`)
+	}
+	return Transform(s, func(n Node) (Schema, error) {
+		if f := n.Field; f != nil {
+			f.Aliases = cloneStrings(f.Aliases)
+			if f.Default != nil {
+				d := cloneValue(*f.Default)
+				f.Default = &d
+			}
+		}
+		switch s := n.Schema.(type) {
+		case Record:
+			s.Aliases = cloneStrings(s.Aliases)
+			return s, nil
+		case Enum:
+			s.Aliases = cloneStrings(s.Aliases)
+			s.Symbols = cloneStrings(s.Symbols)
+			return s, nil
+		case Fixed:
+			s.Aliases = cloneStrings(s.Aliases)
+			return s, nil
+		}
+		return n.Schema, nil
+	})
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
+
+// cloneValue deep-copies the values of field defaults.
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return append([]byte{}, v...)
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = cloneValue(item)
+		}
+		return c
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			c[k] = cloneValue(item)
+		}
+		return c
+	}
+	return v
+}
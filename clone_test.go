@@ -0,0 +1,83 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const cloneTestSchema = `{"type": "record", "name": "ns.Node", "doc": "a node", "aliases": ["Vertex"], "fields": [
+	{"name": "id", "type": "long", "doc": "the id", "aliases": ["key"]},
+	{"name": "tags", "type": {"type": "array", "items": "string"}, "default": ["a"]},
+	{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}, "default": "A"},
+	{"name": "next", "type": ["null", "Node"], "default": null},
+	{"name": "other", "type": "Kind"}
+]}`
+
+func TestSchemasEqual(t *testing.T) {
+	is := is.New(t)
+
+	parse := func(spec string) Schema {
+		s, err := SchemaUnmarshalJSON([]byte(spec))
+		is.NoErr(err)
+		return s
+	}
+	s := parse(cloneTestSchema)
+	is.True(SchemasEqual(s, parse(cloneTestSchema))) // parsed twice
+	is.True(SchemasEqual(s, s))
+	is.True(!SchemasEqual(s, nil))
+	is.True(SchemasEqual(Union{Null, Int}, Union{Null, Int}))
+	is.True(!SchemasEqual(Union{Null, Int}, Union{Int, Null})) // branch order matters
+
+	other := parse(`{"type": "record", "name": "Node", "namespace": "ns", "doc": "a vertex", "aliases": ["ns.Vertex"], "fields": [
+		{"name": "id", "type": "long", "aliases": ["key"]},
+		{"name": "tags", "type": {"type": "array", "items": "string"}, "default": ["a"]},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"], "doc": "kinds"}, "default": "A"},
+		{"name": "next", "type": ["null", "Node"], "default": null},
+		{"name": "other", "type": "Kind"}
+	]}`)
+	is.True(!SchemasEqual(s, other))              // docs differ
+	is.True(SchemasEqual(s, other, IgnoreDocs())) // namespaces and aliases qualified
+
+	renamed := parse(`{"type": "record", "name": "ns.Node", "fields": [
+		{"name": "id", "type": "long"},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}},
+		{"name": "next", "type": ["null", "Node"]},
+		{"name": "other", "type": "Kind"}
+	]}`)
+	is.True(!SchemasEqual(s, renamed, IgnoreDocs(), IgnoreAliases())) // defaults differ
+	is.True(SchemasEqual(s, renamed, CanonicalOnly()))                // same canonical form
+
+	changed := parse(`{"type": "record", "name": "ns.Node", "fields": [{"name": "id", "type": "int"}]}`)
+	is.True(!SchemasEqual(s, changed, CanonicalOnly()))
+}
+
+func TestClone(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(cloneTestSchema))
+	is.NoErr(err)
+	c, err := Clone(s)
+	is.NoErr(err)
+	is.NoErr(c.Valid())
+	is.True(SchemasEqual(s, c)) // same schema
+
+	r := c.(Record)
+	r.Aliases[0] = "Changed"
+	r.Fields[0].Aliases[0] = "changed"
+	(*r.Fields[1].Default).([]interface{})[0] = "changed"
+	kind := resolve(r.Fields[2].Type).(Enum)
+	kind.Symbols[0] = "Z"
+	orig := s.(Record)
+	is.Equal(orig.Aliases[0], "Vertex")                           // aliases copied
+	is.Equal(orig.Fields[0].Aliases[0], "key")                    // field aliases copied
+	is.Equal((*orig.Fields[1].Default).([]interface{})[0], "a")   // defaults copied
+	is.Equal(resolve(orig.Fields[4].Type).(Enum).Symbols[0], "A") // symbols copied
+	is.Equal(resolve(r.Fields[4].Type).(Enum).Symbols[0], "Z")    // references bound to the copies
+	next := resolve(r.Fields[3].Type.(Union)[1]).(Record)
+	is.Equal(next.Aliases[0], "Changed") // recursive reference bound to the copy
+
+	_, err = Clone(NewReference("Unbound"))
+	is.True(err != nil) // unbound reference
+}
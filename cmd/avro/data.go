@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/athiwatp/go.avro"
+)
+
+// openContainer opens the container file at path. The returned function
+// closes it.
+func (e *env) openContainer(path string) (*avro.Reader, func() error, error) {
+	f, err := e.open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := avro.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf(`%s: %s`, path, err)
+	}
+	return r, f.Close, nil
+}
+
+func getSchema(fs *flag.FlagSet, e *env) error {
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	r, closeFile, err := e.openContainer(e.args[0])
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+	var b bytes.Buffer
+	if err := json.Indent(&b, r.Metadata()[avro.MetaSchema], "", "  "); err != nil {
+		return err
+	}
+	b.WriteByte('\n')
+	_, err = b.WriteTo(e.stdout)
+	return err
+}
+
+func getMeta(fs *flag.FlagSet, e *env) error {
+	key := fs.String("key", "", "print only the value of this key")
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	r, closeFile, err := e.openContainer(e.args[0])
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+	meta := r.Metadata()
+	if *key != "" {
+		v, ok := meta[*key]
+		if !ok {
+			return fmt.Errorf(`no metadata "%s"`, *key)
+		}
+		_, err := fmt.Fprintf(e.stdout, "%s\n", v)
+		return err
+	}
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(e.stdout, "%s\t%s\n", k, meta[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// metaFlag collects the metadata given by -meta flags.
+type metaFlag []avro.WriterOption
+
+func (m *metaFlag) String() string {
+	return ""
+}
+
+func (m *metaFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf(`metadata "%s" is not key=value`, s)
+	}
+	*m = append(*m, avro.WithMetadata(k, []byte(v)))
+	return nil
+}
+
+func toJSON(fs *flag.FlagSet, e *env) error {
+	pretty := fs.Bool("pretty", false, "indent the JSON")
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	r, closeFile, err := e.openContainer(e.args[0])
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+	for n := 1; ; n++ {
+		var v interface{}
+		if err := r.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf(`value %d: %s`, n, err)
+		}
+		j, err := avro.EncodeJSON(r.Schema(), v)
+		if err != nil {
+			return fmt.Errorf(`value %d: %s`, n, err)
+		}
+		if *pretty {
+			var b bytes.Buffer
+			if err := json.Indent(&b, j, "", "  "); err != nil {
+				return fmt.Errorf(`value %d: %s`, n, err)
+			}
+			j = b.Bytes()
+		}
+		if _, err := fmt.Fprintf(e.stdout, "%s\n", j); err != nil {
+			return err
+		}
+	}
+}
+
+func fromJSON(fs *flag.FlagSet, e *env) error {
+	schema := fs.String("schema", "", "schema of the values, as a file or JSON")
+	codec := fs.String("codec", avro.CodecNull, "codec of the output")
+	var meta metaFlag
+	fs.Var(&meta, "meta", "add `key=value` to the metadata of the output, repeatable")
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	if *schema == "" {
+		fs.Usage()
+		return errors.New(`missing -schema`)
+	}
+	s, err := loadSchema(*schema)
+	if err != nil {
+		return err
+	}
+	f, err := e.open(e.args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w, err := avro.NewWriter(e.stdout, s, append(meta, avro.WithCodec(*codec))...)
+	if err != nil {
+		return err
+	}
+	err = eachJSON(f, func(n int, raw []byte) error {
+		var v interface{}
+		if err := avro.DecodeJSON(s, raw, &v); err != nil {
+			return fmt.Errorf(`value %d: %s`, n, err)
+		}
+		return w.Append(v)
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// eachJSON calls fn with each JSON value of r, numbered from 1.
+func eachJSON(r io.Reader, fn func(n int, raw []byte) error) error {
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf(`value %d: %s`, n, err)
+		}
+		if err := fn(n, raw); err != nil {
+			return err
+		}
+	}
+}
+
+// copier copies values from container files with the same schema to a
+// container file, created with the schema and codec of the first.
+type copier struct {
+	e      *env
+	codec  string
+	w      *avro.Writer
+	schema []byte // canonical form of the schema
+}
+
+// each calls fn with each value of the files, after checking that the schema
+// of each file matches the first.
+func (c *copier) each(paths []string, fn func(v interface{}) error) error {
+	for _, path := range paths {
+		r, closeFile, err := c.e.openContainer(path)
+		if err != nil {
+			return err
+		}
+		err = c.copy(path, r, fn)
+		closeFile()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *copier) copy(path string, r *avro.Reader, fn func(v interface{}) error) error {
+	if err := c.start(path, r); err != nil {
+		return err
+	}
+	for n := 1; ; n++ {
+		var v interface{}
+		if err := r.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf(`%s: value %d: %s`, path, n, err)
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+}
+
+// start creates the output for the first file, and checks that the schema of
+// each other file matches it.
+func (c *copier) start(path string, r *avro.Reader) error {
+	canonical, err := avro.Canonical(r.Schema())
+	if err != nil {
+		return err
+	}
+	if c.w == nil {
+		codec := c.codec
+		if codec == "" {
+			codec = r.Codec()
+		}
+		if c.w, err = avro.NewWriter(c.e.stdout, r.Schema(), avro.WithCodec(codec)); err != nil {
+			return err
+		}
+		c.schema = canonical
+	} else if !bytes.Equal(canonical, c.schema) {
+		return fmt.Errorf(`%s: schema differs from the first file`, path)
+	}
+	return nil
+}
+
+func (c *copier) close() error {
+	if c.w == nil {
+		return nil
+	}
+	return c.w.Close()
+}
+
+// errStop stops copying early.
+var errStop = errors.New(`stop`)
+
+func cat(fs *flag.FlagSet, e *env) error {
+	offset := fs.Int64("offset", 0, "number of values to skip")
+	limit := fs.Int64("limit", -1, "maximum number of values to copy, or -1 for all")
+	rate := fs.Float64("samplerate", 1, "fraction of values to copy, evenly spaced")
+	codec := fs.String("codec", "", "codec of the output (default the codec of the first file)")
+	if err := e.flags(fs, 1, -1); err != nil {
+		return err
+	}
+	if *rate <= 0 || *rate > 1 {
+		return fmt.Errorf(`sample rate %v is not in (0, 1]`, *rate)
+	}
+	c := copier{e: e, codec: *codec}
+	var seen, sampled, copied int64
+	err := c.each(e.args, func(v interface{}) error {
+		seen++
+		if seen <= *offset {
+			return nil
+		}
+		// Copy a value whenever the expected number of sampled values
+		// reaches a new integer.
+		i := seen - *offset
+		want := int64(float64(i) * *rate)
+		if want == sampled {
+			return nil
+		}
+		sampled = want
+		if *limit >= 0 && copied >= *limit {
+			return errStop
+		}
+		copied++
+		return c.w.Append(v)
+	})
+	if err != nil && err != errStop {
+		return err
+	}
+	return c.close()
+}
+
+func concat(fs *flag.FlagSet, e *env) error {
+	codec := fs.String("codec", "", "codec of the output (default the codec of the first file)")
+	if err := e.flags(fs, 1, -1); err != nil {
+		return err
+	}
+	c := copier{e: e, codec: *codec}
+	for _, path := range e.args {
+		r, closeFile, err := e.openContainer(path)
+		if err != nil {
+			return err
+		}
+		// Blocks are copied without decoding their values.
+		err = c.start(path, r)
+		if err == nil {
+			if err = c.w.AppendFrom(r); err != nil {
+				err = fmt.Errorf(`%s: %s`, path, err)
+			}
+		}
+		closeFile()
+		if err != nil {
+			return err
+		}
+	}
+	return c.close()
+}
+
+func repair(fs *flag.FlagSet, e *env) error {
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	f, err := e.open(e.args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var bad int
+	kept, err := avro.Repair(e.stdout, f, func(b avro.BadBlock) {
+		bad++
+		fmt.Fprintf(e.stderr, "offset %d: skipped %d bytes: %s\n", b.Offset, b.Length, b.Err)
+	})
+	if err != nil {
+		return fmt.Errorf(`%s: %s`, e.args[0], err)
+	}
+	fmt.Fprintf(e.stderr, "kept %d values, skipped %d bad blocks\n", kept, bad)
+	return nil
+}
+
+func count(fs *flag.FlagSet, e *env) error {
+	verify := fs.Bool("verify", false, "skip every value to check that the blocks hold as many as they count")
+	if err := e.flags(fs, 1, -1); err != nil {
+		return err
+	}
+	var total int64
+	for _, path := range e.args {
+		r, closeFile, err := e.openContainer(path)
+		if err != nil {
+			return err
+		}
+		for {
+			var n int64
+			if *verify {
+				err = r.Skip()
+				n = 1
+			} else {
+				var b avro.Block
+				b, err = r.ReadBlock()
+				n = b.Count
+			}
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				closeFile()
+				return fmt.Errorf(`%s: %s`, path, err)
+			}
+			total += n
+		}
+		closeFile()
+	}
+	_, err := fmt.Fprintln(e.stdout, total)
+	return err
+}
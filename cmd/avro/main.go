@@ -0,0 +1,153 @@
+// Command avro works with Avro schemas and object container files.
+//
+// Usage:
+//
+//	avro command [flags] args...
+//
+// The commands are:
+//
+//	getschema     print the schema of a container file
+//	getmeta       print the metadata of a container file
+//	tojson        print the values of a container file as JSON, one per line
+//	fromjson      write JSON values, one per line, to a container file
+//	cat           copy values of container files, with offset, limit and sampling
+//	concat        concatenate container files with the same schema
+//	count         count the values of container files
+//	repair        copy a container file without its bad blocks
+//	canonical     print the Parsing Canonical Form of a schema
+//	fingerprint   print the fingerprint of a schema
+//	validate      check JSON or container data against a schema
+//	compat        check that a reader schema can read data of a writer schema
+//	lint          report style and safety issues of a schema
+//	diff          report the changes between two schemas
+//	idl2schemata  write the named types of an IDL file as schema files
+//
+// Schemas are given as a path to a schema file or as inline JSON. Files named
+// "-" are standard input, and container files are written to standard output.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/athiwatp/go.avro"
+)
+
+// command is a subcommand of avro.
+type command struct {
+	usage string // arguments, after the command name
+	doc   string
+	run   func(fs *flag.FlagSet, env *env) error
+}
+
+// env is what commands read from and write to.
+type env struct {
+	args   []string // command line, and then arguments left by flags
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+var commands = map[string]command{
+	"getschema":    {"file", "print the schema of a container file", getSchema},
+	"getmeta":      {"[-key key] file", "print the metadata of a container file", getMeta},
+	"tojson":       {"[-pretty] file", "print the values of a container file as JSON, one per line", toJSON},
+	"fromjson":     {"-schema schema [-codec codec] [-meta key=value]... file", "write JSON values, one per line, to a container file", fromJSON},
+	"cat":          {"[-offset n] [-limit n] [-samplerate rate] [-codec codec] files...", "copy values of container files", cat},
+	"concat":       {"[-codec codec] files...", "concatenate container files with the same schema", concat},
+	"count":        {"[-verify] files...", "count the values of container files", count},
+	"repair":       {"file", "copy a container file without its bad blocks, reported on standard error", repair},
+	"canonical":    {"schema", "print the Parsing Canonical Form of a schema", canonical},
+	"fingerprint":  {"[-algorithm crc64|md5|sha256] schema", "print the fingerprint of a schema", fingerprint},
+	"validate":     {"-schema schema file", "check JSON or container data against a schema", validate},
+	"compat":       {"reader writer", "check that a reader schema can read data of a writer schema", compat},
+	"lint":         {"[-config file] [-json] schema", "report style and safety issues of a schema", lint},
+	"diff":         {"[-json] old new", "report the changes between two schemas", diff},
+	"idl2schemata": {"file [dir]", "write the named types of an IDL file as schema files", idl2schemata},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "avro: %s\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		usage(stderr)
+		return errors.New(`missing command`)
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage(stderr)
+		return fmt.Errorf(`unknown command "%s"`, args[0])
+	}
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: avro %s %s\n", args[0], cmd.usage)
+		fs.PrintDefaults()
+	}
+	return cmd.run(fs, &env{args: args[1:], stdin: stdin, stdout: stdout, stderr: stderr})
+}
+
+// flags parses the command line with the flags defined on fs and checks the
+// number of arguments left is between min and max, where max < 0 is
+// unbounded.
+func (e *env) flags(fs *flag.FlagSet, min, max int) error {
+	if err := fs.Parse(e.args); err != nil {
+		return err
+	}
+	e.args = fs.Args()
+	if len(e.args) < min || max >= 0 && len(e.args) > max {
+		fs.Usage()
+		return errors.New(`wrong number of arguments`)
+	}
+	return nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "usage: avro command [flags] args...\n\ncommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-13s %s\n", name, commands[name].doc)
+	}
+}
+
+// open opens path for reading, or returns standard input for "-".
+func (e *env) open(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(e.stdin), nil
+	}
+	return os.Open(path)
+}
+
+// loadSchema parses a schema given inline as JSON, or else read from the file
+// at arg.
+func loadSchema(arg string) (avro.Schema, error) {
+	spec := []byte(arg)
+	if t := strings.TrimSpace(arg); !strings.HasPrefix(t, "{") && !strings.HasPrefix(t, "[") && !strings.HasPrefix(t, `"`) {
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, err
+		}
+		spec = b
+	}
+	s, err := avro.SchemaUnmarshalJSON(spec)
+	if err != nil {
+		return nil, fmt.Errorf(`schema %s: %s`, arg, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const testSchema = `{"type": "record", "name": "ns.Item", "doc": "an item", "fields": [
+	{"name": "id", "type": "long"},
+	{"name": "note", "type": ["null", "string"], "default": null}
+]}`
+
+// runCmd runs a command and returns its output.
+func runCmd(t *testing.T, stdin []byte, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(args, bytes.NewReader(stdin), &out, io.Discard)
+	return out.String(), err
+}
+
+func TestRun_usage(t *testing.T) {
+	is := is.New(t)
+
+	var out, errOut bytes.Buffer
+	is.True(run(nil, nil, &out, &errOut) != nil)                      // missing command
+	is.True(strings.Contains(errOut.String(), "usage: avro command")) // usage on the given stderr
+	errOut.Reset()
+	is.True(run([]string{"count", "-x"}, nil, &out, &errOut) != nil) // unknown flag
+	is.True(strings.Contains(errOut.String(), "usage: avro count"))
+	is.Equal(out.Len(), 0) // nothing on stdout
+}
+
+func TestRun_data(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "item.avsc")
+	is.NoErr(os.WriteFile(schema, []byte(testSchema), 0o644))
+
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, `{"id": `+string(rune('0'+i))+`, "note": {"string": "n"}}`)
+	}
+	data, err := runCmd(t, []byte(strings.Join(lines, "\n")), "fromjson", "-schema", schema, "-codec", "deflate",
+		"-meta", "job=42", "-meta", "producer=test", "-")
+	is.NoErr(err) // writes JSON to a container
+	file := filepath.Join(dir, "items.avro")
+	is.NoErr(os.WriteFile(file, []byte(data), 0o644))
+
+	out, err := runCmd(t, nil, "count", file, file)
+	is.NoErr(err)
+	is.Equal(out, "20\n") // counts every file
+	out, err = runCmd(t, nil, "count", "-verify", file)
+	is.NoErr(err)
+	is.Equal(out, "10\n") // counts by skipping values
+
+	data, err = runCmd(t, []byte(data[:len(data)-5]), "repair", "-")
+	is.NoErr(err)
+	out, err = runCmd(t, []byte(data), "count", "-")
+	is.NoErr(err)
+	is.Equal(out, "0\n") // truncated block dropped
+
+	out, err = runCmd(t, nil, "getschema", file)
+	is.NoErr(err)
+	is.True(strings.Contains(out, `"name": "Item"`)) // indented schema
+
+	out, err = runCmd(t, nil, "getmeta", file)
+	is.NoErr(err)
+	is.True(strings.Contains(out, "avro.codec\tdeflate\n")) // metadata lines
+	out, err = runCmd(t, nil, "getmeta", "-key", "avro.codec", file)
+	is.NoErr(err)
+	is.Equal(out, "deflate\n") // single key
+	out, err = runCmd(t, nil, "getmeta", "-key", "job", file)
+	is.NoErr(err)
+	is.Equal(out, "42\n") // user metadata
+	_, err = runCmd(t, nil, "fromjson", "-schema", schema, "-meta", "avro.job=1", "-")
+	is.True(err != nil) // reserved key
+	_, err = runCmd(t, nil, "fromjson", "-schema", schema, "-meta", "job", "-")
+	is.True(err != nil) // not key=value
+
+	out, err = runCmd(t, nil, "tojson", file)
+	is.NoErr(err)
+	got := strings.Split(strings.TrimSpace(out), "\n")
+	is.Equal(len(got), 10)
+	is.Equal(got[3], `{"id":3,"note":{"string":"n"}}`) // union branch names
+
+	data, err = runCmd(t, nil, "cat", "-offset", "2", "-limit", "3", "-codec", "null", file)
+	is.NoErr(err)
+	out, err = runCmd(t, []byte(data), "tojson", "-")
+	is.NoErr(err)
+	is.Equal(out, `{"id":2,"note":{"string":"n"}}`+"\n"+`{"id":3,"note":{"string":"n"}}`+"\n"+
+		`{"id":4,"note":{"string":"n"}}`+"\n") // offset and limit
+
+	data, err = runCmd(t, nil, "cat", "-samplerate", "0.5", file)
+	is.NoErr(err)
+	out, err = runCmd(t, []byte(data), "count", "-")
+	is.NoErr(err)
+	is.Equal(out, "5\n") // sampled
+
+	data, err = runCmd(t, nil, "concat", file, file)
+	is.NoErr(err)
+	out, err = runCmd(t, []byte(data), "count", "-")
+	is.NoErr(err)
+	is.Equal(out, "20\n") // concatenated
+	data, err = runCmd(t, nil, "concat", "-codec", "null", file, file)
+	is.NoErr(err)
+	out, err = runCmd(t, []byte(data), "tojson", "-")
+	is.NoErr(err)
+	is.Equal(strings.Count(out, "\n"), 20) // recompressed
+
+	other := filepath.Join(dir, "other.avro")
+	data, err = runCmd(t, []byte(`1`), "fromjson", "-schema", `"int"`, "-")
+	is.NoErr(err)
+	is.NoErr(os.WriteFile(other, []byte(data), 0o644))
+	_, err = runCmd(t, nil, "concat", file, other)
+	is.True(err != nil) // schemas differ
+
+	_, err = runCmd(t, []byte(`{"id": "x"}`), "fromjson", "-schema", schema, "-")
+	is.True(err != nil) // invalid JSON value
+}
+
+func TestRun_schema(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+
+	out, err := runCmd(t, nil, "canonical", testSchema)
+	is.NoErr(err)
+	is.Equal(out, `{"name":"ns.Item","type":"record","fields":[{"name":"id","type":"long"},`+
+		`{"name":"note","type":["null","string"]}]}`+"\n")
+
+	out, err = runCmd(t, nil, "fingerprint", `"null"`)
+	is.NoErr(err)
+	is.Equal(out, "63dd24e7cc258f8a\n") // CRC-64-AVRO
+	out, err = runCmd(t, nil, "fingerprint", "-algorithm", "md5", `"null"`)
+	is.NoErr(err)
+	is.Equal(out, "9b41ef67651c18488a8b08bb67c75699\n")
+	_, err = runCmd(t, nil, "fingerprint", "-algorithm", "sha1", `"null"`)
+	is.True(err != nil) // unknown algorithm
+
+	out, err = runCmd(t, []byte(`{"id": 1} {"id": 2, "note": {"string": "x"}}`), "validate", "-schema", testSchema, "-")
+	is.NoErr(err)
+	is.Equal(out, "2 values are valid\n")
+	out, err = runCmd(t, []byte(`{"id": 1} {"id": "2"}`), "validate", "-schema", testSchema, "-")
+	is.True(err != nil)                               // invalid values
+	is.True(strings.HasPrefix(out, "value 2: field")) // reports each invalid value
+
+	data, err := runCmd(t, []byte(`{"id": 1}`), "fromjson", "-schema", testSchema, "-")
+	is.NoErr(err)
+	out, err = runCmd(t, []byte(data), "validate", "-schema", `{"type": "record", "name": "Item", "fields": [{"name": "id", "type": "double"}]}`, "-")
+	is.NoErr(err)
+	is.Equal(out, "1 values are valid\n") // container values are checked through JSON
+	_, err = runCmd(t, []byte(data), "validate", "-schema", `{"type": "record", "name": "Item", "fields": [{"name": "id", "type": "string"}]}`, "-")
+	is.True(err != nil) // invalid container values
+
+	out, err = runCmd(t, nil, "compat", `{"type": "record", "name": "Item", "fields": [{"name": "id", "type": "double"}]}`, testSchema)
+	is.NoErr(err)
+	is.Equal(out, "compatible\n")
+	out, err = runCmd(t, nil, "compat", `{"type": "record", "name": "Item", "fields": [{"name": "id", "type": "int"}]}`, testSchema)
+	is.True(err != nil)                       // incompatible
+	is.True(strings.HasPrefix(out, "$.id: ")) // paths of incompatibilities
+
+	idl := filepath.Join(dir, "shop.avdl")
+	is.NoErr(os.WriteFile(idl, []byte(`@namespace("shop") protocol Shop {
+		enum Size { S, L }
+		record Shirt { Size size; }
+	}`), 0o644))
+	out, err = runCmd(t, nil, "idl2schemata", idl, filepath.Join(dir, "out"))
+	is.NoErr(err)
+	is.Equal(strings.Count(out, "\n"), 2) // a file per type
+	spec, err := os.ReadFile(filepath.Join(dir, "out", "Shirt.avsc"))
+	is.NoErr(err)
+	is.True(strings.Contains(string(spec), `"symbols"`)) // standalone schema
+
+	config := filepath.Join(dir, "lint.json")
+	is.NoErr(os.WriteFile(config, []byte(`{"field-doc": {"disabled": true}}`), 0o644))
+	out, err = runCmd(t, nil, "lint", "-config", config, testSchema)
+	is.NoErr(err) // warnings only
+	is.Equal(out, "$.id: warning: field has no default (field-default)\n")
+	out, err = runCmd(t, nil, "lint", "-json", "-config", config, testSchema)
+	is.NoErr(err)
+	is.True(strings.HasPrefix(out, "[\n  {\n    \"rule\": \"field-default\"")) // machine-readable
+	_, err = runCmd(t, nil, "lint", `{"type": "enum", "name": "E", "symbols": ["A"], "aliases": ["E"], "default": "A"}`)
+	is.True(err != nil) // errors fail
+
+	out, err = runCmd(t, nil, "diff", testSchema, `{"type": "record", "name": "ns.Item", "fields": [{"name": "id", "type": "long"}]}`)
+	is.NoErr(err)
+	is.Equal(out, "impact: compatible\n$: doc changed from \"an item\" to \"\" [none]\n"+
+		"$.note: field \"note\" removed, it had a default [compatible]\n")
+	out, err = runCmd(t, nil, "diff", "-json", testSchema, `{"type": "record", "name": "ns.Item", "fields": [{"name": "id", "type": "string"}]}`)
+	is.True(err != nil)                                              // breaking
+	is.True(strings.HasPrefix(out, "{\n  \"impact\": \"breaking\"")) // machine-readable
+
+	_, err = runCmd(t, nil, "nope")
+	is.True(err != nil) // unknown command
+	_, err = runCmd(t, nil, "count")
+	is.True(err != nil) // missing argument
+}
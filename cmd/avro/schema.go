@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/athiwatp/go.avro"
+)
+
+func canonical(fs *flag.FlagSet, e *env) error {
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	s, err := loadSchema(e.args[0])
+	if err != nil {
+		return err
+	}
+	c, err := avro.Canonical(s)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(e.stdout, "%s\n", c)
+	return err
+}
+
+func fingerprint(fs *flag.FlagSet, e *env) error {
+	algorithm := fs.String("algorithm", "crc64", "fingerprint algorithm: crc64, md5 or sha256")
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	s, err := loadSchema(e.args[0])
+	if err != nil {
+		return err
+	}
+	var fp []byte
+	switch *algorithm {
+	case "crc64":
+		n, err := avro.Fingerprint64(s)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(e.stdout, "%016x\n", n)
+		return err
+	case "md5", "sha256":
+		c, err := avro.Canonical(s)
+		if err != nil {
+			return err
+		}
+		if *algorithm == "md5" {
+			sum := md5.Sum(c)
+			fp = sum[:]
+		} else {
+			sum := sha256.Sum256(c)
+			fp = sum[:]
+		}
+	default:
+		return fmt.Errorf(`unknown algorithm "%s"`, *algorithm)
+	}
+	_, err = fmt.Fprintf(e.stdout, "%x\n", fp)
+	return err
+}
+
+// validate checks each value of a container file, or each JSON value of any
+// other file, against a schema. Container values are checked through their
+// JSON encoding, so they are valid if a value with the same JSON exists in
+// the schema.
+func validate(fs *flag.FlagSet, e *env) error {
+	schema := fs.String("schema", "", "schema to check against, as a file or JSON")
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	if *schema == "" {
+		fs.Usage()
+		return errors.New(`missing -schema`)
+	}
+	s, err := loadSchema(*schema)
+	if err != nil {
+		return err
+	}
+	f, err := e.open(e.args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var total, invalid int
+	check := func(n int, raw []byte) error {
+		total++
+		var v interface{}
+		if err := avro.DecodeJSON(s, raw, &v); err != nil {
+			invalid++
+			_, err = fmt.Fprintf(e.stdout, "value %d: %s\n", n, err)
+			return err
+		}
+		return nil
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(data, []byte("Obj\x01")) {
+		err = eachContainerJSON(bytes.NewReader(data), check)
+	} else {
+		err = eachJSON(bytes.NewReader(data), check)
+	}
+	if err != nil {
+		return err
+	}
+	if invalid > 0 {
+		return fmt.Errorf(`%d of %d values are invalid`, invalid, total)
+	}
+	_, err = fmt.Fprintf(e.stdout, "%d values are valid\n", total)
+	return err
+}
+
+// eachContainerJSON calls fn with the JSON encoding of each value of a
+// container file, numbered from 1.
+func eachContainerJSON(r io.Reader, fn func(n int, raw []byte) error) error {
+	cr, err := avro.NewReader(r)
+	if err != nil {
+		return err
+	}
+	for n := 1; ; n++ {
+		var v interface{}
+		if err := cr.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf(`value %d: %s`, n, err)
+		}
+		j, err := avro.EncodeJSON(cr.Schema(), v)
+		if err != nil {
+			return fmt.Errorf(`value %d: %s`, n, err)
+		}
+		if err := fn(n, j); err != nil {
+			return err
+		}
+	}
+}
+
+func compat(fs *flag.FlagSet, e *env) error {
+	if err := e.flags(fs, 2, 2); err != nil {
+		return err
+	}
+	reader, err := loadSchema(e.args[0])
+	if err != nil {
+		return err
+	}
+	writer, err := loadSchema(e.args[1])
+	if err != nil {
+		return err
+	}
+	err = avro.CheckCompatibility(reader, writer)
+	if err == nil {
+		_, err = fmt.Fprintln(e.stdout, "compatible")
+		return err
+	}
+	var v avro.ErrValidation
+	if !errors.As(err, &v) {
+		return err
+	}
+	for _, pe := range v.Flatten() {
+		if _, err := fmt.Fprintln(e.stdout, pe); err != nil {
+			return err
+		}
+	}
+	return errors.New(`reader cannot read data of writer`)
+}
+
+// lint prints the diagnostics of a schema, one per line or as a JSON array,
+// and fails if any has severity error.
+func lint(fs *flag.FlagSet, e *env) error {
+	configPath := fs.String("config", "", "JSON file configuring rules by name")
+	asJSON := fs.Bool("json", false, "print diagnostics as JSON")
+	if err := e.flags(fs, 1, 1); err != nil {
+		return err
+	}
+	s, err := loadSchema(e.args[0])
+	if err != nil {
+		return err
+	}
+	var config avro.LintConfig
+	if *configPath != "" {
+		b, err := os.ReadFile(*configPath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, &config); err != nil {
+			return fmt.Errorf(`%s: %s`, *configPath, err)
+		}
+	}
+	diags, err := avro.Lint(s, config)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if diags == nil {
+			diags = []avro.Diagnostic{}
+		}
+		b, err := json.MarshalIndent(diags, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(e.stdout, "%s\n", b); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range diags {
+			if _, err := fmt.Fprintln(e.stdout, d); err != nil {
+				return err
+			}
+		}
+	}
+	errs := 0
+	for _, d := range diags {
+		if d.Severity == avro.SeverityError {
+			errs++
+		}
+	}
+	if errs > 0 {
+		return fmt.Errorf(`%d lint errors`, errs)
+	}
+	return nil
+}
+
+// diff prints the changes between two schemas, as text or JSON, and fails if
+// they are breaking.
+func diff(fs *flag.FlagSet, e *env) error {
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := e.flags(fs, 2, 2); err != nil {
+		return err
+	}
+	old, err := loadSchema(e.args[0])
+	if err != nil {
+		return err
+	}
+	new, err := loadSchema(e.args[1])
+	if err != nil {
+		return err
+	}
+	r, err := avro.Diff(old, new)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(e.stdout, "%s\n", b)
+		if err != nil {
+			return err
+		}
+	} else if _, err := fmt.Fprint(e.stdout, r); err != nil {
+		return err
+	}
+	if r.Impact == avro.ImpactBreaking {
+		return errors.New(`changes are breaking`)
+	}
+	return nil
+}
+
+func idl2schemata(fs *flag.FlagSet, e *env) error {
+	if err := e.flags(fs, 1, 2); err != nil {
+		return err
+	}
+	dir := "."
+	if len(e.args) == 2 {
+		dir = e.args[1]
+	}
+	p, err := avro.ParseIDLFile(e.args[0])
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, t := range p.Types {
+		s, err := avro.Standalone(t)
+		if err != nil {
+			return err
+		}
+		spec, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		name := t.(avro.NamedSchema).GetNameFields().Name
+		path := filepath.Join(dir, name+".avsc")
+		if err := os.WriteFile(path, append(spec, '\n'), 0o644); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(e.stdout, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
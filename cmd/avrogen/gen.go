@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/athiwatp/go.avro"
+)
+
+// load reads the named types declared in a schema, protocol or IDL file.
+// Schema files are added to ss, so they may refer to the types of each
+// other, and their references are bound once ss is resolved.
+func load(ss *avro.SchemaSet, path string) ([]avro.Schema, error) {
+	switch filepath.Ext(path) {
+	case ".avdl":
+		p, err := avro.ParseIDLFile(path)
+		return p.Types, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Ext(path) {
+	case ".avsc":
+		s, err := ss.Add(data, path)
+		if err != nil {
+			return nil, err
+		}
+		return []avro.Schema{s}, nil
+	case ".avpr":
+		p, err := avro.ParseProtocol(data)
+		return p.Types, err
+	}
+	return nil, fmt.Errorf(`unknown file type "%s", want .avsc, .avpr or .avdl`, filepath.Ext(path))
+}
+
+// generator writes Go declarations for named types and the unions they use.
+type generator struct {
+	pkg     string
+	types   []avro.Schema     // named types in order of definition
+	names   map[string]string // Go names by full name
+	unions  map[string]avro.Union
+	imports map[string]bool
+	decls   map[string]string // what declared each package-level Go name
+}
+
+func newGenerator(pkg string) *generator {
+	return &generator{
+		pkg:     pkg,
+		names:   map[string]string{},
+		unions:  map[string]avro.Union{},
+		imports: map[string]bool{},
+		decls:   map[string]string{},
+	}
+}
+
+// declare records that what declares the package-level Go name ident, and
+// returns an error if something else already does.
+func (g *generator) declare(ident, what string) error {
+	if other, ok := g.decls[ident]; ok {
+		return fmt.Errorf(`%s and %s would both be named "%s"`, other, what, ident)
+	}
+	g.decls[ident] = what
+	return nil
+}
+
+// add adds s and every named type it uses.
+func (g *generator) add(s avro.Schema) error {
+	switch s := s.(type) {
+	case avro.Reference:
+		if s.Target() == nil {
+			return fmt.Errorf(`reference "%s" is unbound`, s.Name)
+		}
+		return g.add(s.Target())
+	case avro.Record, avro.Enum, avro.Fixed:
+		fullname := s.(avro.NamedSchema).Fullname()
+		if _, ok := g.names[fullname]; ok {
+			return nil
+		}
+		name := exported(s.(avro.NamedSchema).GetNameFields().Name)
+		for other, n := range g.names {
+			if n == name {
+				return fmt.Errorf(`types "%s" and "%s" would both be named "%s"`, other, fullname, name)
+			}
+		}
+		g.names[fullname] = name
+		g.types = append(g.types, s)
+		if r, ok := s.(avro.Record); ok {
+			for _, f := range r.Fields {
+				if err := g.add(f.Type); err != nil {
+					return err
+				}
+			}
+		}
+	case avro.Array:
+		return g.add(s.Items)
+	case avro.Map:
+		return g.add(s.Values)
+	case avro.Union:
+		for _, b := range s {
+			if err := g.add(b); err != nil {
+				return err
+			}
+		}
+	case avro.Logical:
+		return g.add(s.Schema)
+	}
+	return nil
+}
+
+// generate returns the formatted Go source for every type added.
+func (g *generator) generate() ([]byte, error) {
+	if err := g.declare("mustParseSchema", "the schema parser"); err != nil {
+		return nil, err
+	}
+	for _, s := range g.types {
+		n := s.(avro.NamedSchema)
+		name := g.names[n.Fullname()]
+		what := fmt.Sprintf(`%s "%s"`, s.Type(), n.Fullname())
+		idents := []string{name, name + "Schema"}
+		if _, ok := s.(avro.Enum); ok {
+			idents = append(idents, "Parse"+name)
+		}
+		for _, ident := range idents {
+			if err := g.declare(ident, what); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var body bytes.Buffer
+	for _, s := range g.types {
+		var err error
+		switch s := s.(type) {
+		case avro.Record:
+			err = g.record(&body, s)
+		case avro.Enum:
+			err = g.enum(&body, s)
+		case avro.Fixed:
+			err = g.fixed(&body, s)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	names := make([]string, 0, len(g.unions))
+	for name := range g.unions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := g.declare(name, "union "+unionDescription(g.unions[name])); err != nil {
+			return nil, err
+		}
+		if err := g.union(&body, name, g.unions[name]); err != nil {
+			return nil, err
+		}
+	}
+	body.WriteString(`
+func mustParseSchema(spec string) avro.Schema {
+	s, err := avro.SchemaUnmarshalJSON([]byte(spec))
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+`)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by avrogen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg)
+	var imports []string
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString("\n\t\"github.com/athiwatp/go.avro\"\n)\n")
+	out.Write(body.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf(`format generated code: %s`, err)
+	}
+	return src, nil
+}
+
+func (g *generator) record(w *bytes.Buffer, r avro.Record) error {
+	name := g.names[r.Fullname()]
+	fmt.Fprintf(w, "\n// %s is generated from the Avro record %q.\n", name, r.Fullname())
+	writeDoc(w, "", r.Doc, true)
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	used := map[string]bool{}
+	for _, f := range r.Fields {
+		t, err := g.goType(f.Type)
+		if err != nil {
+			return fmt.Errorf(`record "%s" field "%s": %s`, r.Fullname(), f.Name, err)
+		}
+		fieldName := exported(f.Name)
+		for used[fieldName] {
+			fieldName += "_"
+		}
+		used[fieldName] = true
+		writeDoc(w, "\t", f.Doc, false)
+		fmt.Fprintf(w, "\t%s %s `avro:%q`\n", fieldName, t, f.Name)
+	}
+	w.WriteString("}\n")
+	return g.schemaVar(w, name, r, "Record")
+}
+
+func (g *generator) enum(w *bytes.Buffer, e avro.Enum) error {
+	g.imports["fmt"] = true
+	name := g.names[e.Fullname()]
+	fmt.Fprintf(w, "\n// %s is generated from the Avro enum %q. Its values are the ordinals\n// of the symbols.\n", name, e.Fullname())
+	writeDoc(w, "", e.Doc, true)
+	fmt.Fprintf(w, "type %s int32\n\nconst (\n", name)
+	for i, sym := range e.Symbols {
+		constant := name + exported(strings.ToLower(sym))
+		if err := g.declare(constant, fmt.Sprintf(`symbol "%s" of enum "%s"`, sym, e.Fullname())); err != nil {
+			return err
+		}
+		if i == 0 {
+			fmt.Fprintf(w, "\t%s %s = iota\n", constant, name)
+		} else {
+			fmt.Fprintf(w, "\t%s\n", constant)
+		}
+	}
+	w.WriteString(")\n")
+	if err := g.schemaVar(w, name, e, "Enum"); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, `
+// String returns the symbol of v.
+func (v %[1]s) String() string {
+	if v < 0 || int(v) >= len(%[1]sSchema.Symbols) {
+		return fmt.Sprintf("%[1]s(%%d)", int32(v))
+	}
+	return %[1]sSchema.Symbols[v]
+}
+
+// Parse%[1]s returns the %[1]s with the given symbol.
+func Parse%[1]s(symbol string) (%[1]s, error) {
+	for i, sym := range %[1]sSchema.Symbols {
+		if sym == symbol {
+			return %[1]s(i), nil
+		}
+	}
+	return 0, fmt.Errorf("symbol %%q does not exist in enum %%q", symbol, %[2]q)
+}
+`, name, e.Fullname())
+	return nil
+}
+
+func (g *generator) fixed(w *bytes.Buffer, f avro.Fixed) error {
+	name := g.names[f.Fullname()]
+	fmt.Fprintf(w, "\n// %s is generated from the Avro fixed %q.\ntype %s [%d]byte\n", name, f.Fullname(), name, f.Size)
+	return g.schemaVar(w, name, f, "Fixed")
+}
+
+// schemaVar writes the variable holding the schema of a generated type.
+func (g *generator) schemaVar(w *bytes.Buffer, name string, s avro.Schema, kind string) error {
+	standalone, err := avro.Standalone(s)
+	if err != nil {
+		return err
+	}
+	spec, err := json.Marshal(standalone)
+	if err != nil {
+		return fmt.Errorf(`marshal schema of %s: %s`, name, err)
+	}
+	fmt.Fprintf(w, "\n// %sSchema is the Avro schema of %s.\nvar %sSchema = mustParseSchema(%s).(avro.%s)\n", name, name, name, goString(spec), kind)
+	return nil
+}
+
+// union writes a wrapper type for a union which is not just optional.
+func (g *generator) union(w *bytes.Buffer, name string, u avro.Union) error {
+	fmt.Fprintf(w, "\n// %s holds a value of the Avro union %s.\n", name, unionDescription(u))
+	fmt.Fprintf(w, "type %s struct {\n\t// Index is the position in the union of the schema of the value.\n\tIndex int\n", name)
+	fields := make([]string, len(u))
+	for i, b := range u {
+		if b == avro.Null {
+			continue
+		}
+		t, err := g.goType(b)
+		if err != nil {
+			return err
+		}
+		// Records are held by pointer, as they may contain the union.
+		if _, ok := avro.Resolve(b).(avro.Record); ok {
+			t = "*" + t
+		}
+		fields[i] = g.branchName(b)
+		fmt.Fprintf(w, "\t%s %s\n", fields[i], t)
+	}
+	w.WriteString("}\n")
+
+	fmt.Fprintf(w, "\n// UnionIndex implements avro.UnionWrapper.\nfunc (u %s) UnionIndex() int { return u.Index }\n", name)
+	fmt.Fprintf(w, "\n// UnionValue implements avro.UnionWrapper.\nfunc (u %s) UnionValue() interface{} {\n\tswitch u.Index {\n", name)
+	for i, f := range fields {
+		if f != "" {
+			fmt.Fprintf(w, "\tcase %d:\n\t\treturn u.%s\n", i, f)
+		}
+	}
+	w.WriteString("\t}\n\treturn nil\n}\n")
+	fmt.Fprintf(w, "\n// SetUnionIndex implements avro.UnionWrapper.\nfunc (u *%s) SetUnionIndex(i int) interface{} {\n\tu.Index = i\n\tswitch i {\n", name)
+	for i, f := range fields {
+		if f == "" {
+			continue
+		}
+		if _, ok := avro.Resolve(u[i]).(avro.Record); ok {
+			t, _ := g.goType(u[i])
+			fmt.Fprintf(w, "\tcase %d:\n\t\tu.%s = new(%s)\n\t\treturn u.%s\n", i, f, t, f)
+		} else {
+			fmt.Fprintf(w, "\tcase %d:\n\t\treturn &u.%s\n", i, f)
+		}
+	}
+	w.WriteString("\t}\n\treturn nil\n}\n")
+	return nil
+}
+
+// goType returns the Go type used for values of s.
+func (g *generator) goType(s avro.Schema) (string, error) {
+	switch s := avro.Resolve(s).(type) {
+	case avro.Primitive:
+		switch s {
+		case avro.Boolean:
+			return "bool", nil
+		case avro.Int:
+			return "int32", nil
+		case avro.Long:
+			return "int64", nil
+		case avro.Float:
+			return "float32", nil
+		case avro.Double:
+			return "float64", nil
+		case avro.Bytes:
+			return "[]byte", nil
+		case avro.String:
+			return "string", nil
+		}
+		return "", fmt.Errorf(`type "%s" has no Go type outside a union`, s)
+	case avro.Record, avro.Enum, avro.Fixed:
+		return g.names[s.(avro.NamedSchema).Fullname()], nil
+	case avro.Logical:
+		switch s.LogicalType {
+		case avro.LogicalDate, avro.LogicalTimestampMillis, avro.LogicalTimestampMicros:
+			g.imports["time"] = true
+			return "time.Time", nil
+		case avro.LogicalTimeMillis, avro.LogicalTimeMicros:
+			g.imports["time"] = true
+			return "time.Duration", nil
+		}
+		return g.goType(s.Schema)
+	case avro.Array:
+		t, err := g.goType(s.Items)
+		return "[]" + t, err
+	case avro.Map:
+		t, err := g.goType(s.Values)
+		return "map[string]" + t, err
+	case avro.Union:
+		if len(s) == 2 && (s[0] == avro.Null) != (s[1] == avro.Null) {
+			other := s[0]
+			if other == avro.Null {
+				other = s[1]
+			}
+			t, err := g.goType(other)
+			return "*" + t, err
+		}
+		base := "Union"
+		for _, b := range s {
+			base += g.branchName(b)
+		}
+		// Unions whose branches differ only inside, such as arrays of int and
+		// of long, are numbered.
+		name := base
+		for i := 2; ; i++ {
+			u, ok := g.unions[name]
+			if !ok {
+				g.unions[name] = s
+				return name, nil
+			}
+			if avro.SchemasEqual(u, s) {
+				return name, nil
+			}
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+	}
+	return "", fmt.Errorf(`unsupported type "%s"`, s.Type())
+}
+
+// branchName names the field of a union wrapper holding values of s.
+func (g *generator) branchName(s avro.Schema) string {
+	switch s := avro.Resolve(s).(type) {
+	case avro.Record, avro.Enum, avro.Fixed:
+		return g.names[s.(avro.NamedSchema).Fullname()]
+	case avro.Logical:
+		return exported(strings.ReplaceAll(s.LogicalType, "-", "_"))
+	}
+	return exported(avro.Resolve(s).Type())
+}
+
+func unionDescription(u avro.Union) string {
+	names := make([]string, len(u))
+	for i, b := range u {
+		if n, ok := b.(avro.NamedSchema); ok {
+			names[i] = strconv.Quote(n.Fullname())
+		} else {
+			names[i] = strconv.Quote(b.Type())
+		}
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// initialisms are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true,
+	"uri": true, "url": true, "uuid": true,
+}
+
+// exported turns an Avro name into an exported Go name, treating "_" as a
+// word separator.
+func exported(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		r := []rune(part)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
+
+// writeDoc writes doc as a comment, separated from a preceding comment if
+// continued is true.
+func writeDoc(w *bytes.Buffer, indent, doc string, continued bool) {
+	if doc == "" {
+		return
+	}
+	if continued {
+		w.WriteString(indent + "//\n")
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		w.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+}
+
+// goString quotes b as a raw string literal when possible.
+func goString(b []byte) string {
+	if bytes.IndexByte(b, '`') < 0 {
+		return "`" + string(b) + "`"
+	}
+	return strconv.Quote(string(b))
+}
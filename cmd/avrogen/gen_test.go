@@ -0,0 +1,232 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/athiwatp/go.avro"
+	"github.com/matryer/is"
+)
+
+const testIDL = `
+@namespace("org.example")
+protocol Shop {
+	/** Sizes of shirts. */
+	enum Size { SMALL, EXTRA_LARGE }
+	fixed Sku(4);
+	record Item {
+		Sku sku;
+		Size size = "SMALL";
+		union { null, string, Item } related = null;
+		array<Item> parts = [];
+		timestamp_ms added;
+		string? note;
+	}
+	Item lookup(Sku sku);
+}
+`
+
+func TestGenerate(t *testing.T) {
+	is := is.New(t)
+
+	p, err := avro.ParseIDL([]byte(testIDL))
+	is.NoErr(err)
+	g := newGenerator("shop")
+	for _, s := range p.Types {
+		is.NoErr(g.add(s))
+	}
+	src, err := g.generate()
+	is.NoErr(err)
+	_, err = parser.ParseFile(token.NewFileSet(), "gen.go", src, 0)
+	is.NoErr(err) // generates valid Go
+
+	// Compare with white space collapsed, ignoring alignment.
+	code := strings.Join(strings.Fields(string(src)), " ")
+	for _, want := range []string{
+		"package shop",
+		"type Size int32",
+		"SizeSmall Size = iota",
+		"SizeExtraLarge )",
+		"func (v Size) String() string",
+		"func ParseSize(symbol string) (Size, error)",
+		"type Sku [4]byte",
+		"Sku Sku `avro:\"sku\"`",
+		"Related UnionNullStringItem `avro:\"related\"`",
+		"Parts []Item `avro:\"parts\"`",
+		"Added time.Time `avro:\"added\"`",
+		"Note *string `avro:\"note\"`",
+		"var ItemSchema = mustParseSchema(",
+		"func (u *UnionNullStringItem) SetUnionIndex(i int) interface{}",
+	} {
+		is.True(strings.Contains(code, want)) // generated code contains declaration
+	}
+}
+
+func TestGenerate_nameConflict(t *testing.T) {
+	is := is.New(t)
+
+	g := newGenerator("p")
+	is.NoErr(g.add(avro.Fixed{NameFields: avro.NameFields{Name: "Id", Namespace: "a"}, Size: 1}))
+	err := g.add(avro.Fixed{NameFields: avro.NameFields{Name: "Id", Namespace: "b"}, Size: 1})
+	is.True(err != nil) // types with the same Go name
+
+	g = newGenerator("p")
+	is.NoErr(g.add(avro.Enum{NameFields: avro.NameFields{Name: "E"}, Symbols: []string{"A_B", "a_b"}}))
+	_, err = g.generate()
+	is.Equal(err.Error(), `symbol "A_B" of enum "E" and symbol "a_b" of enum "E" would both be named "EAB"`) // symbols with the same Go name
+
+	g = newGenerator("p")
+	is.NoErr(g.add(avro.Enum{NameFields: avro.NameFields{Name: "E"}, Symbols: []string{"X"}}))
+	is.NoErr(g.add(avro.Fixed{NameFields: avro.NameFields{Name: "EX"}, Size: 1}))
+	_, err = g.generate()
+	is.True(err != nil) // symbol with the Go name of a type
+}
+
+func TestGenerate_unionNames(t *testing.T) {
+	is := is.New(t)
+
+	s, err := avro.SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [
+		{"name": "a", "type": [{"type": "array", "items": "int"}, "string"]},
+		{"name": "b", "type": [{"type": "array", "items": "long"}, "string"]},
+		{"name": "c", "type": [{"type": "array", "items": "int"}, "string"]}
+	]}`))
+	is.NoErr(err)
+	g := newGenerator("p")
+	is.NoErr(g.add(s))
+	src, err := g.generate()
+	is.NoErr(err)
+	code := strings.Join(strings.Fields(string(src)), " ")
+	for _, want := range []string{
+		"A UnionArrayString `avro:\"a\"`",
+		"B UnionArrayString2 `avro:\"b\"`",
+		"C UnionArrayString `avro:\"c\"`",
+		"type UnionArrayString struct { // Index is the position in the union of the schema of the value. Index int Array []int32",
+		"type UnionArrayString2 struct { // Index is the position in the union of the schema of the value. Index int Array []int64",
+	} {
+		is.True(strings.Contains(code, want)) // unions which differ inside have their own wrappers
+	}
+}
+
+// TestGenerate_roundTrip builds the generated code in a module of its own and
+// runs it to encode and decode a value.
+func TestGenerate_roundTrip(t *testing.T) {
+	is := is.New(t)
+
+	goTool, err := exec.LookPath("go")
+	if err != nil || testing.Short() {
+		t.Skip("needs the go tool")
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	is.NoErr(err)
+	if _, err := os.Stat(filepath.Join(root, "go.mod")); err != nil {
+		t.Skip("the avro package is not in a module")
+	}
+	p, err := avro.ParseIDL([]byte(testIDL))
+	is.NoErr(err)
+	g := newGenerator("main")
+	for _, s := range p.Types {
+		is.NoErr(g.add(s))
+	}
+	src, err := g.generate()
+	is.NoErr(err)
+
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module gentest\n\ngo 1.21\n\n"+
+		"require github.com/athiwatp/go.avro v0.0.0\n\nreplace github.com/athiwatp/go.avro => "+strconv.Quote(root)+"\n"), 0o644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "shop.go"), src, 0o644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "main.go"), []byte(roundTripMain), 0o644))
+	for _, args := range [][]string{{"vet", "."}, {"run", "."}} {
+		cmd := exec.Command(goTool, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("go %s: %s\n%s", args[0], err, out)
+		}
+	}
+}
+
+const roundTripMain = `package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/athiwatp/go.avro"
+)
+
+func main() {
+	note := "gift"
+	in := Item{
+		Sku:     Sku{1, 2, 3, 4},
+		Size:    SizeExtraLarge,
+		Related: UnionNullStringItem{Index: 2, Item: &Item{Parts: []Item{}, Added: time.UnixMilli(1).UTC()}},
+		Parts:   []Item{{Sku: Sku{5}, Parts: []Item{}, Added: time.UnixMilli(2).UTC()}},
+		Added:   time.UnixMilli(3).UTC(),
+		Note:    &note,
+	}
+	data, err := avro.Encode(ItemSchema, in)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	var out Item
+	if err := avro.Decode(ItemSchema, data, &out); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if !reflect.DeepEqual(in, out) {
+		fmt.Printf("decoded %+v, want %+v\n", out, in)
+		os.Exit(1)
+	}
+	if s, err := ParseSize("EXTRA_LARGE"); err != nil || s != out.Size || s.String() != "EXTRA_LARGE" {
+		fmt.Println("enum symbols do not match", s, err)
+		os.Exit(1)
+	}
+}
+`
+
+func TestRun(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "user.avsc")
+	is.NoErr(os.WriteFile(schema, []byte(`{
+		"type": "record", "name": "User", "namespace": "com.example",
+		"fields": [{"name": "user_id", "type": "long"}, {"name": "email", "type": ["null", "string"]}]
+	}`), 0o644))
+	out := filepath.Join(dir, "user.go")
+	is.NoErr(run("users", out, []string{schema}))
+	src, err := os.ReadFile(out)
+	is.NoErr(err)
+	code := strings.Join(strings.Fields(string(src)), " ")
+	is.True(strings.Contains(code, "UserID int64 `avro:\"user_id\"`")) // field from schema file
+	order := filepath.Join(dir, "order.avsc")
+	is.NoErr(os.WriteFile(order, []byte(`{
+		"type": "record", "name": "com.example.Order", "fields": [{"name": "buyer", "type": "com.example.User"}]
+	}`), 0o644))
+	is.NoErr(run("users", out, []string{order, schema})) // types of other files
+	src, err = os.ReadFile(out)
+	is.NoErr(err)
+	is.True(strings.Contains(string(src), "Buyer User"))
+	is.True(run("users", out, []string{order}) != nil)                          // unknown type
+	is.True(run("users", out, []string{filepath.Join(dir, "user.txt")}) != nil) // unknown file type
+}
+
+func TestExported(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(exported("name"), "Name")
+	is.Equal(exported("user_id"), "UserID")
+	is.Equal(exported("firstName"), "FirstName")
+	is.Equal(exported("_x"), "X")
+	is.Equal(exported("9lives"), "X9lives")
+}
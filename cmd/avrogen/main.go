@@ -0,0 +1,81 @@
+// Command avrogen generates Go types from Avro schema (.avsc), protocol
+// (.avpr) and IDL (.avdl) files.
+//
+// Usage:
+//
+//	avrogen [-package name] [-o file] files...
+//
+// Every named type declared in the files, or used by them, becomes a Go type
+// with an "avro" tag on each field and a variable holding its schema:
+//
+//   - a record becomes a struct,
+//   - an enum becomes an int32 type with a constant for each symbol, a String
+//     method and a Parse function,
+//   - a fixed becomes a byte array.
+//
+// A union of null and one other type becomes a pointer. Other unions become
+// wrapper structs which implement avro.UnionWrapper. All files for a package
+// should be generated by one run, as each output declares the same helper.
+// Schema files may use the types of the other schema files of the run by
+// full name.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/athiwatp/go.avro"
+)
+
+func main() {
+	pkg := flag.String("package", "main", "package name of the generated code")
+	out := flag.String("o", "", "output file (default standard output)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: avrogen [-package name] [-o file] files...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*pkg, *out, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "avrogen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(pkg, out string, paths []string) error {
+	ss := avro.NewSchemaSet()
+	types := make([][]avro.Schema, len(paths))
+	for i, path := range paths {
+		var err error
+		if types[i], err = load(ss, path); err != nil {
+			return fmt.Errorf(`%s: %s`, path, err)
+		}
+	}
+	if err := ss.Resolve(); err != nil {
+		return err
+	}
+	g := newGenerator(pkg)
+	for i, path := range paths {
+		for _, t := range types[i] {
+			if err := t.Valid(); err != nil {
+				return fmt.Errorf(`%s: %s`, path, err)
+			}
+			if err := g.add(t); err != nil {
+				return fmt.Errorf(`%s: %s`, path, err)
+			}
+		}
+	}
+	src, err := g.generate()
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
@@ -0,0 +1,240 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Compare compares a and b according to the sort order of s, returning -1, 0
+// or +1. Record fields are compared in order, honoring their "order"
+// attribute, and union values compare by branch first. Maps cannot be
+// compared. The values are encoded with Encode and compared with
+// CompareBinary, so they may have different Go types.
+func Compare(s Schema, a, b interface{}) (int, error) {
+	ab, err := Encode(s, a)
+	if err != nil {
+		return 0, fmt.Errorf(`encode first value: %s`, err)
+	}
+	bb, err := Encode(s, b)
+	if err != nil {
+		return 0, fmt.Errorf(`encode second value: %s`, err)
+	}
+	return CompareBinary(s, ab, bb)
+}
+
+// CompareBinary compares two binary encoded values of schema s according to
+// the sort order of s, returning -1, 0 or +1. Values are read only as far as
+// needed to find a difference and are never fully decoded.
+func CompareBinary(s Schema, a, b []byte) (int, error) {
+	if s == nil {
+		return 0, errors.New(`cannot compare with nil schema`)
+	}
+	da, db := decoder{buf: a}, decoder{buf: b}
+	return compareBinary(s, &da, &db)
+}
+
+func compareBinary(s Schema, a, b *decoder) (int, error) {
+	switch s := resolve(s).(type) {
+	case Primitive:
+		return comparePrimitive(s, a, b)
+	case Record:
+		for _, f := range s.Fields {
+			if f.Order == "ignore" {
+				if err := a.skip(f.Type); err != nil {
+					return 0, err
+				}
+				if err := b.skip(f.Type); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			c, err := compareBinary(f.Type, a, b)
+			if err != nil {
+				return 0, fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+			if c != 0 {
+				if f.Order == "descending" {
+					return -c, nil
+				}
+				return c, nil
+			}
+		}
+		return 0, nil
+	case Enum:
+		return compareLongs(a, b)
+	case Fixed:
+		ab, err := a.next(int(s.Size))
+		if err != nil {
+			return 0, err
+		}
+		bb, err := b.next(int(s.Size))
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(ab, bb), nil
+	case Array:
+		ai, bi := blockItems{d: a, items: s.Items}, blockItems{d: b, items: s.Items}
+		for {
+			aok, err := ai.next()
+			if err != nil {
+				return 0, err
+			}
+			bok, err := bi.next()
+			if err != nil {
+				return 0, err
+			}
+			switch {
+			case !aok && !bok:
+				return 0, nil
+			case !aok:
+				return -1, nil
+			case !bok:
+				return 1, nil
+			}
+			if c, err := compareBinary(s.Items, a, b); c != 0 || err != nil {
+				return c, err
+			}
+		}
+	case Map:
+		return 0, errors.New(`maps cannot be compared`)
+	case Union:
+		ai, err := a.readUnionIndex(s)
+		if err != nil {
+			return 0, err
+		}
+		bi, err := b.readUnionIndex(s)
+		if err != nil {
+			return 0, err
+		}
+		if ai != bi {
+			return compareInts(int64(ai), int64(bi)), nil
+		}
+		return compareBinary(s[ai], a, b)
+	case Logical:
+		return compareBinary(s.Schema, a, b)
+	}
+	return 0, fmt.Errorf(`cannot compare schema type "%s"`, s.Type())
+}
+
+func comparePrimitive(p Primitive, a, b *decoder) (int, error) {
+	switch p {
+	case Null:
+		return 0, nil
+	case Boolean:
+		ab, err := a.readBool()
+		if err != nil {
+			return 0, err
+		}
+		bb, err := b.readBool()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case ab == bb:
+			return 0, nil
+		case bb:
+			return -1, nil
+		}
+		return 1, nil
+	case Int, Long:
+		return compareLongs(a, b)
+	case Float:
+		af, err := a.readFloat()
+		if err != nil {
+			return 0, err
+		}
+		bf, err := b.readFloat()
+		if err != nil {
+			return 0, err
+		}
+		return compareFloats(float64(af), float64(bf)), nil
+	case Double:
+		af, err := a.readDouble()
+		if err != nil {
+			return 0, err
+		}
+		bf, err := b.readDouble()
+		if err != nil {
+			return 0, err
+		}
+		return compareFloats(af, bf), nil
+	case Bytes, String:
+		// Comparing UTF-8 bytes orders strings by code point.
+		ab, err := a.readBytes()
+		if err != nil {
+			return 0, err
+		}
+		bb, err := b.readBytes()
+		if err != nil {
+			return 0, err
+		}
+		return bytes.Compare(ab, bb), nil
+	}
+	return 0, fmt.Errorf(`cannot compare schema type "%s"`, p)
+}
+
+func compareLongs(a, b *decoder) (int, error) {
+	an, err := a.readLong()
+	if err != nil {
+		return 0, err
+	}
+	bn, err := b.readLong()
+	if err != nil {
+		return 0, err
+	}
+	return compareInts(an, bn), nil
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFloats orders NaN after every other value, as Java does.
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	case a != a && b != b:
+		return 0
+	case a != a:
+		return 1
+	}
+	return -1
+}
+
+// blockItems steps through the items of an array or map encoded as blocks.
+type blockItems struct {
+	d     *decoder
+	items Schema // items of an array, or nil for a map
+	left  int
+	done  bool
+}
+
+// next reports whether there is another item, reading the next block count
+// when the current block is exhausted.
+func (bi *blockItems) next() (bool, error) {
+	for bi.left == 0 && !bi.done {
+		n, err := bi.d.readBlockCount(bi.items)
+		if err != nil {
+			return false, err
+		}
+		bi.left = n
+		bi.done = n == 0
+	}
+	if bi.done {
+		return false, nil
+	}
+	bi.left--
+	return true, nil
+}
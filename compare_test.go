@@ -0,0 +1,122 @@
+package avro
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCompare_primitives(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		schema Schema
+		a, b   interface{}
+		want   int
+	}{
+		{Null, nil, nil, 0},
+		{Boolean, false, true, -1},
+		{Boolean, true, true, 0},
+		{Int, -5, 3, -1},
+		{Long, int64(7), int64(-7), 1},
+		{Float, float32(1.5), float32(1.5), 0},
+		{Double, math.NaN(), 1.0, 1},
+		{Double, -1.0, math.NaN(), -1},
+		{Bytes, []byte{0x01, 0xff}, []byte{0x02}, -1},
+		{String, "b", "ab", 1},
+		{String, "ab", "abc", -1},
+		{String, "é", "\U0001F600", -1},
+	}
+	for _, tt := range tests {
+		c, err := Compare(tt.schema, tt.a, tt.b)
+		is.NoErr(err)        // compares without error
+		is.Equal(c, tt.want) // order matches the spec
+	}
+}
+
+func TestCompare_complex(t *testing.T) {
+	is := is.New(t)
+
+	kind := Enum{NameFields: NameFields{Name: "Kind"}, Symbols: []string{"Z", "A"}}
+	r := Record{
+		NameFields: NameFields{Name: "Key"},
+		Fields: []Field{
+			{Name: "kind", Type: kind},
+			{Name: "score", Type: Int, Order: "descending"},
+			{Name: "note", Type: String, Order: "ignore"},
+			{Name: "tags", Type: Array{Items: String}},
+		},
+	}
+	type key struct {
+		Kind  string   `avro:"kind"`
+		Score int      `avro:"score"`
+		Note  string   `avro:"note"`
+		Tags  []string `avro:"tags"`
+	}
+
+	c, err := Compare(r, key{Kind: "Z"}, key{Kind: "A"})
+	is.NoErr(err)   // compares records
+	is.Equal(c, -1) // enums compare by ordinal
+
+	c, err = Compare(r, key{Kind: "A", Score: 1}, key{Kind: "A", Score: 2})
+	is.NoErr(err)  // compares records
+	is.Equal(c, 1) // descending field reverses order
+
+	c, err = Compare(r, key{Kind: "A", Note: "x"}, map[string]interface{}{
+		"kind": "A", "score": 0, "note": "y", "tags": []string{},
+	})
+	is.NoErr(err)  // compares different Go types
+	is.Equal(c, 0) // ignored field does not matter
+
+	c, err = Compare(r, key{Kind: "A", Tags: []string{"a"}}, key{Kind: "A", Tags: []string{"a", "b"}})
+	is.NoErr(err)   // compares arrays
+	is.Equal(c, -1) // shorter prefix comes first
+
+	u := Union{Null, String}
+	c, err = Compare(u, "a", nil)
+	is.NoErr(err)  // compares unions
+	is.Equal(c, 1) // branch index compares first
+
+	_, err = Compare(Map{Values: Int}, map[string]int{}, map[string]int{})
+	is.True(err != nil) // maps cannot be compared
+
+	_, err = CompareBinary(Int, []byte{}, []byte{2})
+	is.True(err != nil) // short input is an error
+}
+
+func TestCompareBinary_blocks(t *testing.T) {
+	is := is.New(t)
+
+	a := Array{Items: Int}
+	// [1, 2] as a single block, and as two blocks with byte sizes.
+	single := []byte{4, 2, 4, 0}
+	blocked := []byte{1, 2, 2, 1, 2, 4, 0}
+	c, err := CompareBinary(a, single, blocked)
+	is.NoErr(err)  // compares blocks
+	is.Equal(c, 0) // block layout does not matter
+}
+
+func TestCompareBinary_sort(t *testing.T) {
+	is := is.New(t)
+
+	values := []int64{5, -3, 100, 0, -100}
+	encoded := make([][]byte, len(values))
+	for i, v := range values {
+		b, err := Encode(Long, v)
+		is.NoErr(err) // encodes
+		encoded[i] = b
+	}
+	sort.Slice(encoded, func(i, j int) bool {
+		c, _ := CompareBinary(Long, encoded[i], encoded[j])
+		return c < 0
+	})
+	var sorted []int64
+	for _, b := range encoded {
+		var v int64
+		is.NoErr(Decode(Long, b, &v)) // decodes
+		sorted = append(sorted, v)
+	}
+	is.Equal(sorted, []int64{-100, -3, 0, 5, 100}) // sorted numerically
+}
@@ -0,0 +1,169 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CheckCompatibility checks that data written with schema writer can be read
+// with schema reader, following the schema resolution rules of the
+// specification: numeric types may be promoted, string and bytes are
+// interchangeable, named types must have the same unqualified name (or a
+// reader alias matching the writer's), reader fields missing from the writer
+// must have defaults, writer enum symbols must exist in the reader unless it
+// has a default, and every branch of a writer union must be readable. The
+// returned ErrValidation has the paths of the incompatible parts.
+func CheckCompatibility(reader, writer Schema) error {
+	if reader == nil || writer == nil {
+		return errors.New(`cannot check compatibility of nil schema`)
+	}
+	c := compatChecker{checked: map[[2]string]bool{}}
+	return c.check(reader, writer)
+}
+
+type compatChecker struct {
+	checked map[[2]string]bool // pairs of named types already checked
+}
+
+// promotions lists the writer types each reader primitive can read.
+var promotions = map[Primitive][]Primitive{
+	Long:   {Int},
+	Float:  {Int, Long},
+	Double: {Int, Long, Float},
+	String: {Bytes},
+	Bytes:  {String},
+}
+
+func (c compatChecker) check(reader, writer Schema) error {
+	reader, writer = resolve(reader), resolve(writer)
+	if l, ok := reader.(Logical); ok {
+		return c.check(l.Schema, writer)
+	}
+	if l, ok := writer.(Logical); ok {
+		return c.check(reader, l.Schema)
+	}
+	if wu, ok := writer.(Union); ok {
+		errs := map[string]error{}
+		for _, w := range wu {
+			if err := c.check(reader, w); err != nil {
+				errs[unionPath(w)] = err
+			}
+		}
+		return childErrors(errs)
+	}
+	if ru, ok := reader.(Union); ok {
+		for _, r := range ru {
+			if c.check(r, writer) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf(`union has no schema which reads "%s"`, unionName(writer))
+	}
+	if reader.Type() != writer.Type() {
+		if rp, ok := reader.(Primitive); ok {
+			for _, p := range promotions[rp] {
+				if writer == p {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf(`"%s" cannot read "%s"`, reader.Type(), writer.Type())
+	}
+	if rn, ok := reader.(NamedSchema); ok {
+		wn := writer.(NamedSchema)
+		if !namesMatch(rn.GetNameFields(), wn.GetNameFields()) {
+			return fmt.Errorf(`"%s" cannot read "%s"`, rn.Fullname(), wn.Fullname())
+		}
+		pair := [2]string{rn.Fullname(), wn.Fullname()}
+		if c.checked[pair] {
+			return nil
+		}
+		c.checked[pair] = true
+	}
+	switch r := reader.(type) {
+	case Record:
+		return c.checkRecord(r, writer.(Record))
+	case Enum:
+		for _, sym := range writer.(Enum).Symbols {
+			if r.exists(sym) != nil && r.Default == "" {
+				return fmt.Errorf(`symbol "%s" does not exist in the reader`, sym)
+			}
+		}
+	case Fixed:
+		if w := writer.(Fixed); r.Size != w.Size {
+			return fmt.Errorf(`size %d cannot read size %d`, r.Size, w.Size)
+		}
+	case Array:
+		if err := c.check(r.Items, writer.(Array).Items); err != nil {
+			return ErrValidation{Children: map[string]error{".items": err}}
+		}
+	case Map:
+		if err := c.check(r.Values, writer.(Map).Values); err != nil {
+			return ErrValidation{Children: map[string]error{".values": err}}
+		}
+	}
+	return nil
+}
+
+func (c compatChecker) checkRecord(r, w Record) error {
+	errs := map[string]error{}
+	for _, rf := range r.Fields {
+		wf := writerField(rf, w)
+		if wf == nil {
+			if rf.Default == nil {
+				errs[fieldPath(rf.Name)] = errors.New(`field is missing from the writer and has no default`)
+			}
+			continue
+		}
+		if err := c.check(rf.Type, wf.Type); err != nil {
+			errs[fieldPath(rf.Name)] = err
+		}
+	}
+	return childErrors(errs)
+}
+
+// writerField returns the field of w matching reader field rf by name or by
+// one of its aliases.
+func writerField(rf Field, w Record) *Field {
+	if i := writerFieldIndex(rf, w); i >= 0 {
+		return &w.Fields[i]
+	}
+	return nil
+}
+
+// writerFieldIndex returns the index of writerField(rf, w), or -1.
+func writerFieldIndex(rf Field, w Record) int {
+	if i := w.fieldIndex(rf.Name, false); i >= 0 {
+		return i
+	}
+	for _, a := range rf.Aliases {
+		if i := w.fieldIndex(a, false); i >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// namesMatch reports whether a named type read with reader name r can read
+// one written with name w: their unqualified names match, or an alias of r
+// matches w.
+func namesMatch(r, w NameFields) bool {
+	if unqualified(r.Name) == unqualified(w.Name) {
+		return true
+	}
+	for _, a := range r.FullAliases() {
+		if a == w.Fullname() || unqualified(a) == unqualified(w.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func unqualified(name string) string {
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] == '.' {
+			return name[i+1:]
+		}
+	}
+	return name
+}
@@ -0,0 +1,53 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	is := is.New(t)
+
+	parse := func(spec string) Schema {
+		s, err := SchemaUnmarshalJSON([]byte(spec))
+		is.NoErr(err)
+		return s
+	}
+	writer := parse(`{"type": "record", "name": "a.User", "fields": [
+		{"name": "id", "type": "int"},
+		{"name": "name", "type": "string"},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}},
+		{"name": "next", "type": ["null", "User"]}
+	]}`)
+
+	is.NoErr(CheckCompatibility(writer, writer)) // schema reads itself
+	is.NoErr(CheckCompatibility(parse(`{"type": "record", "name": "b.User", "fields": [
+		{"name": "id", "type": "double"},
+		{"name": "label", "type": "bytes", "aliases": ["name"]},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["B", "A", "C"]}},
+		{"name": "next", "type": ["null", "User", "string"]},
+		{"name": "extra", "type": "long", "default": 1}
+	]}`), writer)) // promotions, aliases, reordered symbols and defaults
+
+	err := CheckCompatibility(parse(`{"type": "record", "name": "User", "fields": [
+		{"name": "id", "type": "string"},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A"]}},
+		{"name": "extra", "type": "long"}
+	]}`), writer)
+	is.True(err != nil) // incompatible
+	var paths []string
+	for _, pe := range err.(ErrValidation).Flatten() {
+		paths = append(paths, pe.Path)
+	}
+	is.Equal(paths, []string{"$.extra", "$.id", "$.kind"}) // paths of each incompatibility
+
+	is.True(CheckCompatibility(Int, Long) != nil)                                                                                            // no demotion
+	is.NoErr(CheckCompatibility(Union{Null, Long}, Int))                                                                                     // union reader
+	is.True(CheckCompatibility(Long, Union{Null, Int}) != nil)                                                                               // null branch unreadable
+	is.NoErr(CheckCompatibility(Array{Items: Double}, Array{Items: Float}))                                                                  // array items
+	is.True(CheckCompatibility(Map{Values: Int}, Map{Values: Long}) != nil)                                                                  // map values
+	is.True(CheckCompatibility(Fixed{NameFields: NameFields{Name: "F"}, Size: 2}, Fixed{NameFields: NameFields{Name: "F"}, Size: 3}) != nil) // fixed size
+	is.True(CheckCompatibility(Fixed{NameFields: NameFields{Name: "F"}, Size: 2}, Fixed{NameFields: NameFields{Name: "G"}, Size: 2}) != nil) // names differ
+	is.NoErr(CheckCompatibility(Logical{LogicalType: LogicalTimestampMillis, Schema: Long}, Int))                                            // logical types read as underlying
+}
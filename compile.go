@@ -0,0 +1,389 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Validator checks values against a Schema which was checked once by Compile.
+// It gives the same results as Schema.Validate, but does not check the schema
+// again on every call and caches what it learns about each Go type, which
+// makes it much cheaper when validating many values.
+// A Validator is safe for concurrent use.
+type Validator struct {
+	schema Schema
+	root   *validatorNode
+}
+
+// Compile checks that s is valid and returns a Validator for it.
+func Compile(s Schema) (*Validator, error) {
+	if s == nil {
+		return nil, errors.New(`cannot compile nil schema`)
+	}
+	if err := s.Valid(); err != nil {
+		return nil, err
+	}
+	return &Validator{schema: s, root: compileNode(s, map[*Schema]*validatorNode{})}, nil
+}
+
+// Schema returns the Schema the Validator was compiled from.
+func (v *Validator) Schema() Schema {
+	return v.schema
+}
+
+// Validate checks if value conforms to the compiled Schema.
+// If there is an error, it will have type ErrValidation.
+func (v *Validator) Validate(value interface{}) error {
+	return v.root.validate(value)
+}
+
+// validatePlan validates a value of the Go type it was built for.
+type validatePlan func(rv reflect.Value) error
+
+// validatorNode is the compiled form of a single Schema.
+type validatorNode struct {
+	schema   Schema
+	children []*validatorNode // record fields, array items, map values or union branches
+	index    map[string]int   // record field name to position in children
+	plans    sync.Map         // reflect.Type to validatePlan
+}
+
+// compileNode compiles s. Nodes for references are shared through refs, so
+// recursive schemas compile to a cyclic graph of nodes.
+func compileNode(s Schema, refs map[*Schema]*validatorNode) *validatorNode {
+	if r, ok := s.(Reference); ok && r.Target() != nil {
+		if n, ok := refs[r.target]; ok {
+			return n
+		}
+		n := &validatorNode{}
+		refs[r.target] = n
+		compileInto(n, r.Target(), refs)
+		return n
+	}
+	n := &validatorNode{}
+	compileInto(n, s, refs)
+	return n
+}
+
+func compileInto(n *validatorNode, s Schema, refs map[*Schema]*validatorNode) {
+	n.schema = s
+	switch s := s.(type) {
+	case Record:
+		n.index = make(map[string]int, len(s.Fields))
+		for i, f := range s.Fields {
+			if _, ok := n.index[f.Name]; !ok {
+				n.index[f.Name] = i
+			}
+			n.children = append(n.children, compileNode(f.Type, refs))
+		}
+		// Fields are also found by alias, after all names, as fieldIndex does.
+		for i, f := range s.Fields {
+			for _, a := range f.Aliases {
+				if _, ok := n.index[a]; !ok {
+					n.index[a] = i
+				}
+			}
+		}
+	case Array:
+		n.children = []*validatorNode{compileNode(s.Items, refs)}
+	case Map:
+		n.children = []*validatorNode{compileNode(s.Values, refs)}
+	case Union:
+		for _, b := range s {
+			n.children = append(n.children, compileNode(b, refs))
+		}
+	}
+}
+
+func (n *validatorNode) validate(v interface{}) error {
+	switch s := n.schema.(type) {
+	case Record:
+		if v == nil {
+			return errors.New(`nil is not a valid record`)
+		}
+		switch g := v.(type) {
+		case *GenericRecord:
+			return g.validate(s, n.validateChild)
+		case GenericRecord:
+			return g.validate(s, n.validateChild)
+		}
+		msi, err := staticMSI(v, "record")
+		if err != nil {
+			return err
+		}
+		if msi != nil {
+			errs := map[string]error{}
+			for k, val := range msi {
+				i, ok := n.index[k]
+				if !ok {
+					errs[fieldPath(k)] = errors.New("record does not have a field with this name")
+					continue
+				}
+				if err := n.children[i].validate(val); err != nil {
+					errs[fieldPath(k)] = err
+				}
+			}
+			return childErrors(errs)
+		}
+	case Array:
+		if v == nil {
+			return errors.New(`nil is not a valid array`)
+		}
+		if s, ok := v.([]interface{}); ok {
+			errs := map[string]error{}
+			for i, sv := range s {
+				if err := n.children[0].validate(sv); err != nil {
+					errs[indexPath(i)] = err
+				}
+			}
+			return childErrors(errs)
+		}
+	case Map:
+		if v == nil {
+			return errors.New(`nil is not a valid map`)
+		}
+		msi, err := staticMSI(v, "map")
+		if err != nil {
+			return err
+		}
+		if msi != nil {
+			errs := map[string]error{}
+			for k, val := range msi {
+				if err := n.children[0].validate(val); err != nil {
+					errs[keyPath(k)] = err
+				}
+			}
+			return childErrors(errs)
+		}
+	case Union:
+		switch g := v.(type) {
+		case GenericUnion:
+			return g.validate(s, n.validateChild)
+		case *GenericUnion:
+			return g.validate(s, n.validateChild)
+		}
+		if w, ok := asUnionWrapper(v); ok {
+			return genericUnion(w).validate(s, n.validateChild)
+		}
+		errs := map[string]error{}
+		for i, b := range n.children {
+			err := b.validate(v)
+			if err == nil {
+				return nil
+			}
+			errs[unionPath(s[i])] = err
+		}
+		if len(errs) == len(n.children) {
+			return ErrValidation{
+				error:    errors.New("value does not match any type in the union; here is a breakdown"),
+				Children: errs,
+			}
+		}
+		return nil
+	case Enum:
+		if v == nil {
+			return errors.New(`nil is not a valid enum`)
+		}
+		return s.validate(v)
+	case Fixed:
+		if v == nil {
+			return errors.New(`nil is not a valid fixed`)
+		}
+		return s.validate(v)
+	default:
+		return s.Validate(v)
+	}
+	return n.plan(reflect.TypeOf(v))(reflect.ValueOf(v))
+}
+
+// validateChild validates v against the i-th child node.
+func (n *validatorNode) validateChild(i int, v interface{}) error {
+	return n.children[i].validate(v)
+}
+
+// plan returns the cached validatePlan for t, building it if needed.
+func (n *validatorNode) plan(t reflect.Type) validatePlan {
+	if p, ok := n.plans.Load(t); ok {
+		return p.(validatePlan)
+	}
+	var p validatePlan
+	switch n.schema.(type) {
+	case Record:
+		p = n.recordPlan(t)
+	case Array:
+		p = n.arrayPlan(t)
+	case Map:
+		p = n.mapPlan(t)
+	}
+	actual, _ := n.plans.LoadOrStore(t, p)
+	return actual.(validatePlan)
+}
+
+func (n *validatorNode) recordPlan(t reflect.Type) validatePlan {
+	deref := t.Kind() == reflect.Ptr
+	if deref {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		type planField struct {
+			path string
+			node *validatorNode // nil if the record does not have the field
+			skip bool
+		}
+		fields := make([]planField, t.NumField())
+		for i := range fields {
+			if skipStructField(t.Field(i)) {
+				fields[i].skip = true
+				continue
+			}
+			name := structFieldName(t.Field(i))
+			fields[i].path = fieldPath(name)
+			if j, ok := n.index[name]; ok {
+				fields[i].node = n.children[j]
+			}
+		}
+		return func(rv reflect.Value) error {
+			if deref {
+				if rv.IsNil() {
+					return fmt.Errorf(`value with type "%s" is not a valid record`, reflect.Invalid)
+				}
+				rv = rv.Elem()
+			}
+			errs := map[string]error{}
+			for i, f := range fields {
+				if f.skip {
+					continue
+				}
+				if f.node == nil {
+					errs[f.path] = errors.New("record does not have a field with this name")
+					continue
+				}
+				if err := f.node.validate(rv.Field(i).Interface()); err != nil {
+					errs[f.path] = err
+				}
+			}
+			return childErrors(errs)
+		}
+	case reflect.Map:
+		if keyKind := t.Key().Kind(); keyKind != reflect.String {
+			return errorPlan(fmt.Errorf(`map key has type "%s" but it must be string`, keyKind))
+		}
+		return func(rv reflect.Value) error {
+			if deref {
+				if rv.IsNil() {
+					return fmt.Errorf(`value with type "%s" is not a valid record`, reflect.Invalid)
+				}
+				rv = rv.Elem()
+			}
+			errs := map[string]error{}
+			for _, k := range rv.MapKeys() {
+				name := k.String()
+				i, ok := n.index[name]
+				if !ok {
+					errs[fieldPath(name)] = errors.New("record does not have a field with this name")
+					continue
+				}
+				if err := n.children[i].validate(rv.MapIndex(k).Interface()); err != nil {
+					errs[fieldPath(name)] = err
+				}
+			}
+			return childErrors(errs)
+		}
+	}
+	return errorPlan(fmt.Errorf(`value with type "%s" is not a valid record`, t.Kind()))
+}
+
+func (n *validatorNode) arrayPlan(t reflect.Type) validatePlan {
+	deref := t.Kind() == reflect.Ptr
+	if deref {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return func(rv reflect.Value) error {
+			if deref {
+				if rv.IsNil() {
+					return ErrValidation{
+						error: fmt.Errorf(`value has type "%s" but must be slice or array`, reflect.Invalid),
+					}
+				}
+				rv = rv.Elem()
+			}
+			errs := map[string]error{}
+			for i, l := 0, rv.Len(); i < l; i++ {
+				if err := n.children[0].validate(rv.Index(i).Interface()); err != nil {
+					errs[indexPath(i)] = err
+				}
+			}
+			return childErrors(errs)
+		}
+	}
+	return errorPlan(ErrValidation{
+		error: fmt.Errorf(`value has type "%s" but must be slice or array`, t.Kind()),
+	})
+}
+
+func (n *validatorNode) mapPlan(t reflect.Type) validatePlan {
+	deref := t.Kind() == reflect.Ptr
+	if deref {
+		t = t.Elem()
+	}
+	if kind := t.Kind(); kind != reflect.Map {
+		return errorPlan(fmt.Errorf(`value with type "%s" is not a valid Map`, kind))
+	}
+	if keyKind := t.Key().Kind(); keyKind != reflect.String {
+		return errorPlan(fmt.Errorf(`map key has type "%s" but it must be string`, keyKind))
+	}
+	return func(rv reflect.Value) error {
+		if deref {
+			if rv.IsNil() {
+				return fmt.Errorf(`value with type "%s" is not a valid Map`, reflect.Invalid)
+			}
+			rv = rv.Elem()
+		}
+		errs := map[string]error{}
+		for _, k := range rv.MapKeys() {
+			if err := n.children[0].validate(rv.MapIndex(k).Interface()); err != nil {
+				errs[keyPath(k.String())] = err
+			}
+		}
+		return childErrors(errs)
+	}
+}
+
+// errorPlan returns a validatePlan which always fails with err.
+func errorPlan(err error) validatePlan {
+	return func(reflect.Value) error { return err }
+}
+
+// staticMSI returns v as a map[string]interface{} if it is one, or a pointer to
+// one. A nil map is an error, and a nil map with no error means v has another
+// type.
+func staticMSI(v interface{}, typeName string) (map[string]interface{}, error) {
+	switch mv := v.(type) {
+	case *map[string]interface{}:
+		if mv == nil || *mv == nil {
+			return nil, fmt.Errorf(`pointer to nil map is not a valid %s`, typeName)
+		}
+		return *mv, nil
+	case map[string]interface{}:
+		if mv == nil {
+			return nil, fmt.Errorf(`nil map is not a valid %s`, typeName)
+		}
+		return mv, nil
+	}
+	return nil, nil
+}
+
+// childErrors returns an ErrValidation holding errs, or nil if errs is empty.
+func childErrors(errs map[string]error) error {
+	if len(errs) > 0 {
+		return ErrValidation{
+			Children: errs,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package avro
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+var benchRecord = Record{
+	NameFields: NameFields{Name: "Order"},
+	Fields: []Field{
+		{Name: "id", Type: Long},
+		{Name: "customer", Type: String},
+		{Name: "status", Type: Enum{
+			NameFields: NameFields{Name: "Status"},
+			Symbols:    []string{"NEW", "PAID", "SHIPPED"},
+		}},
+		{Name: "Items", Type: Array{Items: Record{
+			NameFields: NameFields{Name: "Item"},
+			Fields: []Field{
+				{Name: "sku", Type: String},
+				{Name: "Price", Type: Double},
+			},
+		}}},
+		{Name: "tags", Type: Map{Values: String}},
+		{Name: "note", Type: Union{Null, String}},
+	},
+}
+
+type benchItem struct {
+	SKU   string `avro:"sku"`
+	Price float64
+}
+
+type benchOrder struct {
+	ID       int64  `avro:"id"`
+	Customer string `avro:"customer"`
+	Status   string `avro:"status"`
+	Items    []benchItem
+	Tags     map[string]string `avro:"tags"`
+	Note     *string           `avro:"note"`
+}
+
+func newBenchOrder() benchOrder {
+	note := "leave at the door"
+	return benchOrder{
+		ID:       42,
+		Customer: "gopher",
+		Status:   "PAID",
+		Items: []benchItem{
+			{SKU: "a", Price: 1.5},
+			{SKU: "b", Price: 2.5},
+			{SKU: "c", Price: 3.5},
+		},
+		Tags: map[string]string{"channel": "web"},
+		Note: &note,
+	}
+}
+
+func TestCompile(t *testing.T) {
+	is := is.New(t)
+
+	_, err := Compile(nil)
+	is.True(err != nil) // nil schema cannot compile
+
+	_, err = Compile(Record{})
+	is.True(err != nil) // invalid schema cannot compile
+
+	v, err := Compile(benchRecord)
+	is.NoErr(err)                     // valid schema compiles
+	is.Equal(v.Schema(), benchRecord) // keeps the schema
+}
+
+func TestValidator_Validate(t *testing.T) {
+	is := is.New(t)
+
+	v, err := Compile(benchRecord)
+	is.NoErr(err) // valid schema compiles
+
+	order := newBenchOrder()
+	is.NoErr(v.Validate(order))  // valid struct
+	is.NoErr(v.Validate(&order)) // valid pointer to struct
+
+	invalid := []interface{}{
+		nil,
+		0,
+		(*benchOrder)(nil),
+		map[int]interface{}{},
+		map[string]interface{}{"id": "not a long"},
+		map[string]interface{}{"__WRONG__": int64(1)},
+		benchOrder{Status: "UNKNOWN"},
+	}
+	for _, val := range invalid {
+		got, want := v.Validate(val), benchRecord.Validate(val)
+		is.True(got != nil)                 // invalid value is invalid
+		is.Equal(got.Error(), want.Error()) // same error as Schema.Validate
+	}
+
+	order.Items[1].Price = 0
+	order.Items[2].SKU = "x"
+	is.NoErr(v.Validate(order)) // cached plan is reused
+
+	type other struct {
+		ID int64 `avro:"id"`
+		X  int
+	}
+	err = v.Validate(other{})
+	is.True(err != nil)                                    // unknown field is invalid
+	is.Equal(err.(ErrValidation).Flatten()[0].Path, "$.X") // path to unknown field
+}
+
+func TestValidator_aliases(t *testing.T) {
+	is := is.New(t)
+
+	r := Record{
+		NameFields: NameFields{Name: "R"},
+		Fields:     []Field{{Name: "id", Type: Long, Aliases: []string{"key"}}},
+	}
+	v, err := Compile(r)
+	is.NoErr(err)
+
+	type byAlias struct {
+		Key int64 `avro:"key"`
+	}
+	for _, val := range []interface{}{
+		map[string]interface{}{"key": int64(1)},
+		map[string]int64{"key": 1},
+		byAlias{Key: 1},
+	} {
+		is.NoErr(r.Validate(val)) // field found by alias
+		is.NoErr(v.Validate(val)) // as Schema.Validate does
+	}
+	err = v.Validate(map[string]interface{}{"key": "x"})
+	is.True(err != nil)                                                           // aliased field is validated
+	is.Equal(err.Error(), r.Validate(map[string]interface{}{"key": "x"}).Error()) // same error as Schema.Validate
+}
+
+func TestValidator_concurrent(t *testing.T) {
+	is := is.New(t)
+
+	v, err := Compile(benchRecord)
+	is.NoErr(err) // valid schema compiles
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := v.Validate(newBenchOrder()); err != nil {
+					errs[i] = err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		is.NoErr(err) // concurrent validation succeeds
+	}
+}
+
+func BenchmarkSchema_Validate(b *testing.B) {
+	order := newBenchOrder()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := benchRecord.Validate(order); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValidator_Validate(b *testing.B) {
+	v, err := Compile(benchRecord)
+	if err != nil {
+		b.Fatal(err)
+	}
+	order := newBenchOrder()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := v.Validate(order); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
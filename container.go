@@ -0,0 +1,626 @@
+package avro
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Object container files start with a header holding containerMagic, the
+// file metadata and a random sync marker, followed by blocks of values. Each
+// block holds its value count, its size in bytes, its values encoded and
+// compressed with the file's codec, and the sync marker.
+const (
+	containerMagic = "Obj\x01"
+	syncSize       = 16
+
+	// DefaultBlockSize is the encoded size in bytes at which a Writer
+	// flushes a block.
+	DefaultBlockSize = 64 * 1024
+
+	// maxBlockSize bounds the size of blocks read, so corrupt sizes do not
+	// allocate unbounded memory.
+	maxBlockSize = 1 << 30
+)
+
+// Codecs supported by object container files.
+const (
+	CodecNull    = "null"
+	CodecDeflate = "deflate"
+)
+
+// Metadata keys reserved by the specification.
+const (
+	MetaSchema = "avro.schema"
+	MetaCodec  = "avro.codec"
+
+	// MetaReservedPrefix starts every reserved key.
+	MetaReservedPrefix = "avro."
+)
+
+func compressBlock(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CodecNull:
+		return data, nil
+	case CodecDeflate:
+		var b bytes.Buffer
+		w, err := flate.NewWriter(&b, flate.DefaultCompression)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf(`unsupported codec "%s"`, codec)
+}
+
+func decompressBlock(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CodecNull:
+		return data, nil
+	case CodecDeflate:
+		b, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return nil, fmt.Errorf(`deflate: %s`, err)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf(`unsupported codec "%s"`, codec)
+}
+
+func checkCodec(codec string) error {
+	switch codec {
+	case CodecNull, CodecDeflate:
+		return nil
+	}
+	return fmt.Errorf(`unsupported codec "%s"`, codec)
+}
+
+// WriterOption configures a Writer.
+type WriterOption func(*writerOptions)
+
+type writerOptions struct {
+	codec     string
+	blockSize int
+	metadata  map[string][]byte
+	sync      *[syncSize]byte
+}
+
+// WithCodec sets the codec compressing blocks, CodecNull by default.
+func WithCodec(codec string) WriterOption {
+	return func(o *writerOptions) { o.codec = codec }
+}
+
+// WithBlockSize sets the encoded size in bytes at which blocks are flushed.
+func WithBlockSize(size int) WriterOption {
+	return func(o *writerOptions) { o.blockSize = size }
+}
+
+// WithMetadata adds a key to the file metadata, such as the job which wrote
+// the file. Keys starting with MetaReservedPrefix are reserved, and NewWriter
+// rejects them.
+func WithMetadata(key string, value []byte) WriterOption {
+	return func(o *writerOptions) { o.metadata[key] = value }
+}
+
+// WithSyncMarker sets the sync marker rather than generating a random one.
+func WithSyncMarker(sync [16]byte) WriterOption {
+	return func(o *writerOptions) { o.sync = &sync }
+}
+
+// Writer writes values to an object container file.
+type Writer struct {
+	w         io.Writer
+	schema    Schema
+	codec     string
+	blockSize int
+	sync      [syncSize]byte
+	block     encoder
+	count     int64
+}
+
+// NewWriter writes the header of an object container file for values of
+// schema s to w, and returns a Writer to append values with. Close must be
+// called to write the last block.
+func NewWriter(w io.Writer, s Schema, opts ...WriterOption) (*Writer, error) {
+	o := writerOptions{codec: CodecNull, blockSize: DefaultBlockSize, metadata: map[string][]byte{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if s == nil {
+		return nil, errors.New(`cannot write with nil schema`)
+	}
+	if err := s.Valid(); err != nil {
+		return nil, err
+	}
+	if err := checkCodec(o.codec); err != nil {
+		return nil, err
+	}
+	for k := range o.metadata {
+		if strings.HasPrefix(k, MetaReservedPrefix) {
+			return nil, fmt.Errorf(`metadata key "%s" is reserved`, k)
+		}
+	}
+	spec, err := Standalone(s)
+	if err != nil {
+		return nil, err
+	}
+	if o.metadata[MetaSchema], err = json.Marshal(spec); err != nil {
+		return nil, err
+	}
+	o.metadata[MetaCodec] = []byte(o.codec)
+
+	cw := &Writer{w: w, schema: s, codec: o.codec, blockSize: o.blockSize}
+	if o.sync != nil {
+		cw.sync = *o.sync
+	} else if _, err := rand.Read(cw.sync[:]); err != nil {
+		return nil, err
+	}
+	var h encoder
+	h.buf = append(h.buf, containerMagic...)
+	writeMetadata(&h, o.metadata)
+	h.buf = append(h.buf, cw.sync[:]...)
+	if _, err := w.Write(h.buf); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// NewAppendWriter returns a Writer which appends values to the object
+// container file in f, with the schema, codec and sync marker of its header.
+// The file must end with a complete block. Of opts, only WithBlockSize
+// applies, as the header is kept.
+func NewAppendWriter(f io.ReadWriteSeeker, opts ...WriterOption) (*Writer, error) {
+	o := writerOptions{blockSize: DefaultBlockSize, metadata: map[string][]byte{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	// The header and every block end with the sync marker.
+	if _, err := f.Seek(-syncSize, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	var sync [syncSize]byte
+	if _, err := io.ReadFull(f, sync[:]); err != nil {
+		return nil, fmt.Errorf(`read sync marker: %s`, err)
+	}
+	if sync != r.sync {
+		return nil, errors.New(`file does not end with a complete block`)
+	}
+	return &Writer{w: f, schema: r.schema, codec: r.codec, blockSize: o.blockSize, sync: r.sync}, nil
+}
+
+// writeMetadata writes metadata as a map of bytes, with keys sorted.
+func writeMetadata(e *encoder, metadata map[string][]byte) {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		e.writeLong(int64(len(keys)))
+		for _, k := range keys {
+			e.writeString(k)
+			e.writeBytes(metadata[k])
+		}
+	}
+	e.writeLong(0)
+}
+
+// Schema returns the schema of the values written.
+func (w *Writer) Schema() Schema {
+	return w.schema
+}
+
+// Codec returns the codec compressing blocks.
+func (w *Writer) Codec() string {
+	return w.codec
+}
+
+// Append encodes v, which may be of any Go type accepted by Encode, into the
+// current block, and flushes the block once it reaches the block size.
+func (w *Writer) Append(v interface{}) error {
+	n := len(w.block.buf)
+	if err := w.block.encode(w.schema, reflect.ValueOf(v)); err != nil {
+		w.block.buf = w.block.buf[:n]
+		return err
+	}
+	w.count++
+	if len(w.block.buf) >= w.blockSize || w.count >= maxZeroWidthItems {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the current block, if it has any values.
+func (w *Writer) Flush() error {
+	if w.count == 0 {
+		return nil
+	}
+	data, err := compressBlock(w.codec, w.block.buf)
+	if err != nil {
+		return err
+	}
+	if err := w.writeBlock(w.count, data); err != nil {
+		return err
+	}
+	w.block.buf = w.block.buf[:0]
+	w.count = 0
+	return nil
+}
+
+// writeBlock writes a block of count values, compressed into data.
+func (w *Writer) writeBlock(count int64, data []byte) error {
+	var e encoder
+	e.writeLong(count)
+	e.writeLong(int64(len(data)))
+	if _, err := w.w.Write(e.buf); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+	_, err := w.w.Write(w.sync[:])
+	return err
+}
+
+// AppendFrom flushes the current block and copies the blocks left in r, which
+// must have the same schema, without decoding their values. Blocks are copied
+// as they are if r has the codec of w, and are otherwise decompressed and
+// compressed again.
+func (w *Writer) AppendFrom(r *Reader) error {
+	a, err := Canonical(w.schema)
+	if err != nil {
+		return err
+	}
+	b, err := Canonical(r.schema)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(a, b) {
+		return errors.New(`schema differs from the schema written`)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for {
+		blk, err := r.ReadRawBlock()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		data := blk.Data
+		if r.codec != w.codec {
+			if data, err = decompressBlock(r.codec, data); err != nil {
+				return err
+			}
+			if data, err = compressBlock(w.codec, data); err != nil {
+				return err
+			}
+		}
+		if err := w.writeBlock(blk.Count, data); err != nil {
+			return err
+		}
+	}
+}
+
+// Close flushes the last block. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.Flush()
+}
+
+// Block is a block of an object container file, with its values
+// decompressed.
+type Block struct {
+	Count int64  // number of values
+	Data  []byte // binary encoding of the values, one after the other
+}
+
+// RawBlock is a block of an object container file as stored, with its values
+// compressed with the codec of the file.
+type RawBlock struct {
+	Count int64
+	Data  []byte
+}
+
+// Reader reads values from an object container file.
+type Reader struct {
+	r          *bufio.Reader
+	src        *countingReader // read by r
+	header     int64           // offset of the sync marker ending the header
+	end        int64           // set by ReadSplit, or 0
+	blockStart int64           // offset of the last block read
+	blockEnd   int64           // offset after the last block read
+	bad        func(BadBlock)  // set by SkipBadBlocks
+	schema     Schema
+	codec      string
+	sync       [syncSize]byte
+	metadata   map[string][]byte
+	block      decoder
+	left       int64      // values left in block
+	projection projection // set by Project
+}
+
+// NewReader reads the header of an object container file from r and returns
+// a Reader for its values.
+func NewReader(r io.Reader) (*Reader, error) {
+	src := &countingReader{r: r}
+	cr := &Reader{r: bufio.NewReader(src), src: src, codec: CodecNull}
+	magic := make([]byte, len(containerMagic))
+	if _, err := io.ReadFull(cr.r, magic); err != nil {
+		return nil, fmt.Errorf(`read header: %s`, err)
+	}
+	if string(magic) != containerMagic {
+		return nil, errors.New(`not an object container file`)
+	}
+	var err error
+	if cr.metadata, err = cr.readMetadata(); err != nil {
+		return nil, fmt.Errorf(`read metadata: %s`, err)
+	}
+	cr.header = cr.offset()
+	if _, err := io.ReadFull(cr.r, cr.sync[:]); err != nil {
+		return nil, fmt.Errorf(`read sync marker: %s`, err)
+	}
+	spec, ok := cr.metadata[MetaSchema]
+	if !ok {
+		return nil, fmt.Errorf(`missing metadata "%s"`, MetaSchema)
+	}
+	if cr.schema, err = SchemaUnmarshalJSON(spec); err != nil {
+		return nil, fmt.Errorf(`schema: %s`, err)
+	}
+	if codec, ok := cr.metadata[MetaCodec]; ok && len(codec) > 0 {
+		cr.codec = string(codec)
+	}
+	if err := checkCodec(cr.codec); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (r *Reader) readMetadata() (map[string][]byte, error) {
+	m := map[string][]byte{}
+	for {
+		n, err := binary.ReadVarint(r.r)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return m, nil
+		}
+		if n < 0 {
+			if _, err := binary.ReadVarint(r.r); err != nil {
+				return nil, err
+			}
+			n = -n
+		}
+		for ; n > 0; n-- {
+			k, err := r.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			v, err := r.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			m[string(k)] = v
+		}
+	}
+}
+
+func (r *Reader) readBytes() ([]byte, error) {
+	n, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxBlockSize {
+		return nil, fmt.Errorf(`invalid length %d`, n)
+	}
+	// The buffer grows as data is read, rather than to a corrupt length.
+	var b bytes.Buffer
+	if m, err := io.CopyN(&b, r.r, n); err == io.EOF && m > 0 {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Schema returns the schema of the values, from the file metadata.
+func (r *Reader) Schema() Schema {
+	return r.schema
+}
+
+// Codec returns the codec compressing blocks.
+func (r *Reader) Codec() string {
+	return r.codec
+}
+
+// Metadata returns a copy of the file metadata, including the reserved
+// MetaSchema and MetaCodec keys.
+func (r *Reader) Metadata() map[string][]byte {
+	m := make(map[string][]byte, len(r.metadata))
+	for k, v := range r.metadata {
+		m[k] = append([]byte{}, v...)
+	}
+	return m
+}
+
+// UserMetadata returns a copy of the file metadata without the reserved keys,
+// as set by WithMetadata.
+func (r *Reader) UserMetadata() map[string][]byte {
+	m := map[string][]byte{}
+	for k, v := range r.metadata {
+		if !strings.HasPrefix(k, MetaReservedPrefix) {
+			m[k] = append([]byte{}, v...)
+		}
+	}
+	return m
+}
+
+// ReadBlock returns the next block, skipping any values left in the current
+// one. It returns io.EOF after the last block.
+func (r *Reader) ReadBlock() (Block, error) {
+	for {
+		b, err := r.ReadRawBlock()
+		if err != nil {
+			return Block{}, err
+		}
+		data, err := decompressBlock(r.codec, b.Data)
+		if err == nil {
+			err = checkCount(r.schema, b.Count, len(data))
+		}
+		if err == nil {
+			return Block{Count: b.Count, Data: data}, nil
+		}
+		if !r.dropBlock(b.Count, err) {
+			return Block{}, err
+		}
+	}
+}
+
+// ReadRawBlock returns the next block without decompressing it, skipping any
+// values left in the current one. It returns io.EOF after the last block.
+func (r *Reader) ReadRawBlock() (RawBlock, error) {
+	for {
+		start := r.offset()
+		b, err := r.readRawBlock()
+		if err == nil {
+			r.blockStart, r.blockEnd = start, r.offset()
+			return b, nil
+		}
+		if err == io.EOF || r.bad == nil {
+			return RawBlock{}, err
+		}
+		if err := r.resync(start, b.Count, err); err != nil {
+			return RawBlock{}, err
+		}
+	}
+}
+
+// readRawBlock reads the next block. On errors, the block holds its count if
+// it was read, or else -1.
+func (r *Reader) readRawBlock() (RawBlock, error) {
+	if r.end > 0 && r.offset()-syncSize >= r.end {
+		return RawBlock{}, io.EOF
+	}
+	r.block, r.left = decoder{}, 0
+	count, err := binary.ReadVarint(r.r)
+	if err != nil {
+		if err == io.EOF {
+			return RawBlock{}, io.EOF
+		}
+		return RawBlock{Count: -1}, fmt.Errorf(`read block count: %s`, err)
+	}
+	if count < 0 {
+		return RawBlock{Count: -1}, fmt.Errorf(`invalid block count %d`, count)
+	}
+	data, err := r.readBytes()
+	if err != nil {
+		return RawBlock{Count: count}, fmt.Errorf(`read block: %s`, noEOF(err))
+	}
+	var sync [syncSize]byte
+	if _, err := io.ReadFull(r.r, sync[:]); err != nil {
+		return RawBlock{Count: count}, fmt.Errorf(`read sync marker: %s`, noEOF(err))
+	}
+	if sync != r.sync {
+		return RawBlock{Count: count}, errors.New(`sync marker does not match the header`)
+	}
+	return RawBlock{Count: count, Data: data}, nil
+}
+
+// noEOF reports io.EOF in the middle of a block as unexpected.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Project makes Decode read only the fields of the values at paths, as
+// selected by Project, skipping the others, and returns the projected schema.
+func (r *Reader) Project(paths ...string) (Schema, error) {
+	s, err := Project(r.schema, paths...)
+	if err != nil {
+		return nil, err
+	}
+	if r.projection, err = newProjection(r.schema, s); err != nil {
+		return nil, err
+	}
+	r.block.projection = r.projection
+	return s, nil
+}
+
+// Decode reads the next value and stores it in the value pointed to by v,
+// which may be of any type accepted by Decode, with the options of Decode.
+// Aliased bytes stay valid after the block is read, as each block is read
+// into new memory. It returns io.EOF after the last value.
+func (r *Reader) Decode(v interface{}, opts ...DecodeOption) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf(`cannot decode into non-pointer or nil value of type "%T"`, v)
+	}
+	for {
+		if err := r.nextValue(); err != nil {
+			return err
+		}
+		r.block.setOptions(opts)
+		err := r.block.decode(r.schema, rv.Elem())
+		if err == nil || !r.dropBlock(r.left+1, err) {
+			return err
+		}
+	}
+}
+
+// Skip reads past the next value without decoding it. It returns io.EOF
+// after the last value.
+func (r *Reader) Skip() error {
+	for {
+		if err := r.nextValue(); err != nil {
+			return err
+		}
+		err := r.block.skip(r.schema)
+		if err == nil || !r.dropBlock(r.left+1, err) {
+			return err
+		}
+	}
+}
+
+// nextValue reads blocks until one has a value left, and counts it as read.
+func (r *Reader) nextValue() error {
+	for r.left == 0 {
+		if rest := len(r.block.buf) - r.block.pos; rest > 0 {
+			err := fmt.Errorf(`%d bytes remain after the values of the block`, rest)
+			if !r.dropBlock(0, err) {
+				return err
+			}
+		}
+		b, err := r.ReadBlock()
+		if err != nil {
+			return err
+		}
+		r.block, r.left = decoder{buf: b.Data, projection: r.projection}, b.Count
+	}
+	r.left--
+	return nil
+}
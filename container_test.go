@@ -0,0 +1,292 @@
+package avro
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"runtime"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type containerItem struct {
+	ID   int64  `avro:"id"`
+	Name string `avro:"name"`
+}
+
+var containerSchema = Record{
+	NameFields: NameFields{Name: "Item"},
+	Fields: []Field{
+		{Name: "id", Type: Long},
+		{Name: "name", Type: String},
+	},
+}
+
+func TestContainer(t *testing.T) {
+	for _, codec := range []string{CodecNull, CodecDeflate} {
+		t.Run(codec, func(t *testing.T) {
+			is := is.New(t)
+
+			var b bytes.Buffer
+			w, err := NewWriter(&b, containerSchema, WithCodec(codec), WithBlockSize(10),
+				WithMetadata("app", []byte("test")))
+			is.NoErr(err)
+			for i := int64(0); i < 5; i++ {
+				is.NoErr(w.Append(containerItem{ID: i, Name: "item"}))
+			}
+			is.True(w.Append(1) != nil) // invalid value
+			is.NoErr(w.Close())
+			is.True(bytes.HasPrefix(b.Bytes(), []byte("Obj\x01"))) // magic
+
+			r, err := NewReader(bytes.NewReader(b.Bytes()))
+			is.NoErr(err)
+			is.Equal(r.Codec(), codec)
+			is.Equal(string(r.Metadata()["app"]), "test")                        // user metadata
+			is.Equal(r.UserMetadata(), map[string][]byte{"app": []byte("test")}) // without reserved keys
+			is.Equal(r.Schema().(Record).Fullname(), "Item")
+			var got []containerItem
+			for {
+				var item containerItem
+				err := r.Decode(&item, ReuseValues())
+				if err == io.EOF {
+					break
+				}
+				is.NoErr(err)
+				got = append(got, item)
+			}
+			is.Equal(len(got), 5)         // every value
+			is.Equal(got[4].ID, int64(4)) // in order
+
+			r, err = NewReader(bytes.NewReader(b.Bytes()))
+			is.NoErr(err)
+			var blocks, values int64
+			for {
+				blk, err := r.ReadBlock()
+				if err == io.EOF {
+					break
+				}
+				is.NoErr(err)
+				blocks++
+				values += blk.Count
+			}
+			is.True(blocks > 1) // flushed at block size
+			is.Equal(values, int64(5))
+
+			r, err = NewReader(bytes.NewReader(b.Bytes()))
+			is.NoErr(err)
+			is.NoErr(r.Skip())
+			is.NoErr(r.Skip())
+			var item containerItem
+			is.NoErr(r.Decode(&item))
+			is.Equal(item.ID, int64(2)) // after the skipped values
+		})
+	}
+}
+
+func TestContainer_errors(t *testing.T) {
+	is := is.New(t)
+
+	_, err := NewWriter(io.Discard, containerSchema, WithCodec("snappy"))
+	is.True(err != nil) // unsupported codec
+	_, err = NewWriter(io.Discard, containerSchema, WithMetadata("avro.job", nil))
+	is.Equal(err.Error(), `metadata key "avro.job" is reserved`)
+	_, err = NewReader(bytes.NewReader([]byte("Obj\x02")))
+	is.True(err != nil) // bad magic
+
+	var b bytes.Buffer
+	w, err := NewWriter(&b, containerSchema, WithSyncMarker([16]byte{1}))
+	is.NoErr(err)
+	is.NoErr(w.Append(containerItem{ID: 1}))
+	is.NoErr(w.Close())
+	data := b.Bytes()
+	data[len(data)-1] ^= 0xff
+	r, err := NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	var item containerItem
+	is.True(r.Decode(&item) != nil) // sync marker mismatch
+
+	r, err = NewReader(bytes.NewReader(data[:len(data)-5]))
+	is.NoErr(err)
+	is.True(r.Decode(&item) != nil) // truncated block
+
+	data, offsets := blocksFile(t, CodecNull, 1)
+	data = binary.AppendVarint(data[:offsets[0]+1], 1<<29)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	r, err = NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	is.True(r.Decode(&item) != nil) // block shorter than its size
+	runtime.ReadMemStats(&after)
+	is.True(after.TotalAlloc-before.TotalAlloc < 1<<20) // not allocated up front
+}
+
+func TestReader_blockCount(t *testing.T) {
+	is := is.New(t)
+
+	data, offsets := blocksFile(t, CodecNull, 2)
+	// The count of the first block, 1, takes a byte.
+	data = append(append(append([]byte{}, data[:offsets[0]]...), binary.AppendVarint(nil, 1<<40)...), data[offsets[0]+1:]...)
+
+	r, err := NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	var item containerItem
+	is.True(r.Decode(&item) != nil) // more values than bytes
+
+	r, err = NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	var bad []BadBlock
+	r.SkipBadBlocks(func(b BadBlock) { bad = append(bad, b) })
+	var ids []int64
+	is.NoErr(r.ReadParallel(context.Background(), nil, func(v interface{}) error {
+		id, _ := v.(*GenericRecord).Get("id")
+		ids = append(ids, id.(int64))
+		return nil
+	}))
+	is.Equal(ids, []int64{1}) // the bad block is skipped
+	is.Equal(len(bad), 1)
+	is.Equal(bad[0].Count, int64(1<<40))
+
+	kept, err := Repair(io.Discard, bytes.NewReader(data), nil)
+	is.NoErr(err)
+	is.Equal(kept, int64(1)) // checked by its count too
+
+	var b bytes.Buffer
+	w, err := NewWriter(&b, Null)
+	is.NoErr(err)
+	for i := 0; i < maxZeroWidthItems+1; i++ {
+		is.NoErr(w.Append(nil))
+	}
+	is.NoErr(w.Close())
+	r, err = NewReader(bytes.NewReader(b.Bytes()))
+	is.NoErr(err)
+	n := 0
+	is.NoErr(r.ReadParallel(context.Background(), nil, func(interface{}) error {
+		n++
+		return nil
+	}))
+	is.Equal(n, maxZeroWidthItems+1) // values encoded in no bytes are written in bounded blocks
+}
+
+func TestWriter_AppendFrom(t *testing.T) {
+	is := is.New(t)
+
+	write := func(codec string, ids ...int64) []byte {
+		var b bytes.Buffer
+		w, err := NewWriter(&b, containerSchema, WithCodec(codec))
+		is.NoErr(err)
+		for _, id := range ids {
+			is.NoErr(w.Append(containerItem{ID: id, Name: "item"}))
+		}
+		is.NoErr(w.Close())
+		return b.Bytes()
+	}
+	a, b := write(CodecDeflate, 1, 2), write(CodecNull, 3)
+
+	var out bytes.Buffer
+	w, err := NewWriter(&out, containerSchema, WithCodec(CodecDeflate))
+	is.NoErr(err)
+	is.NoErr(w.Append(containerItem{ID: 0}))
+	for _, data := range [][]byte{a, b} {
+		r, err := NewReader(bytes.NewReader(data))
+		is.NoErr(err)
+		is.NoErr(w.AppendFrom(r))
+	}
+	is.NoErr(w.Close())
+	ra, err := NewReader(bytes.NewReader(a))
+	is.NoErr(err)
+	blk, err := ra.ReadRawBlock()
+	is.NoErr(err)
+	is.True(bytes.Contains(out.Bytes(), blk.Data)) // blocks of the same codec copied
+
+	r, err := NewReader(bytes.NewReader(out.Bytes()))
+	is.NoErr(err)
+	var ids []int64
+	for {
+		var item containerItem
+		if err := r.Decode(&item); err == io.EOF {
+			break
+		} else {
+			is.NoErr(err)
+		}
+		ids = append(ids, item.ID)
+	}
+	is.Equal(ids, []int64{0, 1, 2, 3}) // in order, across codecs
+
+	var other bytes.Buffer
+	ow, err := NewWriter(&other, Int)
+	is.NoErr(err)
+	is.NoErr(ow.Close())
+	r, err = NewReader(bytes.NewReader(other.Bytes()))
+	is.NoErr(err)
+	is.True(w.AppendFrom(r) != nil) // schemas differ
+}
+
+// file is an io.ReadWriteSeeker in memory.
+type file struct {
+	data []byte
+	pos  int
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.pos >= len(f.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[f.pos:])
+	f.pos += n
+	return n, nil
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	f.data = append(f.data[:f.pos], p...)
+	f.pos = len(f.data)
+	return len(p), nil
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += int64(f.pos)
+	case io.SeekEnd:
+		offset += int64(len(f.data))
+	}
+	f.pos = int(offset)
+	return offset, nil
+}
+
+func TestNewAppendWriter(t *testing.T) {
+	is := is.New(t)
+
+	f := &file{}
+	w, err := NewWriter(f, containerSchema, WithCodec(CodecDeflate))
+	is.NoErr(err)
+	is.NoErr(w.Append(containerItem{ID: 1}))
+	is.NoErr(w.Close())
+
+	for id := int64(2); id <= 3; id++ {
+		w, err = NewAppendWriter(f)
+		is.NoErr(err)
+		is.Equal(w.Codec(), CodecDeflate) // codec of the file
+		is.NoErr(w.Append(containerItem{ID: id}))
+		is.NoErr(w.Close())
+	}
+
+	r, err := NewReader(bytes.NewReader(f.data))
+	is.NoErr(err)
+	var ids []int64
+	for {
+		var item containerItem
+		if err := r.Decode(&item); err == io.EOF {
+			break
+		} else {
+			is.NoErr(err)
+		}
+		ids = append(ids, item.ID)
+	}
+	is.Equal(ids, []int64{1, 2, 3}) // appended after the existing blocks
+
+	f.data = f.data[:len(f.data)-1]
+	_, err = NewAppendWriter(f)
+	is.True(err != nil) // incomplete last block
+}
@@ -0,0 +1,830 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Decode reads the Avro binary encoding of a value with schema s from data
+// and stores it in the value pointed to by v.
+//
+// Records may be decoded into structs (matching fields by name or "avro" tag,
+// and skipping fields the struct does not have), maps with string keys or
+// GenericRecord. Decoding into an interface{} produces generic values: nil,
+// bool, int32, int64, float32, float64, []byte, string, *GenericRecord,
+// GenericEnum, GenericFixed, []interface{}, map[string]interface{} and
+// GenericUnion, while logical types use the values made by DefaultFactories.
+// Dates and timestamps may be decoded into time.Time, and times of day into
+// time.Duration.
+//
+// Slices and pointers in v are reused where possible. The options make
+// Decode reuse more of v, for callers decoding many values into the same
+// destination.
+func Decode(s Schema, data []byte, v interface{}, opts ...DecodeOption) error {
+	if s == nil {
+		return errors.New(`cannot decode with nil schema`)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf(`cannot decode into non-pointer or nil value of type "%T"`, v)
+	}
+	d := decoder{buf: data}
+	d.setOptions(opts)
+	if err := d.decode(s, rv.Elem()); err != nil {
+		return err
+	}
+	if rest := len(d.buf) - d.pos; rest > 0 {
+		return fmt.Errorf(`%d bytes remain after decoding`, rest)
+	}
+	return nil
+}
+
+// DecodeOption configures Decode.
+type DecodeOption struct {
+	reuse bool
+	alias bool
+}
+
+// ReuseValues makes Decode refill the maps, byte slices and generic records
+// of the destination rather than allocate new ones, and keep the strings
+// which are unchanged. Maps are cleared first.
+func ReuseValues() DecodeOption {
+	return DecodeOption{reuse: true}
+}
+
+// AliasBytes makes Decode store bytes and fixed values decoded into byte
+// slices, or as generic values, as slices of the input data rather than
+// copies. The data must then not be modified while they are in use.
+func AliasBytes() DecodeOption {
+	return DecodeOption{alias: true}
+}
+
+// decoder reads binary encoded values from buf, starting at pos.
+type decoder struct {
+	buf        []byte
+	pos        int
+	projection projection // records read with fewer fields, or nil
+	reuse      bool       // set by ReuseValues
+	alias      bool       // set by AliasBytes
+}
+
+func (d *decoder) setOptions(opts []DecodeOption) {
+	d.reuse, d.alias = false, false
+	for _, o := range opts {
+		d.reuse = d.reuse || o.reuse
+		d.alias = d.alias || o.alias
+	}
+}
+
+func (d *decoder) readLong() (int64, error) {
+	n, l := binary.Varint(d.buf[d.pos:])
+	if l <= 0 {
+		if l == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, errors.New(`varint overflows a 64-bit integer`)
+	}
+	d.pos += l
+	return n, nil
+}
+
+func (d *decoder) readInt() (int32, error) {
+	n, err := d.readLong()
+	if err != nil {
+		return 0, err
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return 0, fmt.Errorf(`value %d overflows "int"`, n)
+	}
+	return int32(n), nil
+}
+
+// next returns the next n bytes of buf without copying them.
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 || n > len(d.buf)-d.pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// readBytes returns the next length-prefixed bytes without copying them.
+func (d *decoder) readBytes() ([]byte, error) {
+	n, err := d.readLong()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > int64(len(d.buf)-d.pos) {
+		return nil, fmt.Errorf(`invalid length %d`, n)
+	}
+	return d.next(int(n))
+}
+
+func (d *decoder) readBool() (bool, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return false, err
+	}
+	switch b[0] {
+	case 0:
+		return false, nil
+	case 1:
+		return true, nil
+	}
+	return false, fmt.Errorf(`invalid boolean byte %d`, b[0])
+}
+
+func (d *decoder) readFloat() (float32, error) {
+	b, err := d.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (d *decoder) readDouble() (float64, error) {
+	b, err := d.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+}
+
+// maxZeroWidthItems bounds the count of values encoded in no bytes, such as
+// nulls, in an array block or a container file block, as their size cannot.
+const maxZeroWidthItems = 1 << 20
+
+// readBlockCount returns the item count of the next array block, of items of
+// schema items, or map block, if items is nil. A count of 0 marks the end of
+// the blocks.
+func (d *decoder) readBlockCount(items Schema) (int, error) {
+	n, err := d.readLong()
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		// A negative count is followed by the size of the block in bytes.
+		if _, err := d.readLong(); err != nil {
+			return 0, err
+		}
+		n = -n
+	}
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf(`invalid block count %d`, n)
+	}
+	if err := checkCount(items, n, len(d.buf)-d.pos); err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// checkCount checks that n values of schema s, or map entries if s is nil,
+// fit in size bytes: each takes a byte at least, unless s is encoded in no
+// bytes, and then at most maxZeroWidthItems fit.
+func checkCount(s Schema, n int64, size int) error {
+	if n <= int64(size) {
+		return nil
+	}
+	if s == nil || !zeroWidth(s, map[string]bool{}) {
+		return fmt.Errorf(`count %d is more than the %d bytes left`, n, size)
+	}
+	if n > maxZeroWidthItems {
+		return fmt.Errorf(`count %d of values encoded in no bytes is more than %d`, n, maxZeroWidthItems)
+	}
+	return nil
+}
+
+func (d *decoder) readUnionIndex(u Union) (int, error) {
+	i, err := d.readLong()
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 || i >= int64(len(u)) {
+		return 0, fmt.Errorf(`union has no schema at index %d`, i)
+	}
+	return int(i), nil
+}
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+func (d *decoder) decode(s Schema, rv reflect.Value) error {
+	s = resolve(s)
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.NumMethod() > 0 {
+			return fmt.Errorf(`cannot decode into interface type "%s"`, rv.Type())
+		}
+		v, err := d.decodeGeneric(s)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			rv.Set(reflect.Zero(rv.Type()))
+		} else {
+			rv.Set(reflect.ValueOf(v))
+		}
+		return nil
+	case reflect.Ptr:
+		if u, ok := s.(Union); ok && rv.Type().Elem() != genericUnionType {
+			return d.decodeUnion(u, rv)
+		}
+		if s == Null {
+			rv.Set(reflect.Zero(rv.Type()))
+			return nil
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decode(s, rv.Elem())
+	}
+
+	switch s := s.(type) {
+	case Primitive:
+		return d.decodePrimitive(s, rv)
+	case Record:
+		return d.decodeRecord(s, rv)
+	case Enum:
+		return d.decodeEnum(s, rv)
+	case Fixed:
+		return d.decodeFixed(s, rv)
+	case Array:
+		return d.decodeArray(s, rv)
+	case Map:
+		return d.decodeMap(s, rv)
+	case Union:
+		return d.decodeUnion(s, rv)
+	case Logical:
+		if s.timeBased() && (rv.Type() == timeType || rv.Type() == durationType) {
+			n, err := d.readLong()
+			if err != nil {
+				return err
+			}
+			return s.toTime(n, rv)
+		}
+		return d.decode(s.Schema, rv)
+	}
+	return fmt.Errorf(`cannot decode schema type "%s"`, s.Type())
+}
+
+func errDecodeInto(s Schema, rv reflect.Value) error {
+	return errDecodeTypeInto(s.Type(), rv)
+}
+
+// errDecodeTypeInto is errDecodeInto for callers which have only the type
+// name, so that the schema is not boxed on every call.
+func errDecodeTypeInto(t string, rv reflect.Value) error {
+	return fmt.Errorf(`cannot decode "%s" into value of type "%s"`, t, rv.Type())
+}
+
+func (d *decoder) decodePrimitive(p Primitive, rv reflect.Value) error {
+	switch p {
+	case Null:
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	case Boolean:
+		b, err := d.readBool()
+		if err != nil {
+			return err
+		}
+		if rv.Kind() != reflect.Bool {
+			return errDecodeInto(p, rv)
+		}
+		rv.SetBool(b)
+		return nil
+	case Int, Long:
+		var n int64
+		var err error
+		if p == Int {
+			var i int32
+			i, err = d.readInt()
+			n = int64(i)
+		} else {
+			n, err = d.readLong()
+		}
+		if err != nil {
+			return err
+		}
+		return setInt(rv, n, p.Type())
+	case Float, Double:
+		var f float64
+		if p == Float {
+			f32, err := d.readFloat()
+			if err != nil {
+				return err
+			}
+			f = float64(f32)
+		} else {
+			var err error
+			if f, err = d.readDouble(); err != nil {
+				return err
+			}
+		}
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			rv.SetFloat(f)
+			return nil
+		}
+		return errDecodeInto(p, rv)
+	case Bytes, String:
+		b, err := d.readBytes()
+		if err != nil {
+			return err
+		}
+		return d.setBytes(rv, b, p.Type())
+	}
+	return errDecodeInto(p, rv)
+}
+
+// setInt stores n in an integer kind, checking that it does not overflow.
+func setInt(rv reflect.Value, n int64, t string) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.OverflowInt(n) {
+			return fmt.Errorf(`value %d overflows "%s"`, n, rv.Type())
+		}
+		rv.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n < 0 || rv.OverflowUint(uint64(n)) {
+			return fmt.Errorf(`value %d overflows "%s"`, n, rv.Type())
+		}
+		rv.SetUint(uint64(n))
+		return nil
+	}
+	return errDecodeTypeInto(t, rv)
+}
+
+// setBytes stores b in a string or byte slice, copied unless aliasing.
+func (d *decoder) setBytes(rv reflect.Value, b []byte, t string) error {
+	switch {
+	case rv.Kind() == reflect.String:
+		if !d.reuse || rv.String() != string(b) {
+			rv.SetString(string(b))
+		}
+		return nil
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		switch {
+		case d.alias:
+		case d.reuse && !rv.IsNil():
+			b = append(rv.Bytes()[:0], b...)
+		default:
+			b = append([]byte{}, b...)
+		}
+		rv.SetBytes(b)
+		return nil
+	}
+	return errDecodeTypeInto(t, rv)
+}
+
+func (d *decoder) decodeRecord(r Record, rv reflect.Value) error {
+	// Projected records read their fields from the writer record w.
+	w, index, promoted, defaults := r, []int(nil), []Primitive(nil), []fieldDefault(nil)
+	if p, ok := d.projection[r.Fullname()]; ok {
+		r, index, promoted, defaults = p.reader, p.index, p.promoted, p.defaults
+	}
+	// readField decodes writer field i into rv, promoting it if the reader
+	// field has a wider type.
+	readField := func(i int, rv reflect.Value) error {
+		if promoted != nil && promoted[i] != "" {
+			return d.decodePromoted(w.Fields[i].Type, promoted[i], rv)
+		}
+		return d.decode(w.Fields[i].Type, rv)
+	}
+	// readerIndex returns the index in r of writer field i, or -1.
+	readerIndex := func(i int) int {
+		if index == nil {
+			return i
+		}
+		return index[i]
+	}
+	switch {
+	case rv.Type() == genericRecordType:
+		g := rv.Addr().Interface().(*GenericRecord)
+		if !d.reuse || len(g.values) != len(r.Fields) || g.schema.Fullname() != r.Fullname() {
+			*g = *NewGenericRecord(r)
+		}
+		g.schema = r
+		for i, f := range w.Fields {
+			var err error
+			if j := readerIndex(i); j < 0 {
+				err = d.skip(f.Type)
+			} else if promoted != nil && promoted[i] != "" {
+				err = readField(i, reflect.ValueOf(&g.values[j]).Elem())
+			} else {
+				g.values[j], err = d.decodeGeneric(f.Type)
+			}
+			if err != nil {
+				return fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+		}
+		for _, def := range defaults {
+			dd := decoder{buf: def.data}
+			v, err := dd.decodeGeneric(r.Fields[def.index].Type)
+			if err != nil {
+				return fmt.Errorf(`default of field "%s": %s`, r.Fields[def.index].Name, err)
+			}
+			g.values[def.index] = v
+		}
+		return nil
+	case rv.Kind() == reflect.Struct:
+		fields := structFields(rv.Type())
+		for i, f := range w.Fields {
+			var err error
+			if j := readerIndex(i); j < 0 {
+				err = d.skip(f.Type)
+			} else if k, ok := structIndex(fields, r.Fields[j]); ok {
+				err = readField(i, rv.Field(k))
+			} else {
+				err = d.skip(f.Type)
+			}
+			if err != nil {
+				return fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+		}
+		for _, def := range defaults {
+			f := r.Fields[def.index]
+			if k, ok := structIndex(fields, f); ok {
+				dd := decoder{buf: def.data}
+				if err := dd.decode(f.Type, rv.Field(k)); err != nil {
+					return fmt.Errorf(`default of field "%s": %s`, f.Name, err)
+				}
+			}
+		}
+		return nil
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(r.Fields)))
+		} else if d.reuse {
+			rv.Clear()
+		}
+		v := reflect.New(rv.Type().Elem()).Elem()
+		zero := reflect.Zero(v.Type())
+		set := func(read func(reflect.Value) error, name string) error {
+			v.Set(zero)
+			if err := read(v); err != nil {
+				return err
+			}
+			rv.SetMapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()), v)
+			return nil
+		}
+		for i, f := range w.Fields {
+			var err error
+			if j := readerIndex(i); j < 0 {
+				err = d.skip(f.Type)
+			} else {
+				err = set(func(v reflect.Value) error { return readField(i, v) }, r.Fields[j].Name)
+			}
+			if err != nil {
+				return fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+		}
+		for _, def := range defaults {
+			f := r.Fields[def.index]
+			dd := decoder{buf: def.data}
+			if err := set(func(v reflect.Value) error { return dd.decode(f.Type, v) }, f.Name); err != nil {
+				return fmt.Errorf(`default of field "%s": %s`, f.Name, err)
+			}
+		}
+		return nil
+	}
+	return errDecodeInto(r, rv)
+}
+
+// decodePromoted reads a value of writer primitive w as reader primitive r,
+// which the specification allows to read it.
+func (d *decoder) decodePromoted(w Schema, r Primitive, rv reflect.Value) error {
+	if r == Long || r == String || r == Bytes {
+		// Int and long are encoded alike, as are string and bytes.
+		return d.decode(r, rv)
+	}
+	var f float64
+	if resolve(w) == Float {
+		f32, err := d.readFloat()
+		if err != nil {
+			return err
+		}
+		f = float64(f32)
+	} else {
+		n, err := d.readLong()
+		if err != nil {
+			return err
+		}
+		f = float64(n)
+	}
+	var b [8]byte
+	dd := decoder{buf: b[:], reuse: d.reuse}
+	if r == Float {
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(float32(f)))
+		dd.buf = b[:4]
+	} else {
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	}
+	return dd.decode(r, rv)
+}
+
+func (d *decoder) decodeEnum(e Enum, rv reflect.Value) error {
+	i, err := d.readLong()
+	if err != nil {
+		return err
+	}
+	if i < 0 || i >= int64(len(e.Symbols)) {
+		return fmt.Errorf(`enum has no symbol at index %d`, i)
+	}
+	switch {
+	case rv.Type() == genericEnumType:
+		rv.Set(reflect.ValueOf(GenericEnum{Symbol: e.Symbols[i], Ordinal: int(i)}))
+		return nil
+	case rv.Kind() == reflect.String:
+		rv.SetString(e.Symbols[i])
+		return nil
+	case rv.Type().PkgPath() != "":
+		// A defined integer type holds the ordinal.
+		return setInt(rv, i, e.Type())
+	}
+	return errDecodeInto(e, rv)
+}
+
+func (d *decoder) decodeFixed(f Fixed, rv reflect.Value) error {
+	b, err := d.next(int(f.Size))
+	if err != nil {
+		return err
+	}
+	if rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+		if rv.Len() != int(f.Size) {
+			return fmt.Errorf(`cannot decode fixed of size %d into "%s"`, f.Size, rv.Type())
+		}
+		if rv.CanAddr() {
+			copy(rv.Slice(0, rv.Len()).Bytes(), b)
+		} else {
+			reflect.Copy(rv, reflect.ValueOf(b))
+		}
+		return nil
+	}
+	return d.setBytes(rv, b, f.Type())
+}
+
+func (d *decoder) decodeArray(a Array, rv reflect.Value) error {
+	if rv.Kind() != reflect.Slice {
+		return errDecodeInto(a, rv)
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+	}
+	l := 0
+	for {
+		n, err := d.readBlockCount(a.Items)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		for end := l + n; l < end; l++ {
+			if l == rv.Cap() {
+				grown := reflect.MakeSlice(rv.Type(), l, 2*l+4)
+				reflect.Copy(grown, rv)
+				rv.Set(grown)
+			}
+			rv.SetLen(l + 1)
+			if err := d.decode(a.Items, rv.Index(l)); err != nil {
+				return fmt.Errorf(`item at index %d: %s`, l, err)
+			}
+		}
+	}
+	rv.SetLen(l)
+	return nil
+}
+
+func (d *decoder) decodeMap(m Map, rv reflect.Value) error {
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return errDecodeInto(m, rv)
+	}
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rv.Type()))
+	} else if d.reuse {
+		rv.Clear()
+	}
+	// Keys and values are set in k and v, which the map copies.
+	k, v := reflect.New(rv.Type().Key()).Elem(), reflect.New(rv.Type().Elem()).Elem()
+	zero := reflect.Zero(v.Type())
+	for {
+		n, err := d.readBlockCount(nil)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		for i := 0; i < n; i++ {
+			key, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			v.Set(zero)
+			if err := d.decode(m.Values, v); err != nil {
+				return fmt.Errorf(`value for key "%s": %s`, key, err)
+			}
+			k.SetString(string(key))
+			rv.SetMapIndex(k, v)
+		}
+	}
+}
+
+func (d *decoder) decodeUnion(u Union, rv reflect.Value) error {
+	i, err := d.readUnionIndex(u)
+	if err != nil {
+		return err
+	}
+	if rv.Type() == genericUnionType {
+		v, err := d.decodeGeneric(u[i])
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(GenericUnion{Index: i, Value: v}))
+		return nil
+	}
+	// A pointer to a wrapper is nil for null, as when encoded, and otherwise
+	// set through the wrapper it points to.
+	if rv.Kind() == reflect.Ptr && u[i] != Null && rv.Type().Implements(unionWrapperType) {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if rv.CanAddr() && rv.Addr().Type().Implements(unionWrapperType) {
+		p := rv.Addr().Interface().(UnionWrapper).SetUnionIndex(i)
+		if p == nil {
+			if u[i] != Null {
+				return fmt.Errorf(`"%s" has no value for union schema at index %d`, rv.Type(), i)
+			}
+			return nil
+		}
+		return d.decode(u[i], reflect.ValueOf(p))
+	}
+	if u[i] == Null {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	return d.decode(u[i], rv)
+}
+
+// decodeGeneric decodes a value into its generic representation.
+func (d *decoder) decodeGeneric(s Schema) (interface{}, error) {
+	var rv reflect.Value
+	switch s := resolve(s).(type) {
+	case Primitive:
+		switch s {
+		case Null:
+			return nil, nil
+		case Boolean:
+			return d.readBool()
+		case Int:
+			return d.readInt()
+		case Long:
+			return d.readLong()
+		case Float:
+			return d.readFloat()
+		case Double:
+			return d.readDouble()
+		case Bytes:
+			b, err := d.readBytes()
+			if d.alias {
+				return b, err
+			}
+			return append([]byte{}, b...), err
+		case String:
+			b, err := d.readBytes()
+			return string(b), err
+		}
+	case Record:
+		g := new(GenericRecord)
+		if err := d.decodeRecord(s, reflect.ValueOf(g).Elem()); err != nil {
+			return nil, err
+		}
+		return g, nil
+	case Enum:
+		rv = reflect.New(genericEnumType).Elem()
+	case Fixed:
+		rv = reflect.New(genericFixedType).Elem()
+	case Array:
+		rv = reflect.New(reflect.SliceOf(interfaceType)).Elem()
+	case Map:
+		rv = reflect.New(reflect.MapOf(reflect.TypeOf(""), interfaceType)).Elem()
+	case Union:
+		rv = reflect.New(genericUnionType).Elem()
+	case Logical:
+		factory, ok := DefaultFactories[s.LogicalType]
+		if !ok {
+			return d.decodeGeneric(s.Schema)
+		}
+		rv = reflect.ValueOf(factory()).Elem()
+	}
+	if !rv.IsValid() {
+		return nil, fmt.Errorf(`cannot decode schema type "%s"`, s.Type())
+	}
+	if err := d.decode(s, rv); err != nil {
+		return nil, err
+	}
+	return rv.Interface(), nil
+}
+
+// skip advances past a value without decoding it.
+func (d *decoder) skip(s Schema) error {
+	switch s := resolve(s).(type) {
+	case Primitive:
+		var err error
+		switch s {
+		case Null:
+		case Boolean:
+			_, err = d.next(1)
+		case Int, Long:
+			_, err = d.readLong()
+		case Float:
+			_, err = d.next(4)
+		case Double:
+			_, err = d.next(8)
+		case Bytes, String:
+			_, err = d.readBytes()
+		default:
+			err = fmt.Errorf(`cannot skip schema type "%s"`, s)
+		}
+		return err
+	case Record:
+		for _, f := range s.Fields {
+			if err := d.skip(f.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Enum:
+		_, err := d.readLong()
+		return err
+	case Fixed:
+		_, err := d.next(int(s.Size))
+		return err
+	case Array:
+		return d.skipBlocks(s.Items, nil)
+	case Map:
+		return d.skipBlocks(s.Values, String)
+	case Union:
+		i, err := d.readUnionIndex(s)
+		if err != nil {
+			return err
+		}
+		return d.skip(s[i])
+	case Logical:
+		return d.skip(s.Schema)
+	}
+	return fmt.Errorf(`cannot skip schema type "%s"`, s.Type())
+}
+
+// skipBlocks skips array or map blocks, using block sizes when present. Map
+// blocks pass key as String so each key is skipped before its value.
+func (d *decoder) skipBlocks(items Schema, key Schema) error {
+	for {
+		n, err := d.readLong()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if n < 0 {
+			size, err := d.readLong()
+			if err != nil {
+				return err
+			}
+			if _, err := d.next(int(size)); err != nil {
+				return err
+			}
+			continue
+		}
+		if n > int64(len(d.buf)-d.pos) {
+			if key == nil && zeroWidth(items, map[string]bool{}) {
+				continue // there is nothing to skip
+			}
+			return fmt.Errorf(`count %d is more than the %d bytes left`, n, len(d.buf)-d.pos)
+		}
+		for i := int64(0); i < n; i++ {
+			if key != nil {
+				if err := d.skip(key); err != nil {
+					return err
+				}
+			}
+			if err := d.skip(items); err != nil {
+				return err
+			}
+		}
+	}
+}
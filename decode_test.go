@@ -0,0 +1,187 @@
+package avro
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDecode_primitives(t *testing.T) {
+	is := is.New(t)
+
+	var b bool
+	is.NoErr(Decode(Boolean, []byte{1}, &b)) // decodes boolean
+	is.True(b)                               // boolean is true
+
+	var i int
+	is.NoErr(Decode(Int, []byte{0x80, 0x01}, &i)) // decodes int
+	is.Equal(i, 64)                               // int value
+
+	var i8 int8
+	is.True(Decode(Int, []byte{0x80, 0x02}, &i8) != nil) // overflow is an error
+
+	var f float64
+	is.NoErr(Decode(Float, []byte{0, 0, 0x80, 0x3f}, &f)) // decodes float into float64
+	is.Equal(f, 1.0)                                      // float value
+
+	var s string
+	is.NoErr(Decode(String, []byte{6, 'f', 'o', 'o'}, &s)) // decodes string
+	is.Equal(s, "foo")                                     // string value
+
+	is.True(Decode(String, []byte{6, 'f'}, &s) != nil)           // short input is an error
+	is.True(Decode(Int, []byte{2, 2}, &i) != nil)                // trailing input is an error
+	is.True(Decode(Int, []byte{2}, i) != nil)                    // non-pointer is an error
+	is.True(Decode(String, []byte{6, 'f', 'o', 'o'}, &i) != nil) // mismatched type is an error
+}
+
+func TestDecode_struct(t *testing.T) {
+	is := is.New(t)
+
+	type pet struct {
+		Name string   `avro:"name"`
+		Chip *[2]byte `avro:"chip"`
+	}
+	var p pet
+	is.NoErr(Decode(genericTestRecord, []byte{2, 'a', 0, 2, 7, 8}, &p)) // decodes struct, skipping kind
+	is.Equal(p.Name, "a")                                               // string field
+	is.Equal(*p.Chip, [2]byte{7, 8})                                    // union into pointer
+
+	is.NoErr(Decode(genericTestRecord, []byte{2, 'a', 0, 0}, &p)) // decodes null branch
+	is.True(p.Chip == nil)                                        // null into pointer
+
+	var xs []int
+	is.NoErr(Decode(Array{Items: Int}, []byte{3, 4, 2, 4, 4, 6, 8, 0}, &xs)) // negative block count with size
+	is.Equal(xs, []int{1, 2, 3, 4})                                          // items from all blocks
+
+	var m map[string]int
+	is.NoErr(Decode(Map{Values: Int}, []byte{2, 2, 'a', 2, 0}, &m)) // decodes map
+	is.Equal(m, map[string]int{"a": 1})                             // map value
+}
+
+func TestDecode_generic(t *testing.T) {
+	is := is.New(t)
+
+	var v interface{}
+	is.NoErr(Decode(genericTestRecord, []byte{2, 'a', 2, 2, 7, 8}, &v)) // decodes into interface{}
+	r, ok := v.(*GenericRecord)
+	is.True(ok) // record is a *GenericRecord
+	name, _ := r.Get("name")
+	is.Equal(name, "a")                                                        // string field
+	is.Equal(r.GetIndex(1), GenericEnum{Symbol: "DOG", Ordinal: 1})            // enum field
+	is.Equal(r.GetIndex(2), GenericUnion{Index: 1, Value: GenericFixed{7, 8}}) // union field
+	is.NoErr(genericTestRecord.Validate(r))                                    // decoded value is valid
+
+	b, err := Encode(genericTestRecord, r)
+	is.NoErr(err)                           // generic values encode
+	is.Equal(b, []byte{2, 'a', 2, 2, 7, 8}) // round trip
+
+	var g GenericRecord
+	is.NoErr(Decode(genericTestRecord, b, &g)) // decodes into GenericRecord
+	is.Equal(g.GetIndex(0), "a")               // string field
+
+	is.NoErr(Decode(Array{Items: Map{Values: Long}}, []byte{2, 2, 2, 'k', 2, 0, 0}, &v)) // decodes nested containers
+	is.Equal(v, []interface{}{map[string]interface{}{"k": int64(1)}})                    // generic containers
+}
+
+type reuseTestItem struct {
+	ID    int64            `avro:"id"`
+	Data  []byte           `avro:"data"`
+	Tags  []string         `avro:"tags"`
+	Attrs map[string]int32 `avro:"attrs"`
+}
+
+var reuseTestSchema = Record{
+	NameFields: NameFields{Name: "Item"},
+	Fields: []Field{
+		{Name: "id", Type: Long},
+		{Name: "data", Type: Bytes},
+		{Name: "tags", Type: Array{Items: String}},
+		{Name: "attrs", Type: Map{Values: Int}},
+	},
+}
+
+func TestDecode_options(t *testing.T) {
+	is := is.New(t)
+
+	b, err := Encode(reuseTestSchema, reuseTestItem{ID: 1, Data: []byte{1, 2}, Tags: []string{"a"}, Attrs: map[string]int32{"x": 1}})
+	is.NoErr(err)
+
+	var v reuseTestItem
+	is.NoErr(Decode(reuseTestSchema, b, &v, AliasBytes()))
+	is.Equal(v.Data, []byte{1, 2})
+	b[2] = 9
+	is.Equal(v.Data, []byte{9, 2}) // aliases the input
+
+	data := make([]byte, 0, 8)
+	v = reuseTestItem{Data: data, Attrs: map[string]int32{"old": 1}}
+	is.NoErr(Decode(reuseTestSchema, b, &v, ReuseValues()))
+	is.Equal(v.Data, []byte{9, 2})
+	is.Equal(&v.Data[:1][0], &data[:1][0])      // byte slice reused
+	is.Equal(v.Attrs, map[string]int32{"x": 1}) // map cleared
+	b[2] = 1
+	is.Equal(v.Data, []byte{9, 2}) // copied
+
+	v.Attrs = map[string]int32{"old": 1}
+	is.NoErr(Decode(reuseTestSchema, b, &v))
+	is.Equal(v.Attrs, map[string]int32{"old": 1, "x": 1}) // merged by default
+
+	var g GenericRecord
+	is.NoErr(Decode(reuseTestSchema, b, &g, ReuseValues()))
+	values := g.values
+	is.NoErr(Decode(reuseTestSchema, b, &g, ReuseValues()))
+	is.Equal(&values[0], &g.values[0]) // generic record reused
+	is.Equal(g.GetIndex(1), []byte{1, 2})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	benchmarkDecode(b)
+}
+
+func BenchmarkDecode_reuse(b *testing.B) {
+	benchmarkDecode(b, ReuseValues(), AliasBytes())
+}
+
+func benchmarkDecode(b *testing.B, opts ...DecodeOption) {
+	data, err := Encode(reuseTestSchema, reuseTestItem{
+		ID:    1,
+		Data:  []byte("data"),
+		Tags:  []string{"a", "b"},
+		Attrs: map[string]int32{"x": 1, "y": 2},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	var s Schema = reuseTestSchema
+	var v reuseTestItem
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Decode(s, data, &v, opts...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecode_blockCounts(t *testing.T) {
+	is := is.New(t)
+
+	var v interface{}
+	huge := binary.AppendVarint(nil, 1<<30)
+	is.True(Decode(Array{Items: Long}, huge, &v) != nil)                          // more items than bytes
+	is.True(Decode(Map{Values: Null}, huge, &v) != nil)                           // more entries than bytes
+	is.True(Decode(Array{Items: Null}, append(huge, 0), &v) != nil)               // too many items encoded in no bytes
+	is.True(Decode(Array{Items: Array{Items: Long}}, append(huge, 0), &v) != nil) // nested
+	is.NoErr(Decode(Array{Items: Null}, []byte{6, 0}, &v))
+	is.Equal(v, []interface{}{nil, nil, nil}) // items encoded in no bytes
+
+	writer := Record{NameFields: NameFields{Name: "R"}, Fields: []Field{
+		{Name: "a", Type: Array{Items: Null}},
+		{Name: "b", Type: Array{Items: Long}},
+		{Name: "c", Type: Int},
+	}}
+	reader := Record{NameFields: NameFields{Name: "R"}, Fields: []Field{{Name: "c", Type: Int}}}
+	data := append(binary.AppendVarint(nil, 1<<60), 0, 0, 2)
+	is.NoErr(DecodeProjected(writer, reader, data, &v)) // skips items encoded in no bytes at once
+	data = append([]byte{0}, binary.AppendVarint(nil, 1<<60)...)
+	is.True(DecodeProjected(writer, reader, data, &v) != nil) // cannot skip more items than bytes
+}
@@ -0,0 +1,254 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ParseDefault converts a default value decoded by encoding/json, as found
+// in a schema declaration, to the Go value Field.Default holds for schema s.
+// Numbers, as float64 or, to keep every digit of longs, json.Number, are
+// converted to the type of s, bytes and fixed defaults are ISO-8859-1
+// strings, records, arrays and maps are converted recursively and the default
+// of a union is of its first schema. Schemas parsed from JSON already hold
+// converted defaults; ParseDefault is for fields built in Go from JSON values.
+func ParseDefault(s Schema, v interface{}) (interface{}, error) {
+	if s == nil {
+		return nil, errors.New(`cannot parse default of nil schema`)
+	}
+	return parseDefault(s, v)
+}
+
+// DecodeDefault stores the default of f in the value pointed to by v, which
+// may be of any type accepted by Decode.
+func (f Field) DecodeDefault(v interface{}) error {
+	data, err := f.encodeDefault()
+	if err != nil {
+		return err
+	}
+	if err := Decode(f.Type, data, v); err != nil {
+		return fmt.Errorf(`default of field "%s": %s`, f.Name, err)
+	}
+	return nil
+}
+
+// encodeDefault returns the binary encoding of the default of f.
+func (f Field) encodeDefault() ([]byte, error) {
+	if f.Default == nil {
+		return nil, fmt.Errorf(`field "%s" has no default`, f.Name)
+	}
+	data, err := Encode(f.Type, *f.Default)
+	if err != nil {
+		return nil, fmt.Errorf(`default of field "%s": %s`, f.Name, err)
+	}
+	return data, nil
+}
+
+// parseDefault converts a default value decoded from JSON, as found in a
+// schema declaration, to the Go value used for schema s: int32 for int, int64
+// for long, float32 for float, []byte for bytes and fixed (whose characters
+// must be in ISO-8859-1), and so on for records, arrays and maps. The default
+// of a union is of its first schema.
+func parseDefault(s Schema, v interface{}) (interface{}, error) {
+	switch s := resolve(s).(type) {
+	case Primitive:
+		return parsePrimitiveDefault(s, v)
+	case Record:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf(`default of record "%s" must be an object`, s.Fullname())
+		}
+		r := make(map[string]interface{}, len(s.Fields))
+		for _, f := range s.Fields {
+			fv, ok := m[f.Name]
+			if !ok {
+				if f.Default == nil {
+					return nil, fmt.Errorf(`default of record "%s" is missing field "%s"`, s.Fullname(), f.Name)
+				}
+				r[f.Name] = *f.Default
+				continue
+			}
+			d, err := parseDefault(f.Type, fv)
+			if err != nil {
+				return nil, fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+			r[f.Name] = d
+		}
+		return r, nil
+	case Enum:
+		sym, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf(`default of enum "%s" must be a string`, s.Fullname())
+		}
+		return sym, s.exists(sym)
+	case Fixed:
+		b, err := latin1Bytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return b, s.checkBytes(b)
+	case Array:
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, errors.New(`default of array must be an array`)
+		}
+		a := make([]interface{}, len(items))
+		for i, item := range items {
+			d, err := parseDefault(s.Items, item)
+			if err != nil {
+				return nil, fmt.Errorf(`item %d: %s`, i, err)
+			}
+			a[i] = d
+		}
+		return a, nil
+	case Map:
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errors.New(`default of map must be an object`)
+		}
+		m := make(map[string]interface{}, len(values))
+		for k, value := range values {
+			d, err := parseDefault(s.Values, value)
+			if err != nil {
+				return nil, fmt.Errorf(`value "%s": %s`, k, err)
+			}
+			m[k] = d
+		}
+		return m, nil
+	case Union:
+		if len(s) == 0 {
+			return nil, errors.New(`union may not be empty`)
+		}
+		return parseDefault(s[0], v)
+	case Logical:
+		return parseDefault(s.Schema, v)
+	}
+	return nil, fmt.Errorf(`cannot parse default of schema type "%s"`, s.Type())
+}
+
+func parsePrimitiveDefault(p Primitive, v interface{}) (interface{}, error) {
+	switch p {
+	case Null:
+		if v == nil {
+			return nil, nil
+		}
+	case Boolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case Int, Long:
+		if num, ok := v.(json.Number); ok {
+			if n, err := num.Int64(); err == nil {
+				if p == Long {
+					return n, nil
+				}
+				if n < math.MinInt32 || n > math.MaxInt32 {
+					return nil, fmt.Errorf(`default %s overflows "int"`, num)
+				}
+				return int32(n), nil
+			}
+			// Numbers such as 1e3 or 1.0 are read as floats.
+			f, err := num.Float64()
+			if err != nil {
+				break
+			}
+			v = f
+		}
+		n, ok := v.(float64)
+		if !ok || n != math.Trunc(n) {
+			break
+		}
+		if p == Int {
+			if n < math.MinInt32 || n > math.MaxInt32 {
+				return nil, fmt.Errorf(`default %v overflows "int"`, n)
+			}
+			return int32(n), nil
+		}
+		if n < math.MinInt64 || n >= math.MaxInt64 {
+			return nil, fmt.Errorf(`default %v overflows "long"`, n)
+		}
+		return int64(n), nil
+	case Float, Double:
+		n, ok := v.(float64)
+		if num, isNum := v.(json.Number); isNum {
+			f, err := num.Float64()
+			n, ok = f, err == nil
+		}
+		if !ok {
+			break
+		}
+		if p == Float {
+			return float32(n), nil
+		}
+		return n, nil
+	case Bytes:
+		return latin1Bytes(v)
+	case String:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf(`default %s is not a valid "%s"`, jsonString(v), p)
+}
+
+// latin1Bytes converts a JSON string default to bytes, one byte per
+// character as the specification requires for bytes and fixed.
+func latin1Bytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf(`default %s of bytes must be a string`, jsonString(v))
+	}
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xff {
+			return nil, fmt.Errorf(`default %s has character %q outside ISO-8859-1`, jsonString(v), r)
+		}
+		b = append(b, byte(r))
+	}
+	return b, nil
+}
+
+// unmarshalDefault decodes the JSON of a default, with numbers as
+// json.Number so that longs keep every digit.
+func unmarshalDefault(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	if d.More() {
+		return nil, errors.New(`invalid character after the default`)
+	}
+	return v, nil
+}
+
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// defaultJSON converts a default value of schema s, which may be of any Go
+// type accepted by Encode, to the value written in a schema declaration.
+func defaultJSON(s Schema, v interface{}) (interface{}, error) {
+	b, err := Encode(s, v)
+	if err != nil {
+		return nil, err
+	}
+	d := decoder{buf: b}
+	return d.jsonValue(s, false)
+}
+
+func latin1String(b []byte) string {
+	r := make([]rune, len(b))
+	for i, c := range b {
+		r[i] = rune(c)
+	}
+	return string(r)
+}
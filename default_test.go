@@ -0,0 +1,133 @@
+package avro
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseDefault(t *testing.T) {
+	is := is.New(t)
+
+	r := Record{
+		NameFields: NameFields{Name: "R"},
+		Fields: []Field{
+			{Name: "a", Type: Int},
+			{Name: "b", Type: String, Default: new(interface{})},
+		},
+	}
+	*r.Fields[1].Default = "x"
+	for _, test := range []struct {
+		schema Schema
+		json   interface{}
+		want   interface{}
+	}{
+		{Null, nil, nil},
+		{Boolean, true, true},
+		{Int, 1.0, int32(1)},
+		{Long, -2.0, int64(-2)},
+		{Float, 1.5, float32(1.5)},
+		{Double, 2.5, 2.5},
+		{Bytes, "aÿ", []byte{'a', 0xff}},
+		{String, "s", "s"},
+		{Array{Items: Int}, []interface{}{1.0}, []interface{}{int32(1)}},
+		{Map{Values: Long}, map[string]interface{}{"k": 1.0}, map[string]interface{}{"k": int64(1)}},
+		{Union{Int, Null}, 3.0, int32(3)},
+		{Long, json.Number("9007199254740993"), int64(9007199254740993)},
+		{Long, json.Number("9223372036854775807"), int64(math.MaxInt64)},
+		{Long, json.Number("-9223372036854775808"), int64(math.MinInt64)},
+		{Int, json.Number("2147483647"), int32(math.MaxInt32)},
+		{Int, json.Number("-2147483648"), int32(math.MinInt32)},
+		{Int, json.Number("1e3"), int32(1000)},
+		{Float, json.Number("0.5"), float32(0.5)},
+		{Double, json.Number("1"), 1.0},
+		{r, map[string]interface{}{"a": 1.0}, map[string]interface{}{"a": int32(1), "b": "x"}},
+	} {
+		got, err := parseDefault(test.schema, test.json)
+		is.NoErr(err)
+		is.Equal(got, test.want) // default parsed for its schema
+	}
+
+	for _, test := range []struct {
+		schema Schema
+		json   interface{}
+	}{
+		{Int, 1.5},
+		{Int, 1e10},
+		{Int, json.Number("2147483648")},
+		{Int, json.Number("-2147483649")},
+		{Long, json.Number("9223372036854775808")},
+		{Long, json.Number("1.5")},
+		{Bytes, "Ā"},
+		{Fixed{NameFields: NameFields{Name: "F"}, Size: 2}, "a"},
+		{Enum{NameFields: NameFields{Name: "E"}, Symbols: []string{"A"}}, "B"},
+		{Union{Null, Int}, 1.0},
+		{r, map[string]interface{}{}},
+	} {
+		_, err := parseDefault(test.schema, test.json)
+		is.True(err != nil) // invalid default
+	}
+
+	s, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [
+		{"name": "a", "type": "long", "default": 9007199254740993},
+		{"name": "b", "type": "long", "default": 9223372036854775807}
+	]}`))
+	is.NoErr(err)
+	is.Equal(*s.(Record).Fields[0].Default, int64(9007199254740993)) // every digit kept
+	is.Equal(*s.(Record).Fields[1].Default, int64(math.MaxInt64))
+}
+
+func TestDefaultJSON(t *testing.T) {
+	is := is.New(t)
+
+	type pair struct {
+		A []byte `avro:"a"`
+		B *int32 `avro:"b"`
+	}
+	r := Record{
+		NameFields: NameFields{Name: "Pair"},
+		Fields: []Field{
+			{Name: "a", Type: Bytes},
+			{Name: "b", Type: Union{Null, Int}},
+		},
+	}
+	v, err := defaultJSON(r, pair{A: []byte{0xff}})
+	is.NoErr(err)
+	is.Equal(v, map[string]interface{}{"a": "ÿ", "b": nil}) // struct written as JSON value
+}
+
+func TestField_DecodeDefault(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [
+		{"name": "n", "type": "int", "default": 7},
+		{"name": "b", "type": "bytes", "default": "ÿ"},
+		{"name": "u", "type": ["null", "string"], "default": null},
+		{"name": "tags", "type": {"type": "array", "items": "string"}, "default": ["a"]},
+		{"name": "none", "type": "long"}
+	]}`))
+	is.NoErr(err)
+	r := s.(Record)
+	var n int
+	is.NoErr(r.Fields[0].DecodeDefault(&n))
+	is.Equal(n, 7) // typed number
+	var b []byte
+	is.NoErr(r.Fields[1].DecodeDefault(&b))
+	is.Equal(b, []byte{0xff}) // ISO-8859-1 bytes
+	u := new(string)
+	is.NoErr(r.Fields[2].DecodeDefault(&u))
+	is.True(u == nil) // union default of the first branch
+	var tags []string
+	is.NoErr(r.Fields[3].DecodeDefault(&tags))
+	is.Equal(tags, []string{"a"})
+	is.True(r.Fields[4].DecodeDefault(&n) != nil) // no default
+
+	d, err := ParseDefault(Long, 3.0)
+	is.NoErr(err)
+	f := Field{Name: "l", Type: Long, Default: &d}
+	is.NoErr(Record{NameFields: NameFields{Name: "L"}, Fields: []Field{f}}.Valid()) // parsed default is valid
+	_, err = ParseDefault(Long, "3")
+	is.True(err != nil) // wrong JSON type
+}
@@ -0,0 +1,332 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change between two schemas.
+type ChangeKind string
+
+const (
+	FieldAdded       ChangeKind = "field-added"
+	FieldRemoved     ChangeKind = "field-removed"
+	FieldRenamed     ChangeKind = "field-renamed"
+	TypeChanged      ChangeKind = "type-changed"
+	DefaultChanged   ChangeKind = "default-changed"
+	OrderChanged     ChangeKind = "order-changed"
+	SymbolAdded      ChangeKind = "symbol-added"
+	SymbolRemoved    ChangeKind = "symbol-removed"
+	BranchAdded      ChangeKind = "branch-added"
+	BranchRemoved    ChangeKind = "branch-removed"
+	SizeChanged      ChangeKind = "size-changed"
+	NameChanged      ChangeKind = "name-changed"
+	NamespaceChanged ChangeKind = "namespace-changed"
+	DocChanged       ChangeKind = "doc-changed"
+)
+
+// Impact classifies a change by its effect on reading data.
+type Impact string
+
+const (
+	// ImpactNone changes do not affect data, such as doc changes.
+	ImpactNone Impact = "none"
+	// ImpactCompatible changes keep data of each schema readable by the
+	// other.
+	ImpactCompatible Impact = "compatible"
+	// ImpactBackward changes let the new schema read data of the old, but
+	// not the other way around.
+	ImpactBackward Impact = "backward"
+	// ImpactForward changes let the old schema read data of the new, but not
+	// the other way around.
+	ImpactForward Impact = "forward"
+	// ImpactBreaking changes make data of each schema unreadable by the
+	// other.
+	ImpactBreaking Impact = "breaking"
+)
+
+// combine returns the impact of two changes together.
+func (i Impact) combine(j Impact) Impact {
+	rank := map[Impact]int{ImpactNone: 0, ImpactCompatible: 1, ImpactBackward: 2, ImpactForward: 2, ImpactBreaking: 3}
+	switch {
+	case i == j:
+		return i
+	case rank[i] == 2 && rank[j] == 2:
+		return ImpactBreaking
+	case rank[i] > rank[j]:
+		return i
+	}
+	return j
+}
+
+// Change is a difference between two schemas found by Diff.
+type Change struct {
+	Kind    ChangeKind `json:"kind"`
+	Path    string     `json:"path"` // as in Node, in the new schema where it applies
+	Impact  Impact     `json:"impact"`
+	Message string     `json:"message"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s [%s]", c.Path, c.Message, c.Impact)
+}
+
+// DiffReport lists the changes from an old schema to a new one.
+type DiffReport struct {
+	Impact  Impact   `json:"impact"` // of all changes together
+	Changes []Change `json:"changes"`
+}
+
+// String returns the report as text, with the overall impact followed by a
+// line for each change.
+func (r DiffReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "impact: %s\n", r.Impact)
+	for _, c := range r.Changes {
+		fmt.Fprintf(&b, "%s\n", c)
+	}
+	return b.String()
+}
+
+// Diff reports the changes from schema old to schema new: fields added,
+// removed or renamed (through the aliases of the new field), type, default,
+// order, size and doc changes, enum symbols and union branches added or
+// removed, and renamed or moved named types. Each change is classified by
+// its Impact, using the resolution rules of CheckCompatibility.
+func Diff(old, new Schema) (DiffReport, error) {
+	if old == nil || new == nil {
+		return DiffReport{}, errors.New(`cannot diff nil schema`)
+	}
+	d := differ{visited: map[[2]string]bool{}}
+	d.diff("$", old, new)
+	r := DiffReport{Impact: ImpactNone, Changes: d.changes}
+	if r.Changes == nil {
+		r.Changes = []Change{}
+	}
+	for _, c := range r.Changes {
+		r.Impact = r.Impact.combine(c.Impact)
+	}
+	return r, nil
+}
+
+type differ struct {
+	visited map[[2]string]bool // pairs of named types already compared
+	changes []Change
+}
+
+func (d *differ) add(kind ChangeKind, path string, impact Impact, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{Kind: kind, Path: path, Impact: impact, Message: fmt.Sprintf(format, args...)})
+}
+
+// impactOf returns the impact of replacing schema old by new.
+func impactOf(old, new Schema) Impact {
+	backward := CheckCompatibility(new, old) == nil
+	forward := CheckCompatibility(old, new) == nil
+	switch {
+	case backward && forward:
+		return ImpactCompatible
+	case backward:
+		return ImpactBackward
+	case forward:
+		return ImpactForward
+	}
+	return ImpactBreaking
+}
+
+func (d *differ) diff(path string, old, new Schema) {
+	old, new = resolve(old), resolve(new)
+	ol, oldLogical := old.(Logical)
+	nl, newLogical := new.(Logical)
+	switch {
+	case oldLogical && newLogical && ol.LogicalType == nl.LogicalType:
+		d.diff(path, ol.Schema, nl.Schema)
+		return
+	case oldLogical || newLogical:
+		d.add(TypeChanged, path, impactOf(old, new), `type changed from "%s" to "%s"`, old.Type(), new.Type())
+		return
+	}
+	if old.Type() != new.Type() {
+		d.add(TypeChanged, path, impactOf(old, new), `type changed from "%s" to "%s"`, old.Type(), new.Type())
+		return
+	}
+	if on, ok := old.(NamedSchema); ok {
+		nn := new.(NamedSchema)
+		pair := [2]string{on.Fullname(), nn.Fullname()}
+		if d.visited[pair] {
+			return
+		}
+		d.visited[pair] = true
+		d.diffNames(path, on.GetNameFields(), nn.GetNameFields())
+	}
+	switch o := old.(type) {
+	case Record:
+		n := new.(Record)
+		d.diffDoc(path, o.Doc, n.Doc)
+		d.diffFields(path, o, n)
+	case Enum:
+		d.diffEnum(path, o, new.(Enum))
+	case Fixed:
+		n := new.(Fixed)
+		if o.Size != n.Size {
+			d.add(SizeChanged, path, ImpactBreaking, `size changed from %d to %d`, o.Size, n.Size)
+		}
+	case Array:
+		d.diff(path+".items", o.Items, new.(Array).Items)
+	case Map:
+		d.diff(path+".values", o.Values, new.(Map).Values)
+	case Union:
+		d.diffUnion(path, o, new.(Union))
+	}
+}
+
+func (d *differ) diffNames(path string, old, new NameFields) {
+	if unqualified(old.Name) != unqualified(new.Name) {
+		d.add(NameChanged, path, impactOfNames(old, new), `name changed from "%s" to "%s"`, old.Fullname(), new.Fullname())
+	} else if old.Namespace != new.Namespace {
+		d.add(NamespaceChanged, path, ImpactCompatible, `namespace changed from "%s" to "%s"`, old.Namespace, new.Namespace)
+	}
+}
+
+func impactOfNames(old, new NameFields) Impact {
+	backward, forward := namesMatch(new, old), namesMatch(old, new)
+	switch {
+	case backward && forward:
+		return ImpactCompatible
+	case backward:
+		return ImpactBackward
+	case forward:
+		return ImpactForward
+	}
+	return ImpactBreaking
+}
+
+func (d *differ) diffDoc(path, old, new string) {
+	if old != new {
+		d.add(DocChanged, path, ImpactNone, `doc changed from %q to %q`, old, new)
+	}
+}
+
+func (d *differ) diffFields(path string, old, new Record) {
+	matched := map[string]bool{}
+	for _, nf := range new.Fields {
+		fpath := path + fieldPath(nf.Name)
+		i := writerFieldIndex(nf, old)
+		if i < 0 {
+			if nf.Default != nil {
+				d.add(FieldAdded, fpath, ImpactCompatible, `field "%s" added with a default`, nf.Name)
+			} else {
+				d.add(FieldAdded, fpath, ImpactForward, `field "%s" added without a default`, nf.Name)
+			}
+			continue
+		}
+		of := &old.Fields[i]
+		if of.Name != nf.Name {
+			impact := ImpactBackward
+			if of.Default != nil {
+				impact = ImpactCompatible
+			}
+			d.add(FieldRenamed, fpath, impact, `field "%s" renamed to "%s"`, of.Name, nf.Name)
+		}
+		matched[of.Name] = true
+		d.diffDoc(fpath, of.Doc, nf.Doc)
+		switch {
+		case of.Default == nil && nf.Default != nil:
+			d.add(DefaultChanged, fpath, ImpactCompatible, `default %s added`, jsonDefault(nf))
+		case of.Default != nil && nf.Default == nil:
+			d.add(DefaultChanged, fpath, ImpactCompatible, `default %s removed`, jsonDefault(*of))
+		case of.Default != nil && !reflect.DeepEqual(*of.Default, *nf.Default):
+			d.add(DefaultChanged, fpath, ImpactCompatible, `default changed from %s to %s`, jsonDefault(*of), jsonDefault(nf))
+		}
+		if orderOf(*of) != orderOf(nf) {
+			d.add(OrderChanged, fpath, ImpactCompatible, `order changed from "%s" to "%s"`, orderOf(*of), orderOf(nf))
+		}
+		d.diff(fpath, of.Type, nf.Type)
+	}
+	for _, of := range old.Fields {
+		if matched[of.Name] {
+			continue
+		}
+		if of.Default != nil {
+			d.add(FieldRemoved, path+fieldPath(of.Name), ImpactCompatible, `field "%s" removed, it had a default`, of.Name)
+		} else {
+			d.add(FieldRemoved, path+fieldPath(of.Name), ImpactBackward, `field "%s" removed, it had no default`, of.Name)
+		}
+	}
+}
+
+// jsonDefault returns the default of f as JSON.
+func jsonDefault(f Field) string {
+	v, err := defaultJSON(f.Type, *f.Default)
+	if err != nil {
+		return fmt.Sprint(*f.Default)
+	}
+	return jsonString(v)
+}
+
+func orderOf(f Field) string {
+	if f.Order == "" {
+		return "ascending"
+	}
+	return f.Order
+}
+
+func (d *differ) diffEnum(path string, old, new Enum) {
+	d.diffDoc(path, old.Doc, new.Doc)
+	for _, sym := range new.Symbols {
+		if old.exists(sym) != nil {
+			impact := ImpactBackward
+			if old.Default != "" {
+				impact = ImpactCompatible
+			}
+			d.add(SymbolAdded, path, impact, `symbol "%s" added`, sym)
+		}
+	}
+	for _, sym := range old.Symbols {
+		if new.exists(sym) != nil {
+			impact := ImpactForward
+			if new.Default != "" {
+				impact = ImpactCompatible
+			}
+			d.add(SymbolRemoved, path, impact, `symbol "%s" removed`, sym)
+		}
+	}
+	if old.Default != new.Default {
+		d.add(DefaultChanged, path, ImpactCompatible, `default changed from "%s" to "%s"`, old.Default, new.Default)
+	}
+}
+
+func (d *differ) diffUnion(path string, old, new Union) {
+	// Branches match by name, or as named types of the same type whose
+	// names match as in schema resolution.
+	branch := func(u Union, s Schema) Schema {
+		for _, b := range u {
+			if unionName(b) == unionName(s) {
+				return b
+			}
+		}
+		sn, ok := s.(NamedSchema)
+		if !ok {
+			return nil
+		}
+		for _, b := range u {
+			if bn, ok := b.(NamedSchema); ok && b.Type() == s.Type() && namesMatch(bn.GetNameFields(), sn.GetNameFields()) {
+				return b
+			}
+		}
+		return nil
+	}
+	for _, n := range new {
+		if o := branch(old, n); o != nil {
+			d.diff(path+unionPath(n), o, n)
+		} else {
+			d.add(BranchAdded, path+unionPath(n), ImpactBackward, `branch "%s" added`, unionName(n))
+		}
+	}
+	for _, o := range old {
+		if branch(new, o) == nil {
+			d.add(BranchRemoved, path+unionPath(o), ImpactForward, `branch "%s" removed`, unionName(o))
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package avro
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDiff(t *testing.T) {
+	is := is.New(t)
+
+	parse := func(spec string) Schema {
+		s, err := SchemaUnmarshalJSON([]byte(spec))
+		is.NoErr(err)
+		return s
+	}
+	old := parse(`{"type": "record", "name": "a.User", "doc": "a user", "fields": [
+		{"name": "id", "type": "int"},
+		{"name": "mail", "type": "string"},
+		{"name": "age", "type": "int", "default": 1},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}},
+		{"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 4}},
+		{"name": "note", "type": ["null", "string"]},
+		{"name": "gone", "type": "string"},
+		{"name": "next", "type": ["null", "User"]}
+	]}`)
+	new := parse(`{"type": "record", "name": "b.User", "doc": "the user", "fields": [
+		{"name": "id", "type": "long"},
+		{"name": "email", "type": "string", "aliases": ["mail"]},
+		{"name": "age", "type": "int", "default": 2, "order": "descending"},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "C"]}},
+		{"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 8}},
+		{"name": "note", "type": ["null", "string", "int"]},
+		{"name": "added", "type": "string", "default": ""},
+		{"name": "next", "type": ["null", "User"]}
+	]}`)
+
+	r, err := Diff(old, new)
+	is.NoErr(err)
+	var got []string
+	for _, c := range r.Changes {
+		got = append(got, string(c.Kind)+" "+c.Path+" "+string(c.Impact))
+	}
+	is.Equal(got, []string{
+		"namespace-changed $ compatible",
+		"doc-changed $ none",
+		"type-changed $.id backward",
+		"field-renamed $.email backward",
+		"default-changed $.age compatible",
+		"order-changed $.age compatible",
+		"namespace-changed $.kind compatible",
+		"symbol-added $.kind backward",
+		"symbol-removed $.kind forward",
+		"namespace-changed $.hash compatible",
+		"size-changed $.hash breaking",
+		"branch-added $.note{int} backward",
+		"field-added $.added compatible",
+		"field-removed $.gone backward",
+	}) // every change, classified
+	is.Equal(r.Impact, ImpactBreaking) // overall impact
+
+	is.True(strings.HasPrefix(r.String(), "impact: breaking\n$: namespace changed from \"a\" to \"b\" [compatible]\n")) // human-readable
+	b, err := json.Marshal(r.Changes[2])
+	is.NoErr(err)
+	is.Equal(string(b), `{"kind":"type-changed","path":"$.id","impact":"backward","message":"type changed from \"int\" to \"long\""}`) // machine-readable
+
+	r, err = Diff(old, old)
+	is.NoErr(err)
+	is.Equal(r.Impact, ImpactNone) // no changes
+	is.Equal(len(r.Changes), 0)
+
+	r, err = Diff(Record{NameFields: NameFields{Name: "R"}, Fields: []Field{{Name: "a", Type: Int}}},
+		Record{NameFields: NameFields{Name: "R"}, Fields: []Field{{Name: "a", Type: Int}, {Name: "b", Type: Int}}})
+	is.NoErr(err)
+	is.Equal(r.Impact, ImpactForward) // field without default
+}
@@ -0,0 +1,428 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Encode returns the Avro binary encoding of v according to s.
+//
+// Records may be given as structs (matching fields by name or "avro" tag),
+// maps with string keys or GenericRecord. Union branches are chosen with
+// GenericUnion, or otherwise by the first schema which v validates against;
+// nil and nil pointers select "null".
+func Encode(s Schema, v interface{}) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New(`cannot encode with nil schema`)
+	}
+	var e encoder
+	if err := e.encode(s, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// encoder appends the binary encoding of values to buf.
+type encoder struct {
+	buf []byte
+	// blockItems is the most items in each array or map block, or 0 for a
+	// single block.
+	blockItems int
+	// sizedBlocks writes block counts as negative, followed by the size of
+	// the block in bytes, so readers can skip blocks.
+	sizedBlocks bool
+}
+
+func (e *encoder) writeLong(n int64) {
+	e.buf = binary.AppendVarint(e.buf, n)
+}
+
+func (e *encoder) writeBytes(b []byte) {
+	e.writeLong(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) writeString(s string) {
+	e.writeLong(int64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) writeFloat(f float32) {
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(f))
+}
+
+func (e *encoder) writeDouble(f float64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(f))
+}
+
+// indirect follows pointers and interfaces. The returned Value is invalid if
+// rv is nil or leads to nil.
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.IsValid() && (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+func (e *encoder) encode(s Schema, rv reflect.Value) error {
+	s = resolve(s)
+	rv = indirect(rv)
+	switch s := s.(type) {
+	case Union:
+		return e.encodeUnion(s, rv)
+	case Logical:
+		if rv.IsValid() && (rv.Type() == timeType || rv.Type() == durationType) {
+			n, err := s.fromTime(rv)
+			if err != nil {
+				return err
+			}
+			rv = reflect.ValueOf(n)
+		}
+		return e.encode(s.Schema, rv)
+	}
+	if !rv.IsValid() {
+		if s == Null {
+			return nil
+		}
+		return fmt.Errorf(`nil is not a valid "%s"`, s.Type())
+	}
+	switch s := s.(type) {
+	case Primitive:
+		return e.encodePrimitive(s, rv)
+	case Record:
+		return e.encodeRecord(s, rv)
+	case Enum:
+		return e.encodeEnum(s, rv)
+	case Fixed:
+		return e.encodeFixed(s, rv)
+	case Array:
+		return e.encodeArray(s, rv)
+	case Map:
+		return e.encodeMap(s, rv)
+	}
+	return fmt.Errorf(`cannot encode schema type "%s"`, s.Type())
+}
+
+func (e *encoder) encodePrimitive(p Primitive, rv reflect.Value) error {
+	switch p {
+	case Boolean:
+		if rv.Kind() == reflect.Bool {
+			if rv.Bool() {
+				e.buf = append(e.buf, 1)
+			} else {
+				e.buf = append(e.buf, 0)
+			}
+			return nil
+		}
+	case Int, Long:
+		n, ok := intValue(rv)
+		if !ok {
+			break
+		}
+		if p == Int && (n < math.MinInt32 || n > math.MaxInt32) {
+			return fmt.Errorf(`value %d overflows "int"`, n)
+		}
+		e.writeLong(n)
+		return nil
+	case Float:
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			e.writeFloat(float32(rv.Float()))
+			return nil
+		}
+	case Double:
+		switch rv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			e.writeDouble(rv.Float())
+			return nil
+		}
+	case Bytes, String:
+		switch {
+		case rv.Kind() == reflect.String:
+			e.writeString(rv.String())
+			return nil
+		case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+			e.writeBytes(rv.Bytes())
+			return nil
+		}
+	}
+	return fmt.Errorf(`value of type "%s" is not a valid "%s"`, rv.Type(), p)
+}
+
+// intValue returns the value of an integer kind as int64. The bool is false
+// if rv is not an integer or does not fit in an int64.
+func intValue(rv reflect.Value) (int64, bool) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		return int64(u), u <= math.MaxInt64
+	}
+	return 0, false
+}
+
+func (e *encoder) encodeRecord(r Record, rv reflect.Value) error {
+	if rv.Type() == genericRecordType {
+		g := rv.Interface().(GenericRecord)
+		if err := g.validate(r, func(int, interface{}) error { return nil }); err != nil {
+			return err
+		}
+		for i, f := range r.Fields {
+			if err := e.encode(f.Type, reflect.ValueOf(g.values[i])); err != nil {
+				return fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+		}
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		fields := structFields(rv.Type())
+		for _, f := range r.Fields {
+			i, ok := structIndex(fields, f)
+			if !ok {
+				return fmt.Errorf(`field "%s": missing from struct "%s"`, f.Name, rv.Type())
+			}
+			if err := e.encode(f.Type, rv.Field(i)); err != nil {
+				return fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+		}
+		return nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf(`map key has type "%s" but it must be string`, rv.Type().Key().Kind())
+		}
+		for _, f := range r.Fields {
+			fv := rv.MapIndex(reflect.ValueOf(f.Name).Convert(rv.Type().Key()))
+			for _, a := range f.Aliases {
+				if !fv.IsValid() {
+					fv = rv.MapIndex(reflect.ValueOf(a).Convert(rv.Type().Key()))
+				}
+			}
+			if !fv.IsValid() {
+				return fmt.Errorf(`field "%s": missing from map`, f.Name)
+			}
+			if err := e.encode(f.Type, fv); err != nil {
+				return fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf(`value of type "%s" is not a valid record`, rv.Type())
+}
+
+// structFieldsCache holds the result of structFields by type.
+var structFieldsCache sync.Map
+
+// structFields maps record field names to the index of the struct field
+// holding them. The map must not be modified.
+func structFields(t reflect.Type) map[string]int {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.(map[string]int)
+	}
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if rf := t.Field(i); !skipStructField(rf) {
+			fields[structFieldName(rf)] = i
+		}
+	}
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+// structIndex returns the index of the struct field holding record field f,
+// found by the name or an alias of f.
+func structIndex(fields map[string]int, f Field) (int, bool) {
+	if i, ok := fields[f.Name]; ok {
+		return i, true
+	}
+	for _, a := range f.Aliases {
+		if i, ok := fields[a]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (e *encoder) encodeEnum(en Enum, rv reflect.Value) error {
+	var symbol string
+	switch {
+	case rv.Type() == genericEnumType:
+		g := rv.Interface().(GenericEnum)
+		if err := en.checkGeneric(g); err != nil {
+			return err
+		}
+		e.writeLong(int64(g.Ordinal))
+		return nil
+	case rv.Kind() == reflect.String:
+		symbol = rv.String()
+	default:
+		n, ok := ordinalValue(rv)
+		if !ok {
+			return fmt.Errorf(`value of type "%s" is not a valid enum`, rv.Type())
+		}
+		if n < 0 || n >= int64(len(en.Symbols)) {
+			return fmt.Errorf(`enum has no symbol at index %d`, n)
+		}
+		e.writeLong(n)
+		return nil
+	}
+	for i, sym := range en.Symbols {
+		if sym == symbol {
+			e.writeLong(int64(i))
+			return nil
+		}
+	}
+	return fmt.Errorf(`symbol "%s" does not exist in the enum`, symbol)
+}
+
+func (e *encoder) encodeFixed(f Fixed, rv reflect.Value) error {
+	switch {
+	case rv.Kind() == reflect.String:
+		if err := f.checkBytes([]byte(rv.String())); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, rv.String()...)
+		return nil
+	case rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8:
+		if err := f.checkBytes(rv.Bytes()); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, rv.Bytes()...)
+		return nil
+	case rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8:
+		if rv.Len() != int(f.Size) {
+			return fmt.Errorf(`value has %d bytes, but should have %d`, rv.Len(), f.Size)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			e.buf = append(e.buf, byte(rv.Index(i).Uint()))
+		}
+		return nil
+	}
+	return fmt.Errorf(`value of type "%s" is not a valid fixed`, rv.Type())
+}
+
+func (e *encoder) encodeArray(a Array, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+	default:
+		return fmt.Errorf(`value of type "%s" is not a valid array`, rv.Type())
+	}
+	return e.writeBlocks(rv.Len(), func(i int) error {
+		if err := e.encode(a.Items, rv.Index(i)); err != nil {
+			return fmt.Errorf(`item at index %d: %s`, i, err)
+		}
+		return nil
+	})
+}
+
+func (e *encoder) encodeMap(m Map, rv reflect.Value) error {
+	if rv.Kind() != reflect.Map {
+		return fmt.Errorf(`value of type "%s" is not a valid map`, rv.Type())
+	}
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf(`map key has type "%s" but it must be string`, rv.Type().Key().Kind())
+	}
+	// Keys are sorted so equal maps have equal encodings.
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return e.writeBlocks(len(keys), func(i int) error {
+		e.writeString(keys[i].String())
+		if err := e.encode(m.Values, rv.MapIndex(keys[i])); err != nil {
+			return fmt.Errorf(`value for key "%s": %s`, keys[i].String(), err)
+		}
+		return nil
+	})
+}
+
+// writeBlocks writes n array or map items, written by item, in blocks of at
+// most blockItems followed by the empty block which ends them.
+func (e *encoder) writeBlocks(n int, item func(i int) error) error {
+	size := e.blockItems
+	if size <= 0 {
+		size = n
+	}
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		if !e.sizedBlocks {
+			e.writeLong(int64(end - start))
+		}
+		mark := len(e.buf)
+		for i := start; i < end; i++ {
+			if err := item(i); err != nil {
+				return err
+			}
+		}
+		if e.sizedBlocks {
+			// Insert the negative count and the size before the items.
+			var h [2 * binary.MaxVarintLen64]byte
+			l := binary.PutVarint(h[:], int64(start-end))
+			l += binary.PutVarint(h[l:], int64(len(e.buf)-mark))
+			e.buf = append(e.buf, h[:l]...)
+			copy(e.buf[mark+l:], e.buf[mark:len(e.buf)-l])
+			copy(e.buf[mark:], h[:l])
+		}
+	}
+	e.writeLong(0)
+	return nil
+}
+
+func (e *encoder) encodeUnion(u Union, rv reflect.Value) error {
+	if rv.IsValid() && rv.Type() == genericUnionType {
+		g := rv.Interface().(GenericUnion)
+		if g.Index < 0 || g.Index >= len(u) {
+			return fmt.Errorf(`union has no schema at index %d`, g.Index)
+		}
+		e.writeLong(int64(g.Index))
+		return e.encode(u[g.Index], reflect.ValueOf(g.Value))
+	}
+	if rv.IsValid() {
+		if w, ok := asUnionWrapper(rv.Interface()); ok {
+			return e.encodeUnion(u, reflect.ValueOf(genericUnion(w)))
+		}
+	}
+	i := u.branch(rv)
+	if i < 0 {
+		return errors.New("value does not match any type in the union")
+	}
+	e.writeLong(int64(i))
+	return e.encode(u[i], rv)
+}
+
+// branch returns the index of the first schema in u which the indirected
+// value rv conforms to, or -1 if there is none. A primitive which also
+// accepts rv, but decodes to another kind, is passed over for a later branch
+// of rv's own kind, so a float64 is written as a double and a string as a
+// string.
+func (u Union) branch(rv reflect.Value) int {
+	first := -1
+	for i, s := range u {
+		if !rv.IsValid() {
+			if s == Null {
+				return i
+			}
+			continue
+		}
+		if s == Null || s.Validate(rv.Interface()) != nil {
+			continue
+		}
+		if p, ok := resolve(s).(Primitive); !ok || p.exact(rv) {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	return first
+}
@@ -0,0 +1,97 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestEncode_primitives(t *testing.T) {
+	is := is.New(t)
+
+	tests := []struct {
+		schema Schema
+		value  interface{}
+		want   []byte
+	}{
+		{Null, nil, []byte{}},
+		{Boolean, true, []byte{1}},
+		{Int, 0, []byte{0}},
+		{Int, -1, []byte{1}},
+		{Int, 1, []byte{2}},
+		{Int, -64, []byte{0x7f}},
+		{Int, 64, []byte{0x80, 0x01}},
+		{Long, int64(-65), []byte{0x81, 0x01}},
+		{Float, float32(1), []byte{0, 0, 0x80, 0x3f}},
+		{Double, float64(1), []byte{0, 0, 0, 0, 0, 0, 0xf0, 0x3f}},
+		{Bytes, []byte{1, 2}, []byte{4, 1, 2}},
+		{String, "foo", []byte{6, 'f', 'o', 'o'}},
+	}
+	for _, tt := range tests {
+		got, err := Encode(tt.schema, tt.value)
+		is.NoErr(err)                          // encodes without error
+		is.Equal(string(got), string(tt.want)) // encoding matches the spec
+	}
+
+	_, err := Encode(Int, int64(1)<<32)
+	is.True(err != nil) // int overflow is an error
+	_, err = Encode(String, 1)
+	is.True(err != nil) // mismatched type is an error
+	_, err = Encode(String, nil)
+	is.True(err != nil) // nil is not a string
+	_, err = Encode(nil, nil)
+	is.True(err != nil) // nil schema is an error
+}
+
+func TestEncode_complex(t *testing.T) {
+	is := is.New(t)
+
+	type pet struct {
+		Name string   `avro:"name"`
+		Kind string   `avro:"kind"`
+		Chip *[2]byte `avro:"chip"`
+	}
+	b, err := Encode(genericTestRecord, pet{Name: "a", Kind: "DOG"})
+	is.NoErr(err)                     // encodes struct
+	is.Equal(b, []byte{2, 'a', 2, 0}) // fields in record order
+	b, err = Encode(genericTestRecord, &pet{Name: "a", Kind: "CAT", Chip: &[2]byte{7, 8}})
+	is.NoErr(err)                           // encodes pointer to struct
+	is.Equal(b, []byte{2, 'a', 0, 2, 7, 8}) // union selects fixed
+
+	msi := map[string]interface{}{"name": "a", "kind": "DOG", "chip": nil}
+	b, err = Encode(genericTestRecord, msi)
+	is.NoErr(err)                     // encodes map
+	is.Equal(b, []byte{2, 'a', 2, 0}) // same as struct
+
+	delete(msi, "chip")
+	_, err = Encode(genericTestRecord, msi)
+	is.True(err != nil) // missing field is an error
+
+	_, err = Encode(genericTestRecord, pet{Name: "a", Kind: "FISH"})
+	is.True(err != nil) // unknown symbol is an error
+
+	b, err = Encode(Array{Items: Int}, []int{1, 2})
+	is.NoErr(err)                   // encodes array
+	is.Equal(b, []byte{4, 2, 4, 0}) // one block then end
+	b, err = Encode(Map{Values: Int}, map[string]int{"b": 2, "a": 1})
+	is.NoErr(err)                                   // encodes map
+	is.Equal(b, []byte{4, 2, 'a', 2, 2, 'b', 4, 0}) // keys are sorted
+
+	b, err = Encode(Union{Null, Long, Int}, GenericUnion{Index: 2, Value: 1})
+	is.NoErr(err)             // encodes explicit branch
+	is.Equal(b, []byte{4, 2}) // uses selected branch
+	_, err = Encode(Union{Null, Long}, "x")
+	is.True(err != nil) // no matching branch
+	b, err = Encode(Union{Float, Double}, float64(1))
+	is.NoErr(err)
+	is.Equal(b[0], byte(2)) // float64 prefers double
+	b, err = Encode(Union{Float, Double}, float32(1))
+	is.NoErr(err)
+	is.Equal(b[0], byte(0)) // float32 is a float
+	b, err = Encode(Union{Bytes, String}, "x")
+	is.NoErr(err)
+	is.Equal(b[0], byte(2)) // string prefers string
+	b, err = Encode(Union{Null, Float}, float64(1))
+	is.NoErr(err)
+	is.Equal(b[0], byte(2)) // falls back to a branch which accepts it
+}
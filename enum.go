@@ -12,6 +12,7 @@ type Enum struct {
 	NameFields
 	Doc     string   `json:"doc,omitempty"`
 	Symbols []string `json:"symbols"`
+	Default string   `json:"default,omitempty"` // symbol read in place of unknown symbols
 }
 
 func (e Enum) Type() string { return "enum" }
@@ -19,11 +20,11 @@ func (e Enum) Type() string { return "enum" }
 func (e Enum) Valid() error {
 	errs := map[string]error{}
 	if err := e.NameFields.Valid(); err != nil {
-		errs["name"] = err
+		errs[".name"] = err
 	}
 	symMap := map[string]struct{}{}
-	for _, sym := range e.Symbols {
-		errKey := fmt.Sprintf(`symbol "%s"`, sym)
+	for i, sym := range e.Symbols {
+		errKey := ".symbols" + indexPath(i)
 		if !nameRegex.MatchString(sym) {
 			errs[errKey] = errors.New(`invalid symbol name`)
 		}
@@ -32,6 +33,9 @@ func (e Enum) Valid() error {
 		}
 		symMap[sym] = struct{}{}
 	}
+	if _, ok := symMap[e.Default]; e.Default != "" && !ok {
+		errs[".default"] = fmt.Errorf(`symbol "%s" does not exist in the enum`, e.Default)
+	}
 	if len(errs) > 0 {
 		return ErrValidation{
 			Children: errs,
@@ -47,26 +51,51 @@ func (e Enum) Validate(v interface{}) error {
 	if err := e.Valid(); err != nil {
 		return fmt.Errorf(`validation aborted, enum schema is invalid: %s`, err)
 	}
+	return e.validate(v)
+}
 
-	// Static check for strings.
+// validate checks a non-nil value against an Enum which is known to be valid.
+func (e Enum) validate(v interface{}) error {
+	// Static check for strings and generic enums.
 	switch s := v.(type) {
 	case *string:
 		return e.exists(*s)
 	case string:
 		return e.exists(s)
+	case GenericEnum:
+		return e.checkGeneric(s)
+	case *GenericEnum:
+		return e.checkGeneric(*s)
 	}
 
-	// Reflect for custom types with string concrete type.
+	// Reflect for custom types with string concrete type, or defined integer
+	// types holding the ordinal.
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Ptr {
 		rv = rv.Elem()
 	}
+	if n, ok := ordinalValue(rv); ok {
+		if n < 0 || n >= int64(len(e.Symbols)) {
+			return fmt.Errorf(`enum has no symbol at ordinal %d`, n)
+		}
+		return nil
+	}
 	if k := rv.Kind(); k != reflect.String {
 		return fmt.Errorf(`value of type "%s" is not a valid enum`, k)
 	}
 	return e.exists(rv.String())
 }
 
+// ordinalValue returns the value of a defined integer type, such as an enum
+// type generated by cmd/avrogen, which holds the ordinal of a symbol. Plain
+// integers are not enum values.
+func ordinalValue(rv reflect.Value) (int64, bool) {
+	if !rv.IsValid() || rv.Type().PkgPath() == "" {
+		return 0, false
+	}
+	return intValue(rv)
+}
+
 func (e Enum) exists(s string) error {
 	for _, sym := range e.Symbols {
 		if s == sym {
@@ -76,11 +105,19 @@ func (e Enum) exists(s string) error {
 	return fmt.Errorf(`symbol "%s" does not exist in the enum`, s)
 }
 
+func (e Enum) checkGeneric(g GenericEnum) error {
+	if g.Ordinal < 0 || g.Ordinal >= len(e.Symbols) || e.Symbols[g.Ordinal] != g.Symbol {
+		return fmt.Errorf(`symbol "%s" does not exist at ordinal %d in the enum`, g.Symbol, g.Ordinal)
+	}
+	return nil
+}
+
 type jsonEnum struct {
 	Type string `json:"type"`
 	NameFields
 	Doc     string   `json:"doc,omitempty"`
 	Symbols []string `json:"symbols"`
+	Default string   `json:"default,omitempty"`
 }
 
 // UnmarshalJSON is implemented to check the "type" field.
@@ -96,6 +133,7 @@ func (e *Enum) UnmarshalJSON(data []byte) error {
 	e.NameFields = raw.NameFields
 	e.Doc = raw.Doc
 	e.Symbols = raw.Symbols
+	e.Default = raw.Default
 	return nil
 }
 
@@ -109,6 +147,7 @@ func (e Enum) MarshalJSON() ([]byte, error) {
 		NameFields: e.NameFields,
 		Doc:        e.Doc,
 		Symbols:    e.Symbols,
+		Default:    e.Default,
 	}
 	return json.Marshal(&raw)
 }
@@ -34,6 +34,11 @@ func TestEnum_Valid(t *testing.T) {
 
 	e.Symbols = []string{"one", invalidName}
 	is.True(e.Valid() != nil) // invalid symbol name should be invalid
+
+	e = Enum{NameFields: NameFields{Name: "E"}, Symbols: []string{"A"}, Default: "B"}
+	is.True(e.Valid() != nil) // default must be a symbol
+	e.Default = "A"
+	is.NoErr(e.Valid())
 }
 
 func TestEnum_Validate(t *testing.T) {
@@ -62,6 +67,27 @@ func TestEnum_Validate(t *testing.T) {
 	cv := custom("x")
 	is.NoErr(e.Validate(cv))  // should validate when concrete type is string
 	is.NoErr(e.Validate(&cv)) // should validate when concrete type is string
+
+	type ordinal int32
+	is.NoErr(e.Validate(ordinal(1)))       // should validate defined integer type as ordinal
+	is.True(e.Validate(ordinal(2)) != nil) // should reject ordinal out of range
+}
+
+func TestEnum_ordinal(t *testing.T) {
+	is := is.New(t)
+
+	type suit int32
+	e := Enum{NameFields: NameFields{Name: "Suit"}, Symbols: []string{"SPADES", "HEARTS"}}
+	b, err := Encode(e, suit(1))
+	is.NoErr(err)
+	is.Equal(b, []byte{2}) // encodes the ordinal
+	var got suit
+	is.NoErr(Decode(e, b, &got))
+	is.Equal(got, suit(1)) // decodes the ordinal
+	_, err = Encode(e, suit(2))
+	is.True(err != nil) // ordinal out of range
+	_, err = Encode(e, 1)
+	is.True(err != nil) // plain integers are not enum values
 }
 
 func TestEnum_UnmarshalJSON(t *testing.T) {
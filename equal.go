@@ -0,0 +1,181 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// Equal reports whether a and b are equal values of schema s. Fields with
+// order "ignore" are not compared, maps are compared regardless of order and
+// unions are equal if they hold equal values of the same branch. Floats are
+// compared by bit pattern, so that Equal agrees with Hash. The values are
+// encoded with Encode, so they may have different Go types.
+func Equal(s Schema, a, b interface{}) (bool, error) {
+	ac, err := canonicalData(s, a)
+	if err != nil {
+		return false, fmt.Errorf(`first value: %s`, err)
+	}
+	bc, err := canonicalData(s, b)
+	if err != nil {
+		return false, fmt.Errorf(`second value: %s`, err)
+	}
+	return bytes.Equal(ac, bc), nil
+}
+
+// Hash returns a 64-bit FNV-1a hash of v according to schema s. Values which
+// are Equal have the same hash, whatever their Go types.
+func Hash(s Schema, v interface{}) (uint64, error) {
+	c, err := canonicalData(s, v)
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	h.Write(c)
+	return h.Sum64(), nil
+}
+
+// canonicalData returns an encoding of v which is the same for all values
+// that are Equal.
+func canonicalData(s Schema, v interface{}) ([]byte, error) {
+	if s == nil {
+		return nil, errors.New(`cannot compare with nil schema`)
+	}
+	b, err := Encode(s, v)
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	if err := canonicalize(s, &decoder{buf: b}, &e); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// canonicalize reads a value from d and appends it to e without fields of
+// order "ignore", with integers in their shortest form, map entries sorted by
+// key and arrays and maps in a single block.
+func canonicalize(s Schema, d *decoder, e *encoder) error {
+	switch s := resolve(s).(type) {
+	case Primitive:
+		switch s {
+		case Int, Long:
+			n, err := d.readLong()
+			if err != nil {
+				return err
+			}
+			e.writeLong(n)
+			return nil
+		case Bytes, String:
+			b, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			e.writeBytes(b)
+			return nil
+		}
+		return copyValue(s, d, e)
+	case Record:
+		for _, f := range s.Fields {
+			var err error
+			if f.Order == "ignore" {
+				err = d.skip(f.Type)
+			} else {
+				err = canonicalize(f.Type, d, e)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case Enum:
+		n, err := d.readLong()
+		if err != nil {
+			return err
+		}
+		e.writeLong(n)
+		return nil
+	case Fixed:
+		return copyValue(s, d, e)
+	case Array:
+		var items encoder
+		n := 0
+		bi := blockItems{d: d, items: s.Items}
+		for {
+			ok, err := bi.next()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+			if err := canonicalize(s.Items, d, &items); err != nil {
+				return err
+			}
+			n++
+		}
+		if n > 0 {
+			e.writeLong(int64(n))
+			e.buf = append(e.buf, items.buf...)
+		}
+		e.writeLong(0)
+		return nil
+	case Map:
+		entries := map[string][]byte{}
+		bi := blockItems{d: d}
+		for {
+			ok, err := bi.next()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+			k, err := d.readBytes()
+			if err != nil {
+				return err
+			}
+			var value encoder
+			if err := canonicalize(s.Values, d, &value); err != nil {
+				return err
+			}
+			entries[string(k)] = value.buf
+		}
+		if len(entries) > 0 {
+			keys := make([]string, 0, len(entries))
+			for k := range entries {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			e.writeLong(int64(len(keys)))
+			for _, k := range keys {
+				e.writeString(k)
+				e.buf = append(e.buf, entries[k]...)
+			}
+		}
+		e.writeLong(0)
+		return nil
+	case Union:
+		i, err := d.readUnionIndex(s)
+		if err != nil {
+			return err
+		}
+		e.writeLong(int64(i))
+		return canonicalize(s[i], d, e)
+	case Logical:
+		return canonicalize(s.Schema, d, e)
+	}
+	return fmt.Errorf(`cannot compare schema type "%s"`, s.Type())
+}
+
+// copyValue appends the encoding of the next value in d to e unchanged.
+func copyValue(s Schema, d *decoder, e *encoder) error {
+	start := d.pos
+	if err := d.skip(s); err != nil {
+		return err
+	}
+	e.buf = append(e.buf, d.buf[start:d.pos]...)
+	return nil
+}
@@ -0,0 +1,86 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+var equalTestRecord = Record{
+	NameFields: NameFields{Name: "Event"},
+	Fields: []Field{
+		{Name: "id", Type: Long},
+		{Name: "received", Type: Long, Order: "ignore"},
+		{Name: "attrs", Type: Map{Values: String}},
+		{Name: "source", Type: Union{Null, String}},
+	},
+}
+
+type equalTestEvent struct {
+	ID       int64             `avro:"id"`
+	Received int64             `avro:"received"`
+	Attrs    map[string]string `avro:"attrs"`
+	Source   *string           `avro:"source"`
+}
+
+func TestEqual(t *testing.T) {
+	is := is.New(t)
+
+	src := "web"
+	a := equalTestEvent{ID: 1, Received: 10, Attrs: map[string]string{"a": "1", "b": "2"}, Source: &src}
+	b := map[string]interface{}{
+		"id":       int64(1),
+		"received": int64(20),
+		"attrs":    map[string]interface{}{"b": "2", "a": "1"},
+		"source":   "web",
+	}
+	eq, err := Equal(equalTestRecord, a, b)
+	is.NoErr(err) // compares struct and map
+	is.True(eq)   // ignored field and map order do not matter
+
+	g := NewGenericRecord(equalTestRecord)
+	g.SetIndex(0, int64(1))
+	g.SetIndex(1, int64(30))
+	g.SetIndex(2, map[string]interface{}{"a": "1", "b": "2"})
+	g.SetIndex(3, GenericUnion{Index: 1, Value: "web"})
+	eq, err = Equal(equalTestRecord, a, g)
+	is.NoErr(err) // compares struct and generic record
+	is.True(eq)   // generic record is equal
+
+	ha, err := Hash(equalTestRecord, a)
+	is.NoErr(err) // hashes struct
+	hb, err := Hash(equalTestRecord, b)
+	is.NoErr(err) // hashes map
+	hg, err := Hash(equalTestRecord, g)
+	is.NoErr(err)    // hashes generic record
+	is.Equal(ha, hb) // equal values have equal hashes
+	is.Equal(ha, hg) // equal values have equal hashes
+
+	a.Source = nil
+	eq, err = Equal(equalTestRecord, a, b)
+	is.NoErr(err) // compares
+	is.True(!eq)  // different union branch is not equal
+	hn, err := Hash(equalTestRecord, a)
+	is.NoErr(err)     // hashes
+	is.True(hn != hb) // different values hash differently
+
+	a.Source = &src
+	a.Attrs["c"] = "3"
+	eq, err = Equal(equalTestRecord, a, b)
+	is.NoErr(err) // compares
+	is.True(!eq)  // extra map entry is not equal
+
+	_, err = Equal(equalTestRecord, a, 1)
+	is.True(err != nil) // invalid value is an error
+}
+
+func TestCanonicalize_blocks(t *testing.T) {
+	is := is.New(t)
+
+	a := Array{Items: Int}
+	// [1, 2] as two blocks with byte sizes and a non-minimal varint.
+	blocked := []byte{1, 2, 2, 1, 3, 0x84, 0x00, 0}
+	var e encoder
+	is.NoErr(canonicalize(a, &decoder{buf: blocked}, &e)) // canonicalizes blocks
+	is.Equal(e.buf, []byte{4, 2, 4, 0})                   // single block, minimal varints
+}
@@ -3,6 +3,8 @@ package avro
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -50,6 +52,11 @@ func (e ErrInvalidValue) Error() string {
 	return fmt.Sprintf(`"%s" is an invalid value for field "%s"`, e.Actual, e.Field)
 }
 
+// ErrValidation is returned by Valid and Validate. It may hold an error of its
+// own, and child errors keyed by the path segment of the offending part of the
+// value or schema: ".name" for record fields, "[3]" for array items, `["key"]`
+// for map values and "{type}" for union branches. Children may themselves be
+// ErrValidation, forming a tree which Flatten turns into a list of paths.
 type ErrValidation struct {
 	error
 	Children map[string]error
@@ -65,14 +72,134 @@ func (e ErrValidation) errIndent(idt int) string {
 	if e.error != nil {
 		errs = append(errs, pad+e.error.Error())
 	}
-	for key, err := range e.Children {
-		var errStr string
-		if err, ok := err.(ErrValidation); ok {
-			errStr = err.errIndent(idt + 1)
-		} else {
-			errStr = err.Error()
+	for _, key := range e.keys() {
+		if err, ok := e.Children[key].(ErrValidation); ok {
+			errs = append(errs, fmt.Sprintf("%s%s:\n%s", pad, key, err.errIndent(idt+1)))
+			continue
 		}
-		errs = append(errs, fmt.Sprintf("%s%s: %s", pad, key, errStr))
+		errs = append(errs, fmt.Sprintf("%s%s: %s", pad, key, e.Children[key]))
 	}
 	return strings.Join(errs, "\n")
 }
+
+// keys returns the keys of Children in a deterministic order. Keys are
+// compared lexically, except that runs of digits such as array indexes are
+// compared numerically.
+func (e ErrValidation) keys() []string {
+	keys := make([]string, 0, len(e.Children))
+	for key := range e.Children {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return pathLess(keys[i], keys[j])
+	})
+	return keys
+}
+
+// Flatten returns every error in the tree together with its full path, such
+// as `$.orders[3].price`. Errors are ordered by path, and an error held by an
+// ErrValidation itself comes before the errors of its children.
+func (e ErrValidation) Flatten() []PathError {
+	var errs []PathError
+	e.flatten("$", &errs)
+	return errs
+}
+
+func (e ErrValidation) flatten(path string, errs *[]PathError) {
+	if e.error != nil {
+		*errs = append(*errs, PathError{Path: path, Err: e.error})
+	}
+	for _, key := range e.keys() {
+		child := e.Children[key]
+		if cv, ok := child.(ErrValidation); ok {
+			cv.flatten(path+key, errs)
+			continue
+		}
+		*errs = append(*errs, PathError{Path: path + key, Err: child})
+	}
+}
+
+// Unwrap returns the flattened errors, so errors.Is and errors.As can match
+// any error in the tree. Matching PathError gives the first error by path.
+func (e ErrValidation) Unwrap() []error {
+	flat := e.Flatten()
+	errs := make([]error, len(flat))
+	for i, err := range flat {
+		errs[i] = err
+	}
+	return errs
+}
+
+// PathError is a single error from an ErrValidation tree along with the path
+// to the value or schema it refers to.
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e PathError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e PathError) Unwrap() error {
+	return e.Err
+}
+
+// fieldPath returns the path segment of a record field. Names which are not
+// valid Avro names are quoted.
+func fieldPath(name string) string {
+	if nameRegex.MatchString(name) {
+		return "." + name
+	}
+	return keyPath(name)
+}
+
+// keyPath returns the path segment of a map value.
+func keyPath(key string) string {
+	return "[" + strconv.Quote(key) + "]"
+}
+
+// indexPath returns the path segment of an array item.
+func indexPath(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+// unionPath returns the path segment of a union branch.
+func unionPath(s Schema) string {
+	return "{" + unionName(s) + "}"
+}
+
+func pathLess(a, b string) bool {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			na, nb := digitsLen(a), digitsLen(b)
+			da, db := strings.TrimLeft(a[:na], "0"), strings.TrimLeft(b[:nb], "0")
+			if len(da) != len(db) {
+				return len(da) < len(db)
+			}
+			if da != db {
+				return da < db
+			}
+			a, b = a[na:], b[nb:]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func digitsLen(s string) int {
+	n := 0
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	return n
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,85 @@
+package avro
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestErrValidation_Flatten(t *testing.T) {
+	is := is.New(t)
+
+	r := Record{
+		NameFields: NameFields{Name: "Order"},
+		Fields: []Field{
+			{Name: "items", Type: Array{Items: Record{
+				NameFields: NameFields{Name: "Item"},
+				Fields: []Field{
+					{Name: "price", Type: mockValidNamedSchema},
+				},
+			}}},
+			{Name: "tags", Type: Map{Values: mockValidNamedSchema}},
+		},
+	}
+	items := make([]map[string]interface{}, 11)
+	for i := range items {
+		items[i] = map[string]interface{}{"price": mockValue}
+	}
+	items[3]["price"] = 0
+	items[10]["price"] = 0
+	err := r.Validate(map[string]interface{}{
+		"items": items,
+		"tags":  map[string]interface{}{"env": 0},
+	})
+
+	var ev ErrValidation
+	is.True(errors.As(err, &ev)) // error is an ErrValidation
+	flat := ev.Flatten()
+	is.Equal(len(flat), 3)                      // one entry per invalid value
+	is.Equal(flat[0].Path, "$.items[3].price")  // path to array item field
+	is.Equal(flat[1].Path, "$.items[10].price") // indexes sort numerically
+	is.Equal(flat[2].Path, `$.tags["env"]`)     // path to map value
+	is.Equal(flat[0].Err, errMockInvalid)       // leaf error is kept
+
+	var pe PathError
+	is.True(errors.As(err, &pe))            // PathError is reachable with errors.As
+	is.Equal(pe.Path, "$.items[3].price")   // first PathError by path
+	is.True(errors.Is(err, errMockInvalid)) // leaf errors are reachable with errors.Is
+}
+
+func TestErrValidation_Error(t *testing.T) {
+	is := is.New(t)
+
+	err := ErrValidation{
+		error: errors.New("top"),
+		Children: map[string]error{
+			"[10]": errors.New("ten"),
+			"[2]":  errors.New("two"),
+			".a": ErrValidation{
+				Children: map[string]error{".b": errors.New("b")},
+			},
+		},
+	}
+	want := "top\n.a:\n  .b: b\n[2]: two\n[10]: ten"
+	for i := 0; i < 10; i++ {
+		is.Equal(err.Error(), want) // output is deterministic
+	}
+}
+
+func TestRecord_Valid_paths(t *testing.T) {
+	is := is.New(t)
+
+	r := Record{
+		NameFields: NameFields{Name: "Test"},
+		Fields: []Field{
+			{Name: "ok", Type: Int},
+			{Name: "bad", Type: Int, Order: "__WRONG__"},
+		},
+	}
+	var ev ErrValidation
+	is.True(errors.As(r.Valid(), &ev)) // invalid record returns ErrValidation
+	flat := ev.Flatten()
+	is.Equal(len(flat), 1)                      // only the bad field is reported
+	is.Equal(flat[0].Path, "$.fields[1].order") // path to the bad attribute
+}
@@ -32,7 +32,11 @@ func (f Fixed) Validate(v interface{}) error {
 	if err := f.Valid(); err != nil {
 		return fmt.Errorf(`validation aborted, fixed schema is invalid: %s`, err)
 	}
+	return f.validate(v)
+}
 
+// validate checks a non-nil value against a Fixed which is known to be valid.
+func (f Fixed) validate(v interface{}) error {
 	// Type switch for primitive types.
 	switch s := v.(type) {
 	case []byte:
@@ -43,6 +47,8 @@ func (f Fixed) Validate(v interface{}) error {
 		return f.checkBytes(*s)
 	case *string:
 		return f.checkBytes([]byte(*s))
+	case GenericFixed:
+		return f.checkBytes(s)
 	}
 
 	// Check for concrete types with reflect.
@@ -55,6 +61,13 @@ func (f Fixed) Validate(v interface{}) error {
 		if rv.Type().Elem().Kind() == reflect.Uint8 {
 			return f.checkBytes(rv.Bytes())
 		}
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			if rv.Len() != int(f.Size) {
+				return fmt.Errorf(`value has %d bytes, but should have %d`, rv.Len(), f.Size)
+			}
+			return nil
+		}
 	case reflect.String:
 		return f.checkBytes([]byte(rv.String()))
 	}
@@ -60,6 +60,10 @@ func TestFixedValidate(t *testing.T) {
 	is.NoErr(f.Validate(&customBytesVal)) // only concrete type should matter
 	is.NoErr(f.Validate(customStrVal))    // only concrete type should matter
 	is.NoErr(f.Validate(&customStrVal))   // only concrete type should matter
+
+	is.NoErr(f.Validate([2]byte{}))                // byte array with length == size is valid
+	is.True(f.Validate([3]byte{}) != nil)          // byte array with length != size is invalid
+	is.NoErr(f.Validate(GenericFixed{0xFF, 0xFF})) // generic fixed is valid
 }
 
 func TestFixed_UnmarshalJSON(t *testing.T) {
@@ -0,0 +1,172 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	genericRecordType = reflect.TypeOf(GenericRecord{})
+	genericEnumType   = reflect.TypeOf(GenericEnum{})
+	genericFixedType  = reflect.TypeOf(GenericFixed{})
+	genericUnionType  = reflect.TypeOf(GenericUnion{})
+	unionWrapperType  = reflect.TypeOf((*UnionWrapper)(nil)).Elem()
+)
+
+// GenericRecord holds the values of a record in field order. It is used by
+// Decode for records decoded into an interface{}, and can be passed to
+// Validate and Encode in place of a struct or map.
+type GenericRecord struct {
+	schema Record
+	values []interface{}
+}
+
+// NewGenericRecord returns a GenericRecord for schema with every field unset.
+func NewGenericRecord(schema Record) *GenericRecord {
+	return &GenericRecord{
+		schema: schema,
+		values: make([]interface{}, len(schema.Fields)),
+	}
+}
+
+// Schema returns the Record schema of r.
+func (r *GenericRecord) Schema() Record {
+	return r.schema
+}
+
+// Len returns the number of fields in r.
+func (r *GenericRecord) Len() int {
+	return len(r.values)
+}
+
+// Get returns the value of the field called name. The bool is false if the
+// record does not have such a field.
+func (r *GenericRecord) Get(name string) (interface{}, bool) {
+	i := r.fieldIndex(name)
+	if i < 0 {
+		return nil, false
+	}
+	return r.values[i], true
+}
+
+// Set sets the value of the field called name.
+func (r *GenericRecord) Set(name string, v interface{}) error {
+	i := r.fieldIndex(name)
+	if i < 0 {
+		return fmt.Errorf(`record "%s" does not have a field "%s"`, r.schema.Fullname(), name)
+	}
+	r.values[i] = v
+	return nil
+}
+
+// GetIndex returns the value of the i-th field. It panics if i is out of range.
+func (r *GenericRecord) GetIndex(i int) interface{} {
+	return r.values[i]
+}
+
+// SetIndex sets the value of the i-th field. It panics if i is out of range.
+func (r *GenericRecord) SetIndex(i int, v interface{}) {
+	r.values[i] = v
+}
+
+func (r *GenericRecord) fieldIndex(name string) int {
+	return r.schema.fieldIndex(name, true)
+}
+
+// validate checks r against schema, which is known to be valid. Field
+// values are checked with validate, so a compiled Validator can pass its own.
+func (r *GenericRecord) validate(schema Record, validate func(i int, v interface{}) error) error {
+	if r == nil {
+		return errors.New(`nil is not a valid record`)
+	}
+	if r.schema.Fullname() != schema.Fullname() {
+		return fmt.Errorf(`record "%s" is not a valid "%s"`, r.schema.Fullname(), schema.Fullname())
+	}
+	if len(r.values) != len(schema.Fields) {
+		return fmt.Errorf(`record has %d fields, but should have %d`, len(r.values), len(schema.Fields))
+	}
+	errs := map[string]error{}
+	for i, f := range schema.Fields {
+		if err := validate(i, r.values[i]); err != nil {
+			errs[fieldPath(f.Name)] = err
+		}
+	}
+	return childErrors(errs)
+}
+
+// GenericEnum is an enum symbol together with its position in the enum's
+// symbols. It is used by Decode for enums decoded into an interface{}.
+type GenericEnum struct {
+	Symbol  string
+	Ordinal int
+}
+
+// NewGenericEnum returns the GenericEnum for symbol, which must exist in schema.
+func NewGenericEnum(schema Enum, symbol string) (GenericEnum, error) {
+	for i, sym := range schema.Symbols {
+		if sym == symbol {
+			return GenericEnum{Symbol: symbol, Ordinal: i}, nil
+		}
+	}
+	return GenericEnum{}, fmt.Errorf(`symbol "%s" does not exist in the enum`, symbol)
+}
+
+// GenericFixed is the value of a fixed. It is used by Decode for fixed values
+// decoded into an interface{}.
+type GenericFixed []byte
+
+// GenericUnion is a union value which records the selected branch. It is used
+// by Decode for unions decoded into an interface{}, and can be passed to
+// Validate and Encode to select a branch explicitly.
+type GenericUnion struct {
+	Index int // position of the selected schema in the union
+	Value interface{}
+}
+
+// validate checks g against the union u, which is known to be valid. The value
+// is checked with validate, so a compiled Validator can pass its own.
+func (g GenericUnion) validate(u Union, validate func(i int, v interface{}) error) error {
+	if g.Index < 0 || g.Index >= len(u) {
+		return fmt.Errorf(`union has no schema at index %d`, g.Index)
+	}
+	if err := validate(g.Index, g.Value); err != nil {
+		return ErrValidation{
+			Children: map[string]error{unionPath(u[g.Index]): err},
+		}
+	}
+	return nil
+}
+
+// UnionWrapper is implemented by pointers to Go types which hold a union value
+// together with the index of its schema, such as the union types generated by
+// cmd/avrogen. Validate, Encode and Decode use the index rather than matching
+// the value against each schema of the union.
+type UnionWrapper interface {
+	// UnionIndex returns the position of the selected schema in the union.
+	UnionIndex() int
+	// UnionValue returns the value held, which is nil for null.
+	UnionValue() interface{}
+	// SetUnionIndex selects the schema at position i and returns a pointer
+	// to the value to decode into, which is nil for null.
+	SetUnionIndex(i int) interface{}
+}
+
+// asUnionWrapper returns v as a UnionWrapper if v, or a pointer to it, is one.
+func asUnionWrapper(v interface{}) (UnionWrapper, bool) {
+	if w, ok := v.(UnionWrapper); ok {
+		return w, true
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || !reflect.PtrTo(rv.Type()).Implements(unionWrapperType) {
+		return nil, false
+	}
+	p := reflect.New(rv.Type())
+	p.Elem().Set(rv)
+	return p.Interface().(UnionWrapper), true
+}
+
+// genericUnion returns the GenericUnion with the same selection as w.
+func genericUnion(w UnionWrapper) GenericUnion {
+	return GenericUnion{Index: w.UnionIndex(), Value: w.UnionValue()}
+}
@@ -0,0 +1,144 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+var genericTestRecord = Record{
+	NameFields: NameFields{Name: "Pet", Namespace: "test"},
+	Fields: []Field{
+		{Name: "name", Type: String},
+		{Name: "kind", Type: Enum{
+			NameFields: NameFields{Name: "Kind"},
+			Symbols:    []string{"CAT", "DOG"},
+		}},
+		{Name: "chip", Type: Union{Null, Fixed{NameFields: NameFields{Name: "Chip"}, Size: 2}}},
+	},
+}
+
+func TestGenericRecord(t *testing.T) {
+	is := is.New(t)
+
+	r := NewGenericRecord(genericTestRecord)
+	is.Equal(r.Len(), 3)                      // has a value per field
+	is.Equal(r.Schema(), genericTestRecord)   // keeps the schema
+	is.NoErr(r.Set("name", "Rex"))            // set by name
+	is.True(r.Set("__WRONG__", "Rex") != nil) // unknown field cannot be set
+	r.SetIndex(1, GenericEnum{Symbol: "DOG", Ordinal: 1})
+
+	v, ok := r.Get("name")
+	is.True(ok)                                                     // get existing field
+	is.Equal(v, "Rex")                                              // get by name
+	_, ok = r.Get("nope")                                           // get unknown field
+	is.True(!ok)                                                    // unknown field does not exist
+	is.Equal(r.GetIndex(1), GenericEnum{Symbol: "DOG", Ordinal: 1}) // get by index
+
+	is.NoErr(genericTestRecord.Validate(r))  // valid generic record
+	is.NoErr(genericTestRecord.Validate(*r)) // valid generic record value
+
+	r.SetIndex(2, GenericUnion{Index: 1, Value: GenericFixed{1, 2}})
+	is.NoErr(genericTestRecord.Validate(r)) // valid generic union and fixed
+
+	r.SetIndex(2, GenericUnion{Index: 0, Value: GenericFixed{1, 2}})
+	is.True(genericTestRecord.Validate(r) != nil) // value does not match selected branch
+
+	r.SetIndex(1, GenericEnum{Symbol: "DOG", Ordinal: 0})
+	is.True(genericTestRecord.Validate(r) != nil) // ordinal does not match symbol
+
+	other := genericTestRecord
+	other.Name = "Other"
+	is.True(other.Validate(NewGenericRecord(genericTestRecord)) != nil) // record name must match
+
+	v8r, err := Compile(genericTestRecord)
+	is.NoErr(err)                   // compiles
+	is.True(v8r.Validate(r) != nil) // compiled validator checks generic values
+}
+
+func TestNewGenericEnum(t *testing.T) {
+	is := is.New(t)
+
+	e := genericTestRecord.Fields[1].Type.(Enum)
+	g, err := NewGenericEnum(e, "DOG")
+	is.NoErr(err)                                       // existing symbol
+	is.Equal(g, GenericEnum{Symbol: "DOG", Ordinal: 1}) // ordinal is found
+	is.NoErr(e.Validate(g))                             // generic enum is valid
+
+	_, err = NewGenericEnum(e, "FISH")
+	is.True(err != nil) // unknown symbol
+}
+
+func TestUnion_Validate_generic(t *testing.T) {
+	is := is.New(t)
+
+	u := Union{Null, String}
+	is.NoErr(u.Validate(GenericUnion{Index: 1, Value: "x"}))       // value matches branch
+	is.True(u.Validate(GenericUnion{Index: 0, Value: "x"}) != nil) // value does not match branch
+	is.True(u.Validate(GenericUnion{Index: 2}) != nil)             // branch out of range
+}
+
+// testWrapper is a union wrapper for ["null", "string", "long"], like those
+// generated by avrogen.
+type testWrapper struct {
+	Index  int
+	String string
+	Long   int64
+}
+
+func (u testWrapper) UnionIndex() int { return u.Index }
+
+func (u testWrapper) UnionValue() interface{} {
+	switch u.Index {
+	case 1:
+		return u.String
+	case 2:
+		return u.Long
+	}
+	return nil
+}
+
+func (u *testWrapper) SetUnionIndex(i int) interface{} {
+	u.Index = i
+	switch i {
+	case 1:
+		return &u.String
+	case 2:
+		return &u.Long
+	}
+	return nil
+}
+
+func TestUnionWrapper(t *testing.T) {
+	is := is.New(t)
+
+	u := Union{Null, String, Long}
+	is.NoErr(u.Validate(testWrapper{Index: 2, Long: 3}))             // wrapper value
+	is.NoErr(u.Validate(&testWrapper{Index: 0}))                     // wrapper pointer holding null
+	is.True(u.Validate(testWrapper{Index: 3}) != nil)                // index out of range
+	is.True(Union{Null, Int}.Validate(testWrapper{Index: 1}) != nil) // value does not match schema
+
+	for _, w := range []testWrapper{{Index: 0}, {Index: 1, String: "s"}, {Index: 2, Long: 7}} {
+		b, err := Encode(u, w)
+		is.NoErr(err)
+		is.Equal(b[0], byte(2*w.Index)) // encodes selected index
+		var got testWrapper
+		is.NoErr(Decode(u, b, &got))
+		is.Equal(got, w) // round trips
+	}
+
+	type holder struct {
+		W *testWrapper `avro:"w"`
+	}
+	r := Record{NameFields: NameFields{Name: "H"}, Fields: []Field{{Name: "w", Type: u}}}
+	for _, h := range []holder{{}, {W: &testWrapper{Index: 1, String: "s"}}, {W: &testWrapper{Index: 2, Long: 7}}} {
+		b, err := Encode(r, h)
+		is.NoErr(err)
+		var got holder
+		is.NoErr(Decode(r, b, &got))
+		is.Equal(got, h) // pointer to wrapper round trips
+	}
+	var p *testWrapper
+	is.NoErr(Decode(u, []byte{4, 14}, &p))
+	is.Equal(*p, testWrapper{Index: 2, Long: 7}) // decodes into a pointer to a wrapper
+}
@@ -0,0 +1,765 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ParseIDL creates a Protocol from an Avro IDL declaration, as found in .avdl
+// files. Imports are not supported, as there is no file to resolve them
+// against; use ParseIDLFile instead.
+func ParseIDL(src []byte) (Protocol, error) {
+	return parseIDL(src, "")
+}
+
+// ParseIDLFile reads and parses an Avro IDL file. Imported IDL, protocol and
+// schema files are resolved relative to the directory of the importing file.
+func ParseIDLFile(path string) (Protocol, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return Protocol{}, err
+	}
+	return parseIDL(src, path)
+}
+
+func parseIDL(src []byte, path string) (Protocol, error) {
+	p := newSchemaParser()
+	p.errors = true
+	ip := idlParser{src: src, path: path, schemas: p, imported: map[string]bool{}}
+	if path != "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return Protocol{}, err
+		}
+		ip.imported[abs] = true
+	}
+	pr, err := ip.protocol()
+	if err != nil {
+		return Protocol{}, err
+	}
+	if err := p.resolve(); err != nil {
+		return Protocol{}, err
+	}
+	return pr, pr.Valid()
+}
+
+// idlParser parses Avro IDL into the schemas of a schemaParser.
+type idlParser struct {
+	src      []byte
+	pos      int
+	path     string // of the file being parsed, for imports
+	schemas  *schemaParser
+	doc      string          // last doc comment, until taken
+	imported map[string]bool // absolute paths of files already imported
+}
+
+// idlAnnotations are the "@name(value)" annotations of a declaration.
+type idlAnnotations map[string]json.RawMessage
+
+func (ip *idlParser) errorf(format string, args ...interface{}) error {
+	line := 1 + bytes.Count(ip.src[:ip.pos], []byte("\n"))
+	msg := fmt.Sprintf(format, args...)
+	if ip.path != "" {
+		return fmt.Errorf(`%s:%d: %s`, ip.path, line, msg)
+	}
+	return fmt.Errorf(`line %d: %s`, line, msg)
+}
+
+// skip skips white space and comments, keeping the last doc comment.
+func (ip *idlParser) skip() {
+	for ip.pos < len(ip.src) {
+		rest := ip.src[ip.pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r':
+			ip.pos++
+		case bytes.HasPrefix(rest, []byte("//")):
+			end := bytes.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			ip.pos += end
+		case bytes.HasPrefix(rest, []byte("/*")):
+			end := bytes.Index(rest[2:], []byte("*/"))
+			if end < 0 {
+				ip.pos = len(ip.src)
+				return
+			}
+			if bytes.HasPrefix(rest, []byte("/**")) && end > 0 {
+				ip.doc = docComment(string(rest[3 : end+2]))
+			}
+			ip.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+// docComment removes the leading asterisks and white space of a doc comment.
+func docComment(c string) string {
+	lines := strings.Split(c, "\n")
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if i > 0 {
+			l = strings.TrimSpace(strings.TrimPrefix(l, "*"))
+		}
+		lines[i] = l
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// takeDoc returns the last doc comment and forgets it.
+func (ip *idlParser) takeDoc() string {
+	doc := ip.doc
+	ip.doc = ""
+	return doc
+}
+
+func (ip *idlParser) peek() byte {
+	ip.skip()
+	if ip.pos >= len(ip.src) {
+		return 0
+	}
+	return ip.src[ip.pos]
+}
+
+// accept consumes c if it is next.
+func (ip *idlParser) accept(c byte) bool {
+	if ip.peek() == c {
+		ip.pos++
+		return true
+	}
+	return false
+}
+
+func (ip *idlParser) expect(c byte) error {
+	if !ip.accept(c) {
+		return ip.errorf(`expected "%c" but found %s`, c, ip.found())
+	}
+	return nil
+}
+
+// found describes the next token for error messages.
+func (ip *idlParser) found() string {
+	if ip.peek() == 0 {
+		return "end of file"
+	}
+	end := ip.pos + 1
+	for end < len(ip.src) && end < ip.pos+20 && isIdentByte(ip.src[end]) && isIdentByte(ip.src[ip.pos]) {
+		end++
+	}
+	return strconv.Quote(string(ip.src[ip.pos:end]))
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// ident reads an identifier, which may be dotted or quoted with backticks.
+func (ip *idlParser) ident() (string, error) {
+	if ip.accept('`') {
+		end := bytes.IndexByte(ip.src[ip.pos:], '`')
+		if end < 0 {
+			return "", ip.errorf(`unterminated quoted identifier`)
+		}
+		id := string(ip.src[ip.pos : ip.pos+end])
+		ip.pos += end + 1
+		return id, nil
+	}
+	start := ip.pos
+	for ip.pos < len(ip.src) && isIdentByte(ip.src[ip.pos]) && ip.src[ip.pos] != '-' {
+		ip.pos++
+	}
+	if ip.pos == start {
+		return "", ip.errorf(`expected identifier but found %s`, ip.found())
+	}
+	return string(ip.src[start:ip.pos]), nil
+}
+
+// peekIdent returns the next identifier without consuming it, or "" if the
+// next token is not an identifier.
+func (ip *idlParser) peekIdent() string {
+	pos, doc := ip.pos, ip.doc
+	id, err := ip.ident()
+	ip.pos, ip.doc = pos, doc
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// acceptKeyword consumes the keyword kw if it is next.
+func (ip *idlParser) acceptKeyword(kw string) bool {
+	if ip.peek() != '`' && ip.peekIdent() == kw {
+		ip.pos += len(kw)
+		return true
+	}
+	return false
+}
+
+// json reads a JSON value.
+func (ip *idlParser) json() (json.RawMessage, error) {
+	ip.skip()
+	dec := json.NewDecoder(bytes.NewReader(ip.src[ip.pos:]))
+	var v json.RawMessage
+	if err := dec.Decode(&v); err != nil {
+		return nil, ip.errorf(`invalid JSON value: %s`, err)
+	}
+	ip.pos += int(dec.InputOffset())
+	return v, nil
+}
+
+func (ip *idlParser) integer() (int, error) {
+	v, err := ip.json()
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(string(v))
+	if err != nil {
+		return 0, ip.errorf(`expected integer but found %s`, v)
+	}
+	return n, nil
+}
+
+// annotations reads any "@name(value)" annotations.
+func (ip *idlParser) annotations() (idlAnnotations, error) {
+	var as idlAnnotations
+	for ip.accept('@') {
+		// Annotation names may contain "-", as in "java-class".
+		start := ip.pos
+		for ip.pos < len(ip.src) && isIdentByte(ip.src[ip.pos]) {
+			ip.pos++
+		}
+		name := string(ip.src[start:ip.pos])
+		if name == "" {
+			return nil, ip.errorf(`expected annotation name but found %s`, ip.found())
+		}
+		if err := ip.expect('('); err != nil {
+			return nil, err
+		}
+		v, err := ip.json()
+		if err != nil {
+			return nil, err
+		}
+		if err := ip.expect(')'); err != nil {
+			return nil, err
+		}
+		if as == nil {
+			as = idlAnnotations{}
+		}
+		as[name] = v
+	}
+	return as, nil
+}
+
+// stringAnnotation returns the string annotation name, or "" if absent.
+func (ip *idlParser) stringAnnotation(as idlAnnotations, name string) (string, error) {
+	v, ok := as[name]
+	if !ok {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return "", ip.errorf(`annotation "%s" must be a string`, name)
+	}
+	return s, nil
+}
+
+func (ip *idlParser) aliasesAnnotation(as idlAnnotations) ([]string, error) {
+	v, ok := as["aliases"]
+	if !ok {
+		return nil, nil
+	}
+	var aliases []string
+	if err := json.Unmarshal(v, &aliases); err != nil {
+		return nil, ip.errorf(`annotation "aliases" must be an array of strings`)
+	}
+	return aliases, nil
+}
+
+// protocol parses a whole protocol file.
+func (ip *idlParser) protocol() (Protocol, error) {
+	ip.skip()
+	doc := ip.takeDoc()
+	as, err := ip.annotations()
+	if err != nil {
+		return Protocol{}, err
+	}
+	if doc == "" {
+		doc = ip.takeDoc()
+	}
+	if !ip.acceptKeyword("protocol") {
+		return Protocol{}, ip.errorf(`expected "protocol" but found %s`, ip.found())
+	}
+	name, err := ip.ident()
+	if err != nil {
+		return Protocol{}, err
+	}
+	pr := Protocol{Protocol: name, Doc: doc, Messages: map[string]Message{}}
+	if pr.Namespace, err = ip.stringAnnotation(as, "namespace"); err != nil {
+		return Protocol{}, err
+	}
+	if err := ip.expect('{'); err != nil {
+		return Protocol{}, err
+	}
+	for !ip.accept('}') {
+		if ip.peek() == 0 {
+			return Protocol{}, ip.errorf(`expected "}" but found end of file`)
+		}
+		if err := ip.declaration(&pr); err != nil {
+			return Protocol{}, err
+		}
+	}
+	if ip.peek() != 0 {
+		return Protocol{}, ip.errorf(`unexpected %s after protocol`, ip.found())
+	}
+	return pr, nil
+}
+
+// declaration parses an import, a named type or a message.
+func (ip *idlParser) declaration(pr *Protocol) error {
+	ip.skip()
+	doc := ip.takeDoc()
+	as, err := ip.annotations()
+	if err != nil {
+		return err
+	}
+	if doc == "" {
+		doc = ip.takeDoc()
+	}
+	switch ip.peekIdent() {
+	case "import":
+		ip.acceptKeyword("import")
+		return ip.importFile(pr)
+	case "record", "error", "enum", "fixed":
+		s, err := ip.namedType(pr.Namespace, doc, as)
+		if err != nil {
+			return err
+		}
+		pr.Types = append(pr.Types, s)
+		return nil
+	}
+	return ip.message(pr, doc, as)
+}
+
+// importFile parses an import statement and the file it imports.
+func (ip *idlParser) importFile(pr *Protocol) error {
+	kind, err := ip.ident()
+	if err != nil {
+		return err
+	}
+	v, err := ip.json()
+	if err != nil {
+		return err
+	}
+	var file string
+	if err := json.Unmarshal(v, &file); err != nil {
+		return ip.errorf(`import file name must be a string`)
+	}
+	if err := ip.expect(';'); err != nil {
+		return err
+	}
+	if ip.path == "" {
+		return ip.errorf(`cannot import "%s" without a file to resolve it against`, file)
+	}
+	path := filepath.Join(filepath.Dir(ip.path), file)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if ip.imported[abs] {
+		return nil
+	}
+	ip.imported[abs] = true
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return ip.errorf(`import: %s`, err)
+	}
+	var imported Protocol
+	switch kind {
+	case "idl":
+		sub := idlParser{src: src, path: path, schemas: ip.schemas, imported: ip.imported}
+		imported, err = sub.protocol()
+	case "protocol":
+		imported, err = ip.schemas.parseProtocol(src)
+	case "schema":
+		var s Schema
+		if s, err = ip.schemas.parse(src, ""); err == nil {
+			if _, ok := s.(NamedSchema); ok {
+				imported.Types = []Schema{s}
+			}
+		}
+	default:
+		return ip.errorf(`unknown import kind "%s"`, kind)
+	}
+	if err != nil {
+		return fmt.Errorf(`import "%s": %s`, file, err)
+	}
+	pr.Types = append(pr.Types, imported.Types...)
+	for name, m := range imported.Messages {
+		pr.Messages[name] = m
+	}
+	return nil
+}
+
+// namedType parses a record, error, enum or fixed declaration.
+func (ip *idlParser) namedType(namespace, doc string, as idlAnnotations) (Schema, error) {
+	kind, _ := ip.ident()
+	name, err := ip.ident()
+	if err != nil {
+		return nil, err
+	}
+	nf := NameFields{Name: name}
+	if nf.Namespace, err = ip.stringAnnotation(as, "namespace"); err != nil {
+		return nil, err
+	}
+	if nf.Aliases, err = ip.aliasesAnnotation(as); err != nil {
+		return nil, err
+	}
+	nf = qualify(nf, namespace)
+	switch kind {
+	case "enum":
+		e := Enum{NameFields: nf, Doc: doc, Symbols: []string{}}
+		if err := ip.expect('{'); err != nil {
+			return nil, err
+		}
+		for !ip.accept('}') {
+			if len(e.Symbols) > 0 {
+				if err := ip.expect(','); err != nil {
+					return nil, err
+				}
+			}
+			sym, err := ip.ident()
+			if err != nil {
+				return nil, err
+			}
+			e.Symbols = append(e.Symbols, sym)
+		}
+		if ip.accept('=') {
+			if e.Default, err = ip.ident(); err != nil {
+				return nil, err
+			}
+			if err := ip.expect(';'); err != nil {
+				return nil, err
+			}
+		}
+		return e, ip.define(e)
+	case "fixed":
+		if err := ip.expect('('); err != nil {
+			return nil, err
+		}
+		size, err := ip.integer()
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, ip.errorf(`fixed size %d cannot be negative`, size)
+		}
+		if err := ip.expect(')'); err != nil {
+			return nil, err
+		}
+		if err := ip.expect(';'); err != nil {
+			return nil, err
+		}
+		f := Fixed{NameFields: nf, Size: uint(size)}
+		return f, ip.define(f)
+	}
+	r := Record{NameFields: nf, Doc: doc}
+	if err := ip.expect('{'); err != nil {
+		return nil, err
+	}
+	// Fields are appended to a slice of the right length once known, so
+	// that pointers to them stay valid.
+	var fields []Field
+	for !ip.accept('}') {
+		if ip.peek() == 0 {
+			return nil, ip.errorf(`expected "}" but found end of file`)
+		}
+		fs, err := ip.fields(r.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, fs...)
+	}
+	r.Fields = make([]Field, len(fields))
+	copy(r.Fields, fields)
+	for i := range r.Fields {
+		ip.schemas.deferDefault(&r.Fields[i], r.Fullname())
+	}
+	return r, ip.define(r)
+}
+
+func (ip *idlParser) define(s Schema) error {
+	if err := ip.schemas.define(s); err != nil {
+		return ip.errorf(`%s`, err)
+	}
+	return nil
+}
+
+// fields parses a field declaration, which may declare several variables of
+// the same type, up to and including its ";". Defaults are left for the caller
+// to defer.
+func (ip *idlParser) fields(namespace string) ([]Field, error) {
+	ip.skip()
+	doc := ip.takeDoc()
+	t, err := ip.fieldType(namespace)
+	if err != nil {
+		return nil, err
+	}
+	var fields []Field
+	for {
+		f, err := ip.variable(t, doc)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+		if !ip.accept(',') {
+			return fields, ip.expect(';')
+		}
+	}
+}
+
+// variable parses the annotations, name and default of a field of type t.
+func (ip *idlParser) variable(t Schema, doc string) (Field, error) {
+	ip.skip()
+	if d := ip.takeDoc(); d != "" {
+		doc = d
+	}
+	as, err := ip.annotations()
+	if err != nil {
+		return Field{}, err
+	}
+	name, err := ip.ident()
+	if err != nil {
+		return Field{}, err
+	}
+	f := Field{Name: name, Doc: doc, Type: t}
+	if f.Order, err = ip.stringAnnotation(as, "order"); err != nil {
+		return Field{}, err
+	}
+	if f.Aliases, err = ip.aliasesAnnotation(as); err != nil {
+		return Field{}, err
+	}
+	valueFirst := false
+	if ip.accept('=') {
+		v, err := ip.json()
+		if err != nil {
+			return Field{}, err
+		}
+		d, err := unmarshalDefault(v)
+		if err != nil {
+			return Field{}, ip.errorf(`invalid default: %s`, err)
+		}
+		f.Default = &d
+		valueFirst = d != nil
+	}
+	// An optional type is a union with null, which comes first unless the
+	// default is not null.
+	if o, ok := t.(idlOptional); ok {
+		f.Type = o.union(valueFirst)
+	}
+	return f, nil
+}
+
+// idlOptional is the type "T?" until its default, which decides the order
+// of its union, is known.
+type idlOptional struct {
+	Schema
+}
+
+func (o idlOptional) union(valueFirst bool) Union {
+	if valueFirst {
+		return Union{o.Schema, Null}
+	}
+	return Union{Null, o.Schema}
+}
+
+// fieldType parses a type with its annotations, including optional types.
+func (ip *idlParser) fieldType(namespace string) (Schema, error) {
+	as, err := ip.annotations()
+	if err != nil {
+		return nil, err
+	}
+	t, err := ip.typ(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if lt, err := ip.stringAnnotation(as, "logicalType"); err != nil {
+		return nil, err
+	} else if lt != "" {
+		var precision, scale int
+		json.Unmarshal(as["precision"], &precision)
+		json.Unmarshal(as["scale"], &scale)
+		l := Logical{LogicalType: lt, Schema: t, Precision: precision, Scale: scale}
+		if l.Valid() == nil {
+			t = l
+		}
+	}
+	if ip.accept('?') {
+		return idlOptional{t}, nil
+	}
+	return t, nil
+}
+
+// typ parses a type name, logical type, array, map or union.
+func (ip *idlParser) typ(namespace string) (Schema, error) {
+	ip.skip()
+	quoted := ip.peek() == '`'
+	name, err := ip.ident()
+	if err != nil {
+		return nil, err
+	}
+	if quoted {
+		return ip.schemas.reference(name, namespace), nil
+	}
+	switch name {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return Primitive(name), nil
+	case "date":
+		return Logical{LogicalType: LogicalDate, Schema: Int}, nil
+	case "time_ms":
+		return Logical{LogicalType: LogicalTimeMillis, Schema: Int}, nil
+	case "timestamp_ms":
+		return Logical{LogicalType: LogicalTimestampMillis, Schema: Long}, nil
+	case "uuid":
+		return Logical{LogicalType: LogicalUUID, Schema: String}, nil
+	case "decimal":
+		if err := ip.expect('('); err != nil {
+			return nil, err
+		}
+		precision, err := ip.integer()
+		if err != nil {
+			return nil, err
+		}
+		scale := 0
+		if ip.accept(',') {
+			if scale, err = ip.integer(); err != nil {
+				return nil, err
+			}
+		}
+		if err := ip.expect(')'); err != nil {
+			return nil, err
+		}
+		l := Logical{LogicalType: LogicalDecimal, Schema: Bytes, Precision: precision, Scale: scale}
+		if err := l.Valid(); err != nil {
+			return nil, ip.errorf(`%s`, err)
+		}
+		return l, nil
+	case "array", "map":
+		if err := ip.expect('<'); err != nil {
+			return nil, err
+		}
+		t, err := ip.fieldType(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if o, ok := t.(idlOptional); ok {
+			t = o.union(false)
+		}
+		if err := ip.expect('>'); err != nil {
+			return nil, err
+		}
+		if name == "array" {
+			return Array{Items: t}, nil
+		}
+		return Map{Values: t}, nil
+	case "union":
+		if err := ip.expect('{'); err != nil {
+			return nil, err
+		}
+		var u Union
+		for !ip.accept('}') {
+			if len(u) > 0 {
+				if err := ip.expect(','); err != nil {
+					return nil, err
+				}
+			}
+			t, err := ip.fieldType(namespace)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := t.(idlOptional); ok {
+				return nil, ip.errorf(`union branches cannot be optional`)
+			}
+			u = append(u, t)
+		}
+		return u, nil
+	}
+	return ip.schemas.reference(name, namespace), nil
+}
+
+// message parses a message declaration.
+func (ip *idlParser) message(pr *Protocol, doc string, as idlAnnotations) error {
+	var response Schema = Null
+	if ip.peekIdent() == "void" {
+		ip.acceptKeyword("void")
+	} else {
+		t, err := ip.fieldType(pr.Namespace)
+		if err != nil {
+			return err
+		}
+		if o, ok := t.(idlOptional); ok {
+			t = o.union(false)
+		}
+		response = t
+	}
+	name, err := ip.ident()
+	if err != nil {
+		return err
+	}
+	if _, ok := pr.Messages[name]; ok {
+		return ip.errorf(`message "%s" is defined more than once`, name)
+	}
+	m := Message{Doc: doc, Response: response}
+	if err := ip.expect('('); err != nil {
+		return err
+	}
+	var params []Field
+	for !ip.accept(')') {
+		if len(params) > 0 {
+			if err := ip.expect(','); err != nil {
+				return err
+			}
+		}
+		ip.skip()
+		doc := ip.takeDoc()
+		t, err := ip.fieldType(pr.Namespace)
+		if err != nil {
+			return err
+		}
+		f, err := ip.variable(t, doc)
+		if err != nil {
+			return err
+		}
+		params = append(params, f)
+	}
+	m.Request = make([]Field, len(params))
+	copy(m.Request, params)
+	for i := range m.Request {
+		ip.schemas.deferDefault(&m.Request[i], name)
+	}
+	switch {
+	case ip.acceptKeyword("oneway"):
+		m.OneWay = true
+	case ip.acceptKeyword("throws"):
+		for {
+			e, err := ip.ident()
+			if err != nil {
+				return err
+			}
+			m.Errors = append(m.Errors, ip.schemas.reference(e, pr.Namespace))
+			if !ip.accept(',') {
+				break
+			}
+		}
+	}
+	if err := ip.expect(';'); err != nil {
+		return err
+	}
+	pr.Messages[name] = m
+	return nil
+}
@@ -0,0 +1,143 @@
+package avro
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const testIDL = `
+/** Sends mail. */
+@namespace("test.mail")
+protocol Mail {
+	/** How urgent a message is. */
+	@aliases(["test.mail.Priority"])
+	enum Urgency { LOW, HIGH } = LOW;
+
+	fixed Hash(4);
+
+	record Message {
+		/** The recipient. */
+		string to;
+		Urgency @order("descending") urgency = "LOW";
+		string? subject;
+		string? signature = "regards";
+		union { null, Hash } hash = null;
+		array<string> cc = [], bcc = [];
+		map<long> counts = {};
+		timestamp_ms sent;
+		decimal(9, 2) price;
+		Message? reply;
+		string ` + "`error`" + `;
+	}
+
+	error Bounce { string reason; }
+
+	// Not a doc comment.
+	string send(Message message, boolean urgent = false) throws Bounce;
+	void ping() oneway;
+	void ` + "`record`" + `();
+}
+`
+
+func TestParseIDL(t *testing.T) {
+	is := is.New(t)
+
+	p, err := ParseIDL([]byte(testIDL))
+	is.NoErr(err) // parse IDL
+	is.Equal(p.Fullname(), "test.mail.Mail")
+	is.Equal(p.Doc, "Sends mail.")
+	is.Equal(len(p.Types), 4)
+
+	e := p.Types[0].(Enum)
+	is.Equal(e.Fullname(), "test.mail.Urgency")
+	is.Equal(e.Doc, "How urgent a message is.")
+	is.Equal(e.Aliases, []string{"test.mail.Priority"})
+	is.Equal(e.Symbols, []string{"LOW", "HIGH"})
+	is.Equal(e.Default, "LOW") // enum default
+	is.Equal(p.Types[1], Fixed{NameFields: NameFields{Name: "Hash", Namespace: "test.mail"}, Size: 4})
+
+	r := p.Types[2].(Record)
+	field := func(name string) *Field {
+		f, ok := r.GetField(name)
+		is.True(ok) // field exists
+		return f
+	}
+	is.Equal(field("to").Doc, "The recipient.")
+	is.Equal(field("urgency").Order, "descending")
+	is.Equal(*field("urgency").Default, "LOW")
+	is.Equal(field("subject").Type, Union{Null, String})   // optional type
+	is.Equal(field("signature").Type, Union{String, Null}) // optional type with non-null default
+	is.Equal(*field("cc").Default, []interface{}{})
+	is.Equal(field("bcc").Type, Array{Items: String}) // several variables of a type
+	is.Equal(field("counts").Type, Map{Values: Long})
+	is.Equal(field("sent").Type, Logical{LogicalType: LogicalTimestampMillis, Schema: Long})
+	is.Equal(field("price").Type, Logical{LogicalType: LogicalDecimal, Schema: Bytes, Precision: 9, Scale: 2})
+	is.Equal(field("reply").Type.(Union)[1].(Reference).Name, "test.mail.Message") // recursive reference
+	is.Equal(field("error").Type, String)                                          // quoted identifier
+	is.Equal(p.Types[3].(Record).Fullname(), "test.mail.Bounce")
+
+	send := p.Messages["send"]
+	is.Equal(send.Response, String)
+	is.Equal(len(send.Request), 2)
+	is.Equal(*send.Request[1].Default, false)
+	is.Equal(send.Errors[0].(Reference).Name, "test.mail.Bounce")
+	is.True(p.Messages["ping"].OneWay)
+	is.Equal(p.Messages["record"].Response, Null) // void
+}
+
+func TestParseIDL_errors(t *testing.T) {
+	is := is.New(t)
+
+	for _, src := range []string{
+		``,
+		`protocol P {`,
+		`protocol P { record R { int x } }`,
+		`protocol P { record R { Missing x; } }`,
+		`protocol P { record R { int x = "no"; } }`,
+		`protocol P { fixed F(-1); }`,
+		`protocol P { import schema "x.avsc"; }`,
+		`protocol P { int m(); int m(); }`,
+		`protocol P { } extra`,
+	} {
+		_, err := ParseIDL([]byte(src))
+		is.True(err != nil) // invalid IDL returns error
+	}
+}
+
+func TestParseIDLFile(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		is.NoErr(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("main.avdl", `
+		@namespace("test")
+		protocol Main {
+			import idl "common.avdl";
+			import schema "user.avsc";
+			import protocol "ping.avpr";
+			record Order { User user; Money total; }
+		}`)
+	write("common.avdl", `
+		@namespace("test")
+		protocol Common {
+			import idl "main.avdl";
+			record Money { long cents; }
+		}`)
+	write("user.avsc", `{"type": "record", "name": "test.User", "fields": [{"name": "name", "type": "string"}]}`)
+	write("ping.avpr", `{"protocol": "Ping", "namespace": "test", "messages": {"ping": {"request": [], "response": "null"}}}`)
+
+	p, err := ParseIDLFile(filepath.Join(dir, "main.avdl"))
+	is.NoErr(err) // parse IDL with imports
+	names := []string{}
+	for _, s := range p.Types {
+		names = append(names, s.(NamedSchema).Fullname())
+	}
+	is.Equal(names, []string{"test.Money", "test.User", "test.Order"}) // imported types come first
+	_, ok := p.Messages["ping"]
+	is.True(ok) // imported message
+}
@@ -0,0 +1,255 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// SchemaOption configures SchemaOf.
+type SchemaOption func(*schemaOptions)
+
+type schemaOptions struct {
+	namespace    string
+	doc          string
+	zeroDefaults bool
+	types        map[reflect.Type]Schema
+}
+
+// WithNamespace sets the namespace of every named type derived by SchemaOf.
+func WithNamespace(namespace string) SchemaOption {
+	return func(o *schemaOptions) { o.namespace = namespace }
+}
+
+// WithDoc sets the doc of the derived record.
+func WithDoc(doc string) SchemaOption {
+	return func(o *schemaOptions) { o.doc = doc }
+}
+
+// WithZeroDefaults gives every field without an "avrodefault" tag a default
+// of its Go zero value, so the schema can read data written without them.
+func WithZeroDefaults() SchemaOption {
+	return func(o *schemaOptions) { o.zeroDefaults = true }
+}
+
+// WithTypeSchema makes SchemaOf use s for values of Go type t, for example an
+// Enum for a string type, or a different logical type for time.Time.
+func WithTypeSchema(t reflect.Type, s Schema) SchemaOption {
+	return func(o *schemaOptions) { o.types[t] = s }
+}
+
+// SchemaOf derives a Record schema from a Go struct type, given either as a
+// reflect.Type or as a value of the type (or a pointer to it).
+//
+// Field names are taken from the "avro" tag when present, and fields tagged
+// `avro:"-"` or unexported are left out. The "avrodoc" tag sets a field's doc
+// and the "avrodefault" tag its default, as JSON.
+//
+// Go types map to booleans, ints (int8, int16, int32, uint8, uint16), longs
+// (int, int64, uint, uint32, uint64), floats, doubles, strings and bytes.
+// Byte arrays become fixed, other slices and arrays become arrays, maps with
+// string keys become maps and structs become records named after their type.
+// Pointers become a union of null and the element type, and time.Time
+// becomes a long with logical type "timestamp-millis". Named types which
+// appear more than once, including recursive types, are defined once and then
+// referred to by name.
+func SchemaOf(v interface{}, opts ...SchemaOption) (Record, error) {
+	o := schemaOptions{types: map[reflect.Type]Schema{
+		timeType: Logical{LogicalType: LogicalTimestampMillis, Schema: Long},
+	}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return Record{}, fmt.Errorf(`cannot derive a record schema from "%v"`, t)
+	}
+	inf := inferrer{opts: o, named: map[string]namedType{}}
+	s, err := inf.schemaOf(t)
+	if err != nil {
+		return Record{}, err
+	}
+	r := s.(Record)
+	r.Doc = o.doc
+	for _, nt := range inf.named {
+		if err := nt.ref.Bind(nt.schema); err != nil {
+			return Record{}, err
+		}
+	}
+	if err := r.Valid(); err != nil {
+		return Record{}, err
+	}
+	return r, nil
+}
+
+// namedType is a named schema derived from a Go type, with the Reference used
+// wherever it appears after its definition.
+type namedType struct {
+	goType reflect.Type
+	schema Schema
+	ref    Reference
+}
+
+type inferrer struct {
+	opts  schemaOptions
+	named map[string]namedType // by full name
+}
+
+func (inf *inferrer) schemaOf(t reflect.Type) (Schema, error) {
+	if s, ok := inf.opts.types[t]; ok {
+		return s, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return Int, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return Long, nil
+	case reflect.Float32:
+		return Float, nil
+	case reflect.Float64:
+		return Double, nil
+	case reflect.String:
+		return String, nil
+	case reflect.Ptr:
+		s, err := inf.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.(Union); ok {
+			return s, nil
+		}
+		return Union{Null, s}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Bytes, nil
+		}
+		items, err := inf.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return Array{Items: items}, nil
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			name := t.Name()
+			if name == "" {
+				name = fmt.Sprintf("Fixed%d", t.Len())
+			}
+			return inf.define(t, name, func(nf NameFields) (Schema, error) {
+				return Fixed{NameFields: nf, Size: uint(t.Len())}, nil
+			})
+		}
+		items, err := inf.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return Array{Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf(`map key of "%s" must be a string`, t)
+		}
+		values, err := inf.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return Map{Values: values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return nil, fmt.Errorf(`anonymous struct "%s" cannot be a named record`, t)
+		}
+		return inf.define(t, t.Name(), func(nf NameFields) (Schema, error) {
+			return inf.record(t, nf)
+		})
+	}
+	return nil, fmt.Errorf(`cannot derive a schema from "%s"`, t)
+}
+
+// define returns the schema built by build for a named Go type, or a Reference
+// if the type has already been seen.
+func (inf *inferrer) define(t reflect.Type, name string, build func(NameFields) (Schema, error)) (Schema, error) {
+	nf := NameFields{Name: name, Namespace: inf.opts.namespace}
+	if nt, ok := inf.named[nf.Fullname()]; ok {
+		if nt.goType != t {
+			return nil, fmt.Errorf(`types "%s" and "%s" have the same name "%s"`, nt.goType, t, nf.Fullname())
+		}
+		return nt.ref, nil
+	}
+	nt := namedType{goType: t, ref: NewReference(nf.Fullname())}
+	inf.named[nf.Fullname()] = nt
+	s, err := build(nf)
+	if err != nil {
+		return nil, err
+	}
+	nt.schema = s
+	inf.named[nf.Fullname()] = nt
+	return s, nil
+}
+
+func (inf *inferrer) record(t reflect.Type, nf NameFields) (Schema, error) {
+	r := Record{NameFields: nf, Fields: []Field{}}
+	for i := 0; i < t.NumField(); i++ {
+		rf := t.Field(i)
+		if skipStructField(rf) {
+			continue
+		}
+		fs, err := inf.schemaOf(rf.Type)
+		if err != nil {
+			return nil, fmt.Errorf(`field "%s": %s`, rf.Name, err)
+		}
+		f := Field{
+			Name: structFieldName(rf),
+			Doc:  rf.Tag.Get("avrodoc"),
+			Type: fs,
+		}
+		if def, ok := rf.Tag.Lookup("avrodefault"); ok {
+			if f.Default, err = inferDefault(rf.Type, def); err != nil {
+				return nil, fmt.Errorf(`field "%s": %s`, rf.Name, err)
+			}
+			// A union's default must match its first schema.
+			if u, ok := f.Type.(Union); ok && len(u) == 2 && *f.Default != nil {
+				f.Type = Union{u[1], u[0]}
+			}
+		} else if inf.opts.zeroDefaults {
+			f.Default = zeroDefault(rf.Type)
+		}
+		r.Fields = append(r.Fields, f)
+	}
+	return r, nil
+}
+
+// inferDefault parses a JSON default into a value of Go type t.
+func inferDefault(t reflect.Type, def string) (*interface{}, error) {
+	p := reflect.New(t)
+	if err := json.Unmarshal([]byte(def), p.Interface()); err != nil {
+		return nil, fmt.Errorf(`invalid default %s: %s`, def, err)
+	}
+	v := indirect(p.Elem())
+	var d interface{}
+	if v.IsValid() {
+		d = v.Interface()
+	}
+	return &d, nil
+}
+
+// zeroDefault returns the zero value of Go type t, with empty rather than nil
+// slices and maps.
+func zeroDefault(t reflect.Type) *interface{} {
+	var d interface{}
+	switch t.Kind() {
+	case reflect.Ptr:
+	case reflect.Slice:
+		d = reflect.MakeSlice(t, 0, 0).Interface()
+	case reflect.Map:
+		d = reflect.MakeMap(t).Interface()
+	default:
+		d = reflect.Zero(t).Interface()
+	}
+	return &d
+}
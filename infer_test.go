@@ -0,0 +1,212 @@
+package avro
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+type inferStatus string
+
+type inferAddress struct {
+	Street string `avro:"street"`
+}
+
+type inferUser struct {
+	ID       int64             `avro:"id" avrodoc:"unique id"`
+	Name     string            `avro:"name"`
+	Age      int32             `avro:"age" avrodefault:"18"`
+	Email    *string           `avro:"email"`
+	Nick     *string           `avro:"nick" avrodefault:"\"anon\""`
+	Tags     []string          `avro:"tags"`
+	Attrs    map[string]int    `avro:"attrs"`
+	Created  time.Time         `avro:"created"`
+	Hash     [4]byte           `avro:"hash"`
+	Status   inferStatus       `avro:"status"`
+	Home     inferAddress      `avro:"home"`
+	Work     *inferAddress     `avro:"work"`
+	Scores   map[string]string `avro:"-"`
+	internal int
+}
+
+type inferNode struct {
+	Value    int64
+	Children []inferNode
+	Next     *inferNode
+}
+
+func TestSchemaOf(t *testing.T) {
+	is := is.New(t)
+
+	status := Enum{NameFields: NameFields{Name: "Status"}, Symbols: []string{"ACTIVE", "BANNED"}}
+	r, err := SchemaOf(&inferUser{},
+		WithNamespace("com.example"),
+		WithDoc("a user"),
+		WithTypeSchema(reflect.TypeOf(inferStatus("")), status),
+	)
+	is.NoErr(err)                                   // derives schema
+	is.Equal(r.Fullname(), "com.example.inferUser") // named after the type
+	is.Equal(r.Doc, "a user")                       // doc option
+	is.Equal(len(r.Fields), 12)                     // skips "-" and unexported fields
+
+	f, _ := r.GetField("id")
+	is.Equal(f.Type, Long)       // int64 is long
+	is.Equal(f.Doc, "unique id") // doc from tag
+	f, _ = r.GetField("age")
+	is.Equal(f.Type, Int)           // int32 is int
+	is.Equal(*f.Default, int32(18)) // default from tag
+	f, _ = r.GetField("email")
+	is.Equal(f.Type, Union{Null, String}) // pointer is optional
+	f, _ = r.GetField("nick")
+	is.Equal(f.Type, Union{String, Null}) // non-null default comes first
+	f, _ = r.GetField("tags")
+	is.Equal(f.Type, Array{Items: String}) // slice is array
+	f, _ = r.GetField("attrs")
+	is.Equal(f.Type, Map{Values: Long}) // map is map
+	f, _ = r.GetField("created")
+	is.Equal(f.Type, Logical{LogicalType: LogicalTimestampMillis, Schema: Long}) // time is timestamp
+	f, _ = r.GetField("hash")
+	is.Equal(f.Type.(Fixed).Size, uint(4)) // byte array is fixed
+	f, _ = r.GetField("status")
+	is.Equal(f.Type, status) // type override
+	f, _ = r.GetField("home")
+	is.Equal(f.Type.(Record).Fullname(), "com.example.inferAddress") // struct is record
+	f, _ = r.GetField("work")
+	ref := f.Type.(Union)[1].(Reference)
+	is.Equal(ref.Name, "com.example.inferAddress")                         // repeated type is a reference
+	is.Equal(ref.Target().(Record).Fullname(), "com.example.inferAddress") // reference is bound
+
+	email := "a@b.c"
+	u := inferUser{ID: 1, Email: &email, Created: time.Unix(1, 0), Status: "ACTIVE"}
+	is.NoErr(r.Validate(u)) // derived schema validates the struct
+	b, err := Encode(r, u)
+	is.NoErr(err) // encodes the struct
+	var got inferUser
+	is.NoErr(Decode(r, b, &got))          // decodes the struct
+	is.Equal(got.Email, &email)           // optional value round trips
+	is.True(got.Created.Equal(u.Created)) // timestamp round trips
+	is.True(got.Work == nil)              // null round trips
+}
+
+func TestSchemaOf_recursive(t *testing.T) {
+	is := is.New(t)
+
+	r, err := SchemaOf(reflect.TypeOf(inferNode{}), WithZeroDefaults())
+	is.NoErr(err) // derives recursive schema
+	f, _ := r.GetField("Next")
+	is.Equal(f.Type.(Union)[1].(Reference).Name, "inferNode") // recursion uses a reference
+	is.Equal(*f.Default, nil)                                 // zero default of pointer is null
+	f, _ = r.GetField("Value")
+	is.Equal(*f.Default, int64(0)) // zero default
+
+	n := inferNode{Value: 1, Children: []inferNode{{Value: 2}}, Next: &inferNode{Value: 3}}
+	is.NoErr(r.Validate(n)) // validates recursive value
+	v, err := Compile(r)
+	is.NoErr(err)           // compiles recursive schema
+	is.NoErr(v.Validate(n)) // compiled validator handles recursion
+
+	b, err := Encode(r, n)
+	is.NoErr(err) // encodes recursive value
+	var got inferNode
+	is.NoErr(Decode(r, b, &got)) // decodes recursive value
+	eq, err := Equal(r, got, n)
+	is.NoErr(err)
+	is.True(eq) // round trips
+}
+
+type inferKinds struct {
+	B    bool
+	I    int
+	I8   int8
+	I16  int16
+	I32  int32
+	I64  int64
+	U    uint
+	U8   uint8
+	U16  uint16
+	U32  uint32
+	U64  uint64
+	F32  float32
+	F64  float64
+	S    string
+	MyS  inferStatus
+	Raw  []byte
+	Arr  [2]byte
+	PB   *bool
+	PI8  *int8
+	PI16 *int16
+	PU8  *uint8
+	PU16 *uint16
+	PU32 *uint32
+	PU   *uint
+	PF32 *float32
+	PS   *string
+	PMyS *inferStatus
+	PRaw *[]byte
+}
+
+func TestSchemaOf_kinds(t *testing.T) {
+	is := is.New(t)
+
+	r, err := SchemaOf(inferKinds{})
+	is.NoErr(err)
+	v, err := Compile(r)
+	is.NoErr(err)
+
+	b, i8, i16, u8, u16, u32, u, f, s, my, raw := true, int8(-8), int16(-16), uint8(8), uint16(16), uint32(32), uint(64), float32(1.5), "s", inferStatus("my"), []byte{1}
+	for _, k := range []inferKinds{
+		{},
+		{
+			B: true, I: -1, I8: -8, I16: -16, I32: -32, I64: -64, U: 1, U8: 8, U16: 16, U32: 1 << 31, U64: 1 << 40,
+			F32: 1.5, F64: 2.5, S: "s", MyS: "my", Raw: []byte{1, 2}, Arr: [2]byte{3, 4},
+			PB: &b, PI8: &i8, PI16: &i16, PU8: &u8, PU16: &u16, PU32: &u32, PU: &u,
+			PF32: &f, PS: &s, PMyS: &my, PRaw: &raw,
+		},
+	} {
+		is.NoErr(r.Validate(k)) // validates the struct the schema came from
+		is.NoErr(v.Validate(k)) // compiled validator agrees
+		data, err := Encode(r, k)
+		is.NoErr(err)
+		var got inferKinds
+		is.NoErr(Decode(r, data, &got))
+		if k.Raw == nil {
+			got.Raw = nil
+		}
+		is.Equal(got, k) // round trips
+	}
+}
+
+func TestSchemaOf_errors(t *testing.T) {
+	is := is.New(t)
+
+	_, err := SchemaOf(1)
+	is.True(err != nil) // not a struct
+	_, err = SchemaOf(struct{ C chan int }{})
+	is.True(err != nil) // unsupported field type
+	_, err = SchemaOf(struct {
+		M map[int]string
+	}{})
+	is.True(err != nil) // map keys must be strings
+
+	type bad struct {
+		X int `avrodefault:"nope"`
+	}
+	_, err = SchemaOf(bad{})
+	is.True(err != nil) // invalid default
+
+	type Fixed4 [8]byte
+	type clash struct {
+		A [4]byte
+		B Fixed4
+	}
+	_, err = SchemaOf(clash{})
+	is.True(err != nil) // fixed types of different sizes share a name
+	type same struct {
+		A [4]byte
+		B [4]uint8
+	}
+	_, err = SchemaOf(same{})
+	is.NoErr(err) // the same array type is a reference
+}
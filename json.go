@@ -0,0 +1,322 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// EncodeJSON returns the Avro JSON encoding of v according to s. Values are
+// first encoded with Encode, so v may be of any Go type it accepts. Bytes and
+// fixed are written as strings with one character per byte, and non-null union
+// values as an object whose only key is the name of the branch, such as
+// {"string": "a"} or {"com.example.User": {...}}.
+func EncodeJSON(s Schema, v interface{}) ([]byte, error) {
+	b, err := Encode(s, v)
+	if err != nil {
+		return nil, err
+	}
+	d := decoder{buf: b}
+	j, err := d.jsonValue(s, true)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// DecodeJSON reads the Avro JSON encoding of a value with schema s from data
+// and stores it in the value pointed to by v, which may be of any type
+// accepted by Decode. Record fields missing from data take their defaults.
+func DecodeJSON(s Schema, data []byte, v interface{}) error {
+	if s == nil {
+		return errors.New(`cannot decode with nil schema`)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var j interface{}
+	if err := dec.Decode(&j); err != nil {
+		return fmt.Errorf(`unmarshal json: %s`, err)
+	}
+	if dec.More() {
+		return errors.New(`unexpected data after json value`)
+	}
+	var e encoder
+	if err := e.encodeJSON(s, j); err != nil {
+		return err
+	}
+	return Decode(s, e.buf, v)
+}
+
+// encodeJSON appends the binary encoding of v, a JSON value decoded with
+// json.Number for numbers, according to s.
+func (e *encoder) encodeJSON(s Schema, v interface{}) error {
+	switch s := resolve(s).(type) {
+	case Primitive:
+		return e.encodeJSONPrimitive(s, v)
+	case Record:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf(`record "%s" must be an object`, s.Fullname())
+		}
+		for _, f := range s.Fields {
+			fv, ok := m[f.Name]
+			if !ok {
+				if f.Default == nil {
+					return fmt.Errorf(`record "%s" is missing field "%s"`, s.Fullname(), f.Name)
+				}
+				if err := e.encode(f.Type, reflect.ValueOf(*f.Default)); err != nil {
+					return fmt.Errorf(`field "%s": %s`, f.Name, err)
+				}
+				continue
+			}
+			if err := e.encodeJSON(f.Type, fv); err != nil {
+				return fmt.Errorf(`field "%s": %s`, f.Name, err)
+			}
+		}
+		return nil
+	case Enum:
+		sym, ok := v.(string)
+		if !ok {
+			return fmt.Errorf(`enum "%s" must be a string`, s.Fullname())
+		}
+		for i, symbol := range s.Symbols {
+			if symbol == sym {
+				e.writeLong(int64(i))
+				return nil
+			}
+		}
+		return fmt.Errorf(`symbol "%s" does not exist in enum "%s"`, sym, s.Fullname())
+	case Fixed:
+		b, err := latin1Bytes(v)
+		if err != nil {
+			return err
+		}
+		if err := s.checkBytes(b); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, b...)
+		return nil
+	case Array:
+		items, ok := v.([]interface{})
+		if !ok {
+			return errors.New(`array must be an array`)
+		}
+		if len(items) > 0 {
+			e.writeLong(int64(len(items)))
+			for i, item := range items {
+				if err := e.encodeJSON(s.Items, item); err != nil {
+					return fmt.Errorf(`item %d: %s`, i, err)
+				}
+			}
+		}
+		e.writeLong(0)
+		return nil
+	case Map:
+		values, ok := v.(map[string]interface{})
+		if !ok {
+			return errors.New(`map must be an object`)
+		}
+		if len(values) > 0 {
+			e.writeLong(int64(len(values)))
+			keys := make([]string, 0, len(values))
+			for k := range values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				e.writeString(k)
+				if err := e.encodeJSON(s.Values, values[k]); err != nil {
+					return fmt.Errorf(`value "%s": %s`, k, err)
+				}
+			}
+		}
+		e.writeLong(0)
+		return nil
+	case Union:
+		return e.encodeJSONUnion(s, v)
+	case Logical:
+		return e.encodeJSON(s.Schema, v)
+	}
+	return fmt.Errorf(`cannot encode schema type "%s"`, s.Type())
+}
+
+func (e *encoder) encodeJSONPrimitive(p Primitive, v interface{}) error {
+	switch p {
+	case Null:
+		if v == nil {
+			return nil
+		}
+	case Boolean:
+		if b, ok := v.(bool); ok {
+			if b {
+				e.buf = append(e.buf, 1)
+			} else {
+				e.buf = append(e.buf, 0)
+			}
+			return nil
+		}
+	case Int, Long:
+		n, ok := v.(json.Number)
+		if !ok {
+			break
+		}
+		i, err := n.Int64()
+		if err != nil || p == Int && (i < math.MinInt32 || i > math.MaxInt32) {
+			return fmt.Errorf(`%s is not a valid "%s"`, n, p)
+		}
+		e.writeLong(i)
+		return nil
+	case Float, Double:
+		n, ok := v.(json.Number)
+		if !ok {
+			break
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return fmt.Errorf(`%s is not a valid "%s"`, n, p)
+		}
+		if p == Float {
+			e.writeFloat(float32(f))
+		} else {
+			e.writeDouble(f)
+		}
+		return nil
+	case Bytes:
+		b, err := latin1Bytes(v)
+		if err != nil {
+			return err
+		}
+		e.writeBytes(b)
+		return nil
+	case String:
+		if s, ok := v.(string); ok {
+			e.writeString(s)
+			return nil
+		}
+	}
+	return fmt.Errorf(`%s is not a valid "%s"`, jsonString(v), p)
+}
+
+// encodeJSONUnion encodes null as is and other values from an object whose
+// only key names the branch.
+func (e *encoder) encodeJSONUnion(u Union, v interface{}) error {
+	if v == nil {
+		for i, s := range u {
+			if s == Null {
+				e.writeLong(int64(i))
+				return nil
+			}
+		}
+		return errors.New(`union has no "null" schema`)
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return fmt.Errorf(`union value %s must be an object with a single branch name`, jsonString(v))
+	}
+	for name, bv := range m {
+		for i, s := range u {
+			if unionName(s) == name {
+				e.writeLong(int64(i))
+				return e.encodeJSON(s, bv)
+			}
+		}
+		return fmt.Errorf(`union has no schema "%s"`, name)
+	}
+	return nil
+}
+
+// jsonValue decodes a value to its JSON representation: records and maps
+// become objects, arrays become arrays and bytes and fixed become strings with
+// one character per byte. Non-null union values are wrapped in an object keyed
+// by the branch name if wrap is true, as the JSON encoding requires, and are
+// left bare as in schema defaults otherwise.
+func (d *decoder) jsonValue(s Schema, wrap bool) (interface{}, error) {
+	switch s := resolve(s).(type) {
+	case Primitive:
+		switch s {
+		case Bytes:
+			b, err := d.readBytes()
+			return latin1String(b), err
+		case Float:
+			return d.readFloat()
+		}
+		return d.decodeGeneric(s)
+	case Record:
+		m := make(map[string]interface{}, len(s.Fields))
+		for _, f := range s.Fields {
+			v, err := d.jsonValue(f.Type, wrap)
+			if err != nil {
+				return nil, err
+			}
+			m[f.Name] = v
+		}
+		return m, nil
+	case Enum:
+		n, err := d.readLong()
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 || n >= int64(len(s.Symbols)) {
+			return nil, fmt.Errorf(`enum ordinal %d out of range`, n)
+		}
+		return s.Symbols[n], nil
+	case Fixed:
+		b, err := d.next(int(s.Size))
+		return latin1String(b), err
+	case Array:
+		a := []interface{}{}
+		bi := blockItems{d: d, items: s.Items}
+		for {
+			ok, err := bi.next()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return a, nil
+			}
+			v, err := d.jsonValue(s.Items, wrap)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+	case Map:
+		m := map[string]interface{}{}
+		bi := blockItems{d: d}
+		for {
+			ok, err := bi.next()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return m, nil
+			}
+			k, err := d.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.jsonValue(s.Values, wrap)
+			if err != nil {
+				return nil, err
+			}
+			m[string(k)] = v
+		}
+	case Union:
+		i, err := d.readUnionIndex(s)
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.jsonValue(s[i], wrap)
+		if err != nil || !wrap || s[i] == Null {
+			return v, err
+		}
+		return map[string]interface{}{unionName(s[i]): v}, nil
+	case Logical:
+		return d.jsonValue(s.Schema, wrap)
+	}
+	return nil, fmt.Errorf(`cannot decode schema type "%s"`, s.Type())
+}
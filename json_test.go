@@ -0,0 +1,75 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const jsonTestSchema = `{"type": "record", "name": "ns.Item", "fields": [
+	{"name": "id", "type": "long"},
+	{"name": "price", "type": "float"},
+	{"name": "raw", "type": "bytes"},
+	{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}},
+	{"name": "note", "type": ["null", "string", "Item"]},
+	{"name": "tags", "type": {"type": "array", "items": "string"}},
+	{"name": "attrs", "type": {"type": "map", "values": "int"}},
+	{"name": "count", "type": "int", "default": 7}
+]}`
+
+func TestEncodeJSON(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(jsonTestSchema))
+	is.NoErr(err)
+	v := map[string]interface{}{
+		"id":    int64(9007199254740993),
+		"price": float32(0.1),
+		"raw":   []byte{0, 0xff},
+		"kind":  GenericEnum{Symbol: "B", Ordinal: 1},
+		"note":  GenericUnion{Index: 1, Value: "hi"},
+		"tags":  []string{"x"},
+		"attrs": map[string]int32{"a": 1},
+		"count": int32(3),
+	}
+	j, err := EncodeJSON(s, v)
+	is.NoErr(err)
+	is.Equal(string(j), `{"attrs":{"a":1},"count":3,"id":9007199254740993,"kind":"B","note":{"string":"hi"},`+
+		`"price":0.1,"raw":"\u0000ÿ","tags":["x"]}`) // unions are wrapped, bytes are latin1
+
+	var got interface{}
+	is.NoErr(DecodeJSON(s, j, &got))
+	eq, err := Equal(s, got, v)
+	is.NoErr(err)
+	is.True(eq) // round trips
+}
+
+func TestDecodeJSON(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(jsonTestSchema))
+	is.NoErr(err)
+	var m map[string]interface{}
+	is.NoErr(DecodeJSON(s, []byte(`{"id": 1, "price": 2, "raw": "", "kind": "A", "note": null,
+		"tags": [], "attrs": {}}`), &m))
+	is.Equal(m["count"], int32(7))              // missing field takes its default
+	is.Equal(m["note"], GenericUnion{Index: 0}) // null union
+
+	var r map[string]interface{}
+	is.NoErr(DecodeJSON(s, []byte(`{"id": 1, "price": 2, "raw": "", "kind": "A", "tags": [], "attrs": {},
+		"note": {"ns.Item": {"id": 2, "price": 0, "raw": "", "kind": "B", "note": null, "tags": [], "attrs": {}}}}`), &r))
+	is.Equal(r["note"].(GenericUnion).Index, 2) // named branch
+
+	for _, bad := range []string{
+		`{"id": 1.5, "price": 2, "raw": "", "kind": "A", "note": null, "tags": [], "attrs": {}}`,
+		`{"id": 1, "price": 2, "raw": "", "kind": "C", "note": null, "tags": [], "attrs": {}}`,
+		`{"id": 1, "price": 2, "raw": "", "kind": "A", "note": "hi", "tags": [], "attrs": {}}`,
+		`{"id": 1, "price": 2, "raw": "", "kind": "A", "note": {"int": 1}, "tags": [], "attrs": {}}`,
+		`{"id": 1, "price": 2, "raw": "Ā", "kind": "A", "note": null, "tags": [], "attrs": {}}`,
+		`{"price": 2, "raw": "", "kind": "A", "note": null, "tags": [], "attrs": {}}`,
+		`{} {}`,
+	} {
+		is.True(DecodeJSON(s, []byte(bad), &m) != nil) // invalid JSON value
+	}
+	is.True(DecodeJSON(Int, []byte(`2147483648`), new(int32)) != nil) // int overflow
+}
@@ -0,0 +1,285 @@
+package avro
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity is the importance of a lint Diagnostic.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Diagnostic is an issue found by Lint.
+type Diagnostic struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"` // as in Node
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", d.Path, d.Severity, d.Message, d.Rule)
+}
+
+// LintRule describes a rule checked by Lint.
+type LintRule struct {
+	Name     string
+	Doc      string
+	Severity Severity // default severity
+	Max      int      // default limit, for rules which have one
+	check    func(l *linter, n Node, max int) []string
+}
+
+// RuleConfig configures a lint rule.
+type RuleConfig struct {
+	Disabled bool     `json:"disabled,omitempty"`
+	Severity Severity `json:"severity,omitempty"` // overrides the rule's severity
+	Max      int      `json:"max,omitempty"`      // overrides the rule's limit
+}
+
+// LintConfig configures Lint by rule name. Rules not in the config are
+// enabled with their defaults.
+type LintConfig map[string]RuleConfig
+
+var (
+	camelCaseRegex      = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+	pascalCaseRegex     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	upperSnakeCaseRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+)
+
+// LintRules returns the rules checked by Lint.
+func LintRules() []LintRule {
+	return []LintRule{
+		{
+			Name:     "field-doc",
+			Doc:      "fields should have a doc",
+			Severity: SeverityWarning,
+			check: func(l *linter, n Node, max int) []string {
+				if n.Field != nil && n.Field.Doc == "" {
+					return []string{`field has no doc`}
+				}
+				return nil
+			},
+		},
+		{
+			Name:     "name-case",
+			Doc:      "fields should be camelCase, named types PascalCase and enum symbols UPPER_SNAKE_CASE",
+			Severity: SeverityWarning,
+			check:    checkNameCase,
+		},
+		{
+			Name:     "optional-null-first",
+			Doc:      "optional fields should be unions starting with null",
+			Severity: SeverityWarning,
+			check: func(l *linter, n Node, max int) []string {
+				u, ok := n.Schema.(Union)
+				if n.Field == nil || !ok {
+					return nil
+				}
+				for i, s := range u {
+					if s == Null && i > 0 {
+						return []string{`optional field should have null as the first schema of its union`}
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Name:     "field-default",
+			Doc:      "fields should have a default, so readers can add them without breaking backward compatibility",
+			Severity: SeverityWarning,
+			check: func(l *linter, n Node, max int) []string {
+				if n.Field != nil && n.Field.Default == nil {
+					return []string{`field has no default`}
+				}
+				return nil
+			},
+		},
+		{
+			Name:     "enum-default",
+			Doc:      "enums should have a default, so readers can read symbols added later",
+			Severity: SeverityWarning,
+			check: func(l *linter, n Node, max int) []string {
+				if e, ok := n.Schema.(Enum); ok && e.Default == "" {
+					return []string{fmt.Sprintf(`enum "%s" has no default`, e.Fullname())}
+				}
+				return nil
+			},
+		},
+		{
+			Name:     "duplicate-alias",
+			Doc:      "aliases should be unique and differ from other names",
+			Severity: SeverityError,
+			check:    checkDuplicateAliases,
+		},
+		{
+			Name:     "max-depth",
+			Doc:      "records should not be nested deeper than max",
+			Severity: SeverityWarning,
+			Max:      5,
+			check: func(l *linter, n Node, max int) []string {
+				if _, ok := n.Schema.(Record); ok && !n.Seen && l.depth(n.Path) > max {
+					return []string{fmt.Sprintf(`record is nested %d deep, more than %d`, l.depth(n.Path), max)}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Lint checks s against the rules of LintRules, configured by config, and
+// returns the diagnostics ordered by path and rule. It returns an error if
+// config names an unknown rule or severity.
+func Lint(s Schema, config LintConfig) ([]Diagnostic, error) {
+	rules := LintRules()
+	known := map[string]bool{}
+	for _, r := range rules {
+		known[r.Name] = true
+	}
+	for name, c := range config {
+		if !known[name] {
+			return nil, fmt.Errorf(`unknown lint rule "%s"`, name)
+		}
+		switch c.Severity {
+		case "", SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return nil, fmt.Errorf(`unknown severity "%s" for lint rule "%s"`, c.Severity, name)
+		}
+	}
+	l := linter{records: map[string]int{}}
+	var diags []Diagnostic
+	err := Walk(s, func(n Node) error {
+		// A type first met through a reference, such as one declared in
+		// another file of a SchemaSet, is linted there.
+		if r, ok := n.Schema.(Reference); ok && !n.Seen {
+			n.Schema = resolve(r)
+		}
+		if _, ok := n.Schema.(Record); ok && !n.Seen {
+			l.records[n.Path] = l.depth(n.Path) + 1
+		}
+		for _, r := range rules {
+			c := config[r.Name]
+			if c.Disabled {
+				continue
+			}
+			severity, max := r.Severity, r.Max
+			if c.Severity != "" {
+				severity = c.Severity
+			}
+			if c.Max != 0 {
+				max = c.Max
+			}
+			for _, msg := range r.check(&l, n, max) {
+				diags = append(diags, Diagnostic{Rule: r.Name, Severity: severity, Path: n.Path, Message: msg})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Path != diags[j].Path {
+			return pathLess(diags[i].Path, diags[j].Path)
+		}
+		return diags[i].Rule < diags[j].Rule
+	})
+	return diags, nil
+}
+
+// linter tracks the nesting of records while walking.
+type linter struct {
+	records map[string]int // depth of the records walked, by path
+}
+
+// depth returns the number of records enclosing path, including any record
+// at path itself once it has been walked.
+func (l *linter) depth(path string) int {
+	best, bestLen := 0, -1
+	for p, d := range l.records {
+		if len(p) > bestLen && strings.HasPrefix(path, p) && (len(p) == len(path) || strings.IndexByte(".[{", path[len(p)]) >= 0) {
+			best, bestLen = d, len(p)
+		}
+	}
+	return best
+}
+
+func checkNameCase(l *linter, n Node, max int) []string {
+	var msgs []string
+	if n.Field != nil && !camelCaseRegex.MatchString(n.Field.Name) {
+		msgs = append(msgs, fmt.Sprintf(`field "%s" is not camelCase`, n.Field.Name))
+	}
+	if n.Seen {
+		return msgs
+	}
+	switch s := n.Schema.(type) {
+	case Record, Enum, Fixed:
+		nf := s.(NamedSchema).GetNameFields()
+		if !pascalCaseRegex.MatchString(nf.Name) {
+			msgs = append(msgs, fmt.Sprintf(`type "%s" is not PascalCase`, nf.Name))
+		}
+		if e, ok := s.(Enum); ok {
+			for _, sym := range e.Symbols {
+				if !upperSnakeCaseRegex.MatchString(sym) {
+					msgs = append(msgs, fmt.Sprintf(`symbol "%s" is not UPPER_SNAKE_CASE`, sym))
+				}
+			}
+		}
+	}
+	return msgs
+}
+
+func checkDuplicateAliases(l *linter, n Node, max int) []string {
+	var msgs []string
+	if n.Field != nil {
+		msgs = append(msgs, duplicateAliases(`field "`+n.Field.Name+`"`, n.Field.Aliases, n.Field.Name)...)
+	}
+	if n.Seen {
+		return msgs
+	}
+	switch s := n.Schema.(type) {
+	case Record:
+		// Field aliases must not clash with other fields.
+		names := map[string]string{}
+		for _, f := range s.Fields {
+			names[f.Name] = f.Name
+		}
+		for _, f := range s.Fields {
+			for _, a := range f.Aliases {
+				if other, ok := names[a]; ok && other != f.Name {
+					msgs = append(msgs, fmt.Sprintf(`alias "%s" of field "%s" is also used by field "%s"`, a, f.Name, other))
+				}
+				names[a] = f.Name
+			}
+		}
+		msgs = append(msgs, duplicateAliases(`record "`+s.Fullname()+`"`, s.Aliases, s.Name, s.Fullname())...)
+	case Enum, Fixed:
+		nf := s.(NamedSchema).GetNameFields()
+		msgs = append(msgs, duplicateAliases(fmt.Sprintf(`%s "%s"`, s.Type(), nf.Fullname()), nf.Aliases, nf.Name, nf.Fullname())...)
+	}
+	return msgs
+}
+
+// duplicateAliases reports aliases of what which repeat or equal one of its
+// names.
+func duplicateAliases(what string, aliases []string, names ...string) []string {
+	var msgs []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, a := range aliases {
+		if seen[a] {
+			msgs = append(msgs, fmt.Sprintf(`%s has duplicate alias "%s"`, what, a))
+		}
+		seen[a] = true
+	}
+	return msgs
+}
@@ -0,0 +1,90 @@
+package avro
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const lintTestSchema = `{"type": "record", "name": "ns.user_record", "aliases": ["ns.user_record"], "fields": [
+	{"name": "id", "type": "long", "doc": "id", "default": 0},
+	{"name": "Email", "type": ["string", "null"], "doc": "email", "default": ""},
+	{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["active"]}, "doc": "status", "default": "active"},
+	{"name": "old", "type": "int", "aliases": ["id", "prev", "prev"], "doc": "old", "default": 1},
+	{"name": "a", "type": {"type": "record", "name": "A", "fields": [
+		{"name": "b", "type": {"type": "record", "name": "B", "fields": []}, "doc": "b", "default": {}}
+	]}}
+]}`
+
+func TestLint(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(lintTestSchema))
+	is.NoErr(err)
+	diags, err := Lint(s, LintConfig{"max-depth": {Max: 2}})
+	is.NoErr(err)
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Path+" "+d.Rule)
+	}
+	is.Equal(got, []string{
+		"$ duplicate-alias",
+		"$ duplicate-alias",
+		"$ name-case",
+		"$.Email name-case",
+		"$.Email optional-null-first",
+		"$.a field-default",
+		"$.a field-doc",
+		"$.a.b max-depth",
+		"$.old duplicate-alias",
+		"$.status enum-default",
+		"$.status name-case",
+	}) // ordered by path and rule
+	is.Equal(diags[0].Severity, SeverityError)
+
+	b, err := json.Marshal(diags[3])
+	is.NoErr(err)
+	is.Equal(string(b), `{"rule":"name-case","severity":"warning","path":"$.Email","message":"field \"Email\" is not camelCase"}`) // machine-readable
+
+	diags, err = Lint(s, LintConfig{
+		"name-case":           {Disabled: true},
+		"duplicate-alias":     {Disabled: true},
+		"optional-null-first": {Disabled: true},
+		"field-default":       {Disabled: true},
+		"field-doc":           {Disabled: true},
+		"enum-default":        {Severity: SeverityError},
+	})
+	is.NoErr(err)
+	is.Equal(len(diags), 1) // rules disabled, max-depth within default
+	is.Equal(diags[0].Severity, SeverityError)
+
+	_, err = Lint(s, LintConfig{"nope": {}})
+	is.True(err != nil) // unknown rule
+	_, err = Lint(s, LintConfig{"field-doc": {Severity: "fatal"}})
+	is.True(err != nil) // unknown severity
+	is.Equal(len(LintRules()), 7)
+}
+
+func TestLint_references(t *testing.T) {
+	is := is.New(t)
+
+	ref := NewReference("ns.bad_enum")
+	is.NoErr(ref.Bind(Enum{NameFields: NameFields{Name: "bad_enum", Namespace: "ns", Aliases: []string{"bad_enum"}}, Symbols: []string{"a"}}))
+	r := Record{NameFields: NameFields{Name: "R"}, Fields: []Field{
+		{Name: "x", Type: ref, Doc: "x", Default: new(interface{})},
+		{Name: "y", Type: ref, Doc: "y", Default: new(interface{})},
+	}}
+	diags, err := Lint(r, nil)
+	is.NoErr(err)
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Path+" "+d.Rule)
+	}
+	is.Equal(got, []string{
+		"$.x duplicate-alias",
+		"$.x enum-default",
+		"$.x name-case",
+		"$.x name-case",
+	}) // the type behind the first reference is linted once
+}
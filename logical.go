@@ -0,0 +1,202 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Logical type names defined by the specification.
+const (
+	LogicalDecimal         = "decimal"
+	LogicalUUID            = "uuid"
+	LogicalDate            = "date"
+	LogicalTimeMillis      = "time-millis"
+	LogicalTimeMicros      = "time-micros"
+	LogicalTimestampMillis = "timestamp-millis"
+	LogicalTimestampMicros = "timestamp-micros"
+)
+
+// Logical represents a logical type, which annotates an underlying primitive
+// or fixed schema, such as {"type": "long", "logicalType": "timestamp-millis"}.
+//
+// Dates and timestamps may be given as time.Time, and times of day as
+// time.Duration. Other values are handled by the underlying schema.
+type Logical struct {
+	LogicalType string
+	Schema      Schema // underlying schema
+	Precision   int    // decimal only
+	Scale       int    // decimal only
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Type returns the type of the underlying schema.
+func (l Logical) Type() string {
+	if l.Schema == nil {
+		return "logical"
+	}
+	return l.Schema.Type()
+}
+
+// Valid checks that the underlying schema is valid and is permitted by the
+// logical type.
+func (l Logical) Valid() error {
+	if l.Schema == nil {
+		return errors.New(`logical type must have an underlying schema`)
+	}
+	if err := l.Schema.Valid(); err != nil {
+		return err
+	}
+	var want []string
+	switch l.LogicalType {
+	case LogicalDecimal:
+		want = []string{"bytes", "fixed"}
+		if l.Precision <= 0 {
+			return fmt.Errorf(`decimal precision %d must be positive`, l.Precision)
+		}
+		if l.Scale < 0 || l.Scale > l.Precision {
+			return fmt.Errorf(`decimal scale %d must be between 0 and the precision`, l.Scale)
+		}
+	case LogicalUUID:
+		want = []string{"string"}
+	case LogicalDate, LogicalTimeMillis:
+		want = []string{"int"}
+	case LogicalTimeMicros, LogicalTimestampMillis, LogicalTimestampMicros:
+		want = []string{"long"}
+	default:
+		return fmt.Errorf(`"%s" is not a supported logical type`, l.LogicalType)
+	}
+	for _, t := range want {
+		if l.Schema.Type() == t {
+			return nil
+		}
+	}
+	return fmt.Errorf(`logical type "%s" cannot annotate "%s"`, l.LogicalType, l.Schema.Type())
+}
+
+// Validate checks if value conforms to the logical type.
+func (l Logical) Validate(v interface{}) error {
+	if err := l.Valid(); err != nil {
+		return fmt.Errorf(`validation aborted, logical schema is invalid: %s`, err)
+	}
+	rv := indirect(reflect.ValueOf(v))
+	if rv.IsValid() && (rv.Type() == timeType || rv.Type() == durationType) {
+		_, err := l.fromTime(rv)
+		return err
+	}
+	return l.Schema.Validate(v)
+}
+
+// timeBased reports whether the logical type is a date, time or timestamp.
+func (l Logical) timeBased() bool {
+	switch l.LogicalType {
+	case LogicalDate, LogicalTimeMillis, LogicalTimeMicros, LogicalTimestampMillis, LogicalTimestampMicros:
+		return true
+	}
+	return false
+}
+
+// fromTime converts a time.Time or time.Duration to the underlying integer.
+func (l Logical) fromTime(rv reflect.Value) (int64, error) {
+	if rv.Type() == timeType {
+		t := rv.Interface().(time.Time)
+		switch l.LogicalType {
+		case LogicalDate:
+			days := t.Unix() / 86400
+			if t.Unix() < 0 && t.Unix()%86400 != 0 {
+				days--
+			}
+			return days, nil
+		case LogicalTimestampMillis:
+			return t.UnixMilli(), nil
+		case LogicalTimestampMicros:
+			return t.UnixMicro(), nil
+		}
+	} else {
+		d := time.Duration(rv.Int())
+		switch l.LogicalType {
+		case LogicalTimeMillis:
+			return int64(d / time.Millisecond), nil
+		case LogicalTimeMicros:
+			return int64(d / time.Microsecond), nil
+		}
+	}
+	return 0, fmt.Errorf(`value of type "%s" is not a valid "%s"`, rv.Type(), l.LogicalType)
+}
+
+// toTime stores the underlying integer n in a time.Time or time.Duration.
+func (l Logical) toTime(n int64, rv reflect.Value) error {
+	switch {
+	case rv.Type() == timeType && l.LogicalType == LogicalDate:
+		rv.Set(reflect.ValueOf(time.Unix(n*86400, 0).UTC()))
+	case rv.Type() == timeType && l.LogicalType == LogicalTimestampMillis:
+		rv.Set(reflect.ValueOf(time.UnixMilli(n).UTC()))
+	case rv.Type() == timeType && l.LogicalType == LogicalTimestampMicros:
+		rv.Set(reflect.ValueOf(time.UnixMicro(n).UTC()))
+	case rv.Type() == durationType && l.LogicalType == LogicalTimeMillis:
+		rv.SetInt(n * int64(time.Millisecond))
+	case rv.Type() == durationType && l.LogicalType == LogicalTimeMicros:
+		if n > math.MaxInt64/int64(time.Microsecond) {
+			return fmt.Errorf(`value %d overflows "%s"`, n, rv.Type())
+		}
+		rv.SetInt(n * int64(time.Microsecond))
+	default:
+		return fmt.Errorf(`cannot decode "%s" into value of type "%s"`, l.LogicalType, rv.Type())
+	}
+	return nil
+}
+
+// MarshalJSON adds the "logicalType" attribute to the underlying schema.
+func (l Logical) MarshalJSON() ([]byte, error) {
+	if err := l.Valid(); err != nil {
+		return nil, err
+	}
+	underlying, err := json.Marshal(l.Schema)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(underlying, &raw); err != nil {
+		// Primitives marshal as a plain string.
+		raw = map[string]interface{}{"type": l.Schema.Type()}
+	}
+	raw["logicalType"] = l.LogicalType
+	if l.LogicalType == LogicalDecimal {
+		raw["precision"] = l.Precision
+		if l.Scale != 0 {
+			raw["scale"] = l.Scale
+		}
+	}
+	return json.Marshal(raw)
+}
+
+// unmarshalLogical returns the Logical for a schema object with a
+// "logicalType" attribute. Unknown or invalid logical types are ignored as the
+// specification requires, returning the underlying schema.
+func unmarshalLogical(spec []byte, underlying Schema) Schema {
+	var raw struct {
+		LogicalType string `json:"logicalType"`
+		Precision   int    `json:"precision"`
+		Scale       int    `json:"scale"`
+	}
+	if err := json.Unmarshal(spec, &raw); err != nil {
+		return underlying
+	}
+	l := Logical{
+		LogicalType: raw.LogicalType,
+		Schema:      underlying,
+		Precision:   raw.Precision,
+		Scale:       raw.Scale,
+	}
+	if l.Valid() != nil {
+		return underlying
+	}
+	return l
+}
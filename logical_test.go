@@ -0,0 +1,59 @@
+package avro
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestLogical_Valid(t *testing.T) {
+	is := is.New(t)
+
+	is.NoErr(Logical{LogicalType: LogicalDate, Schema: Int}.Valid())
+	is.NoErr(Logical{LogicalType: LogicalUUID, Schema: String}.Valid())
+	is.NoErr(Logical{LogicalType: LogicalDecimal, Schema: Bytes, Precision: 4, Scale: 2}.Valid())
+	is.True(Logical{LogicalType: LogicalDate, Schema: Long}.Valid() != nil)                             // wrong underlying type
+	is.True(Logical{LogicalType: LogicalDecimal, Schema: Bytes}.Valid() != nil)                         // missing precision
+	is.True(Logical{LogicalType: LogicalDecimal, Schema: Bytes, Precision: 2, Scale: 3}.Valid() != nil) // scale above precision
+	is.True(Logical{LogicalType: "nope", Schema: Int}.Valid() != nil)                                   // unknown logical type
+}
+
+func TestLogical_time(t *testing.T) {
+	is := is.New(t)
+
+	date := Logical{LogicalType: LogicalDate, Schema: Int}
+	day := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	is.NoErr(date.Validate(day))
+	b, err := Encode(date, day)
+	is.NoErr(err)
+	var got time.Time
+	is.NoErr(Decode(date, b, &got))
+	is.True(got.Equal(day)) // date round trips
+	var n int32
+	is.NoErr(Decode(date, b, &n))
+	is.Equal(n, int32(18263)) // days since the epoch
+
+	tm := Logical{LogicalType: LogicalTimeMillis, Schema: Int}
+	b, err = Encode(tm, 90*time.Second)
+	is.NoErr(err)
+	var d time.Duration
+	is.NoErr(Decode(tm, b, &d))
+	is.Equal(d, 90*time.Second)      // time of day round trips
+	is.True(tm.Validate(day) != nil) // time.Time is not a time of day
+}
+
+func TestLogical_JSON(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(`{"type": "long", "logicalType": "timestamp-micros"}`))
+	is.NoErr(err)
+	is.Equal(s, Logical{LogicalType: LogicalTimestampMicros, Schema: Long})
+	b, err := s.(Logical).MarshalJSON()
+	is.NoErr(err)
+	is.Equal(string(b), `{"logicalType":"timestamp-micros","type":"long"}`)
+
+	s, err = SchemaUnmarshalJSON([]byte(`{"type": "string", "logicalType": "date"}`))
+	is.NoErr(err)
+	is.Equal(s, String) // invalid logical types are ignored
+}
@@ -20,14 +20,14 @@ func (m Map) Valid() error {
 	if m.Values == nil {
 		return ErrValidation{
 			Children: map[string]error{
-				"values": errors.New("cannot be nil"),
+				".values": errors.New("cannot be nil"),
 			},
 		}
 	}
 	if err := m.Values.Valid(); err != nil {
 		return ErrValidation{
 			Children: map[string]error{
-				"values": err,
+				".values": err,
 			},
 		}
 	}
@@ -60,7 +60,7 @@ func (m Map) Validate(v interface{}) error {
 		errs := map[string]error{}
 		for k, val := range msi {
 			if err := m.Values.Validate(val); err != nil {
-				errs[k] = err
+				errs[keyPath(k)] = err
 			}
 		}
 		if len(errs) > 0 {
@@ -85,7 +85,7 @@ func (m Map) Validate(v interface{}) error {
 	for _, k := range rv.MapKeys() {
 		val := rv.MapIndex(k).Interface()
 		if err := m.Values.Validate(val); err != nil {
-			errs[k.String()] = err
+			errs[keyPath(k.String())] = err
 		}
 	}
 	if len(errs) > 0 {
@@ -0,0 +1,231 @@
+package avro
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// ParallelOption configures Reader.ReadParallel.
+type ParallelOption func(*parallelOptions)
+
+type parallelOptions struct {
+	workers   int
+	maxBlocks int
+	unordered bool
+}
+
+// WithWorkers sets the number of blocks decompressed and decoded at once,
+// runtime.GOMAXPROCS by default.
+func WithWorkers(n int) ParallelOption {
+	return func(o *parallelOptions) { o.workers = n }
+}
+
+// WithMaxBlocks bounds the number of blocks held in memory at once, read but
+// not yet passed on, to n. It is twice the number of workers by default.
+func WithMaxBlocks(n int) ParallelOption {
+	return func(o *parallelOptions) { o.maxBlocks = n }
+}
+
+// WithUnordered passes on the values of each block as soon as it is decoded,
+// rather than in the order of the file.
+func WithUnordered() ParallelOption {
+	return func(o *parallelOptions) { o.unordered = true }
+}
+
+// ReadParallel reads the blocks left in r and decompresses and decodes them
+// on several goroutines. It calls fn with each value, one at a time, in the
+// order of the file unless WithUnordered is given. Values are decoded into
+// the pointers returned by newValue, which fn receives, or as generic values
+// if newValue is nil. ReadParallel returns the first error of reading,
+// decoding or fn, or the error of ctx once it is done. Blocks which cannot
+// be decoded are skipped after SkipBadBlocks, without passing on any of their
+// values.
+func (r *Reader) ReadParallel(ctx context.Context, newValue func() interface{}, fn func(v interface{}) error, opts ...ParallelOption) error {
+	o := parallelOptions{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.workers < 1 {
+		o.workers = 1
+	}
+	if o.maxBlocks < 1 {
+		o.maxBlocks = 2 * o.workers
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	// Blocks skipped while reading are reported through results, so that
+	// report is only called here, in order.
+	report := r.bad
+	var resynced []BadBlock
+	if report != nil {
+		r.bad = func(b BadBlock) { resynced = append(resynced, b) }
+		defer func() { r.bad = report }()
+	}
+
+	type job struct {
+		seq        int
+		block      RawBlock
+		start, end int64 // offsets of the block
+	}
+	// A token is taken for each block read and given back once its values
+	// are passed on, so results never wait to be sent.
+	tokens := make(chan struct{}, o.maxBlocks)
+	jobs := make(chan job)
+	results := make(chan blockResult, o.maxBlocks)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for seq := 0; ; seq++ {
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			b, err := r.ReadRawBlock()
+			for i := range resynced {
+				results <- blockResult{seq: seq, bad: &resynced[i]}
+				seq++
+				select {
+				case tokens <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			resynced = nil
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				results <- blockResult{seq: seq, err: err}
+				return
+			}
+			select {
+			case jobs <- job{seq, b, r.blockStart, r.blockEnd}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var workers sync.WaitGroup
+	for i := 0; i < o.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for j := range jobs {
+				values, err := r.decodeBlock(j.block, newValue)
+				res := blockResult{seq: j.seq, values: values, err: err}
+				if err != nil && r.bad != nil {
+					res.bad = &BadBlock{Offset: j.start, Length: j.end - j.start, Count: j.block.Count, Err: err}
+				} else if err != nil {
+					res.err = fmt.Errorf(`block %d: %s`, j.seq, err)
+				}
+				results <- res
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		workers.Wait()
+		close(results)
+	}()
+	// Stop the goroutines and wait for them before returning.
+	defer wg.Wait()
+	defer cancel()
+
+	pending := map[int]blockResult{}
+	next := 0
+	for {
+		var res blockResult
+		var ok bool
+		select {
+		case res, ok = <-results:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if !ok {
+			return ctx.Err()
+		}
+		if o.unordered {
+			if err := passOn(res, fn, report); err != nil {
+				return err
+			}
+			<-tokens
+			continue
+		}
+		pending[res.seq] = res
+		for res, ok := pending[next]; ok; res, ok = pending[next] {
+			delete(pending, next)
+			next++
+			if err := passOn(res, fn, report); err != nil {
+				return err
+			}
+			<-tokens
+		}
+	}
+}
+
+// blockResult holds the values decoded from the block at seq, or the error
+// reading or decoding it.
+type blockResult struct {
+	seq    int
+	values []interface{}
+	err    error
+	bad    *BadBlock // set instead of err after SkipBadBlocks
+}
+
+// passOn calls fn with the values of res, or report with its bad block, or
+// returns its error.
+func passOn(res blockResult, fn func(v interface{}) error, report func(BadBlock)) error {
+	if res.bad != nil {
+		report(*res.bad)
+		return nil
+	}
+	if res.err != nil {
+		return res.err
+	}
+	for _, v := range res.values {
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeBlock decompresses b and decodes its values, as Decode does.
+func (r *Reader) decodeBlock(b RawBlock, newValue func() interface{}) ([]interface{}, error) {
+	data, err := decompressBlock(r.codec, b.Data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCount(r.schema, b.Count, len(data)); err != nil {
+		return nil, err
+	}
+	d := decoder{buf: data, projection: r.projection}
+	var values []interface{}
+	for i := int64(0); i < b.Count; i++ {
+		var v interface{}
+		rv := reflect.ValueOf(&v).Elem()
+		if newValue != nil {
+			v = newValue()
+			if rv = reflect.ValueOf(v); rv.Kind() != reflect.Ptr || rv.IsNil() {
+				return nil, fmt.Errorf(`cannot decode into non-pointer or nil value of type "%T"`, v)
+			}
+			rv = rv.Elem()
+		}
+		if err := d.decode(r.schema, rv); err != nil {
+			return nil, fmt.Errorf(`value %d: %s`, i, err)
+		}
+		values = append(values, v)
+	}
+	if rest := len(d.buf) - d.pos; rest > 0 {
+		return nil, fmt.Errorf(`%d bytes remain after the values of the block`, rest)
+	}
+	return values, nil
+}
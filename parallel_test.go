@@ -0,0 +1,132 @@
+package avro
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReader_ReadParallel(t *testing.T) {
+	is := is.New(t)
+
+	var b bytes.Buffer
+	w, err := NewWriter(&b, containerSchema, WithCodec(CodecDeflate), WithBlockSize(20))
+	is.NoErr(err)
+	for i := int64(0); i < 100; i++ {
+		is.NoErr(w.Append(containerItem{ID: i, Name: "item"}))
+	}
+	is.NoErr(w.Close())
+	data := b.Bytes()
+	newItem := func() interface{} { return new(containerItem) }
+
+	r, err := NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	var ids []int64
+	is.NoErr(r.ReadParallel(context.Background(), newItem, func(v interface{}) error {
+		ids = append(ids, v.(*containerItem).ID)
+		return nil
+	}, WithWorkers(4), WithMaxBlocks(3)))
+	is.Equal(len(ids), 100)
+	for i, id := range ids {
+		is.Equal(id, int64(i)) // in order
+	}
+
+	r, err = NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	ids = nil
+	is.NoErr(r.ReadParallel(context.Background(), nil, func(v interface{}) error {
+		id, _ := v.(*GenericRecord).Get("id")
+		ids = append(ids, id.(int64))
+		return nil
+	}, WithUnordered()))
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	is.Equal(len(ids), 100)
+	is.Equal(ids[99], int64(99)) // every generic value
+
+	stop := errors.New("stop")
+	r, err = NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	n := 0
+	err = r.ReadParallel(context.Background(), newItem, func(v interface{}) error {
+		if n++; n == 10 {
+			return stop
+		}
+		return nil
+	}, WithWorkers(2))
+	is.Equal(err, stop) // fn error stops reading
+	is.Equal(n, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err = NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	err = r.ReadParallel(ctx, newItem, func(v interface{}) error {
+		cancel()
+		return nil
+	})
+	is.Equal(err, context.Canceled) // stops once ctx is done
+
+	corrupt := append([]byte{}, data...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	r, err = NewReader(bytes.NewReader(corrupt))
+	is.NoErr(err)
+	n = 0
+	err = r.ReadParallel(context.Background(), newItem, func(v interface{}) error {
+		n++
+		return nil
+	})
+	is.True(err != nil) // bad sync marker
+	is.True(n > 0)      // after the values before it
+}
+
+func BenchmarkReader_ReadParallel(b *testing.B) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, containerSchema, WithCodec(CodecDeflate))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := int64(0); i < 100000; i++ {
+		if err := w.Append(containerItem{ID: i, Name: "item"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r, err := NewReader(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			b.Fatal(err)
+		}
+		err = r.ReadParallel(context.Background(), func() interface{} { return new(containerItem) },
+			func(v interface{}) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReader_ReadParallel_skipBadBlocks(t *testing.T) {
+	is := is.New(t)
+
+	data, offsets := blocksFile(t, CodecNull, 6)
+	data[offsets[1]] = 0xff   // framing of value 1, skipped while reading
+	data[offsets[2]+3] = 0x7f // value 2, skipped while decoding
+	data[offsets[4]-1] ^= 1   // sync marker of value 3
+
+	for i := 0; i < 20; i++ {
+		r, err := NewReader(bytes.NewReader(data))
+		is.NoErr(err)
+		var got []int64 // ids read, and offsets of bad blocks negated
+		r.SkipBadBlocks(func(b BadBlock) { got = append(got, -b.Offset) })
+		is.NoErr(r.ReadParallel(context.Background(), func() interface{} { return new(containerItem) }, func(v interface{}) error {
+			got = append(got, v.(*containerItem).ID)
+			return nil
+		}, WithWorkers(4), WithMaxBlocks(2)))
+		is.Equal(got, []int64{0, -int64(offsets[1]), -int64(offsets[2]), -int64(offsets[3]), 5}) // reports in order, with the values
+	}
+}
@@ -1,6 +1,10 @@
 package avro
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
 
 const (
 	Null    Primitive = "null"
@@ -40,61 +44,52 @@ func (p Primitive) Valid() error {
 	return fmt.Errorf(`"%s" is not a valid primitive type`, p)
 }
 
+// Validate checks that v, or what it points to, is a value Encode writes as p:
+// any integer kind in range for Int and Long, either float kind for Float and
+// Double, and a string or byte slice for Bytes and String. Defined types of
+// these kinds are valid too.
 func (p Primitive) Validate(v interface{}) error {
-	if p != Null && v == nil {
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		if p == Null {
+			return nil
+		}
 		return p.errInvalid()
 	}
-	if ptrIface, ok := v.(*interface{}); ok {
-		v = *ptrIface
-	}
 	switch p {
-	case Null:
-		if v == nil {
-			return nil
-		}
 	case Boolean:
-		switch v.(type) {
-		case bool, *bool:
+		if rv.Kind() == reflect.Bool {
 			return nil
 		}
-	case Int:
-		switch v.(type) {
-		case int, int32, int64, uint, uint32, uint64,
-			*int, *int32, *int64, *uint, *uint32, *uint64:
+	case Int, Long:
+		if n, ok := intValue(rv); ok && (p == Long || n >= math.MinInt32 && n <= math.MaxInt32) {
 			return nil
 		}
-	case Long:
-		switch v.(type) {
-		case int64, uint64, *int64, *uint64:
+	case Float, Double:
+		if rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64 {
 			return nil
-		case int, *int:
-			if uint64(^uint(0)) == ^uint64(0) {
-				// int is 64-bit
-				return nil
-			}
 		}
-	case Float:
-		switch v.(type) {
-		case float32, *float32:
+	case Bytes, String:
+		if rv.Kind() == reflect.String || rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
 			return nil
 		}
+	}
+	return p.errInvalid()
+}
+
+// exact reports whether rv, which p validates, has the kind p decodes to.
+func (p Primitive) exact(rv reflect.Value) bool {
+	switch p {
+	case Float:
+		return rv.Kind() == reflect.Float32
 	case Double:
-		switch v.(type) {
-		case float64, *float64:
-			return nil
-		}
+		return rv.Kind() == reflect.Float64
 	case Bytes:
-		switch v.(type) {
-		case []byte, *[]byte:
-			return nil
-		}
+		return rv.Kind() == reflect.Slice
 	case String:
-		switch v.(type) {
-		case string, *string:
-			return nil
-		}
+		return rv.Kind() == reflect.String
 	}
-	return p.errInvalid()
+	return true
 }
 
 func (p Primitive) errInvalid() error {
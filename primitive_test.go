@@ -47,15 +47,21 @@ func TestPrimitive_Validate(t *testing.T) {
 		{Int, 1, false},
 		{Int, 1.5235, true},
 		{Int, nil, true},
+		{Int, int8(1), false},
+		{Int, uint16(1), false},
+		{Int, int64(1 << 40), true},
+		{Int, new(int16), false},
 		{Long, int64(0), false},
 		{Long, int64(1), false},
-		{Long, int(1), uint64(^uint(0)) != ^uint64(0)}, // Only error on non-64-bit
+		{Long, int(1), false},
 		{Long, float32(1.5), true},
-		{Long, uint8(0x0), true},
+		{Long, uint8(0x0), false},
+		{Long, uint32(1), false},
+		{Long, uint64(1 << 63), true},
 		{Long, nil, true},
 		{Float, float32(0.0), false},
 		{Float, float32(1.5), false},
-		{Float, float64(1.5), true},
+		{Float, float64(1.5), false},
 		{Float, int(0), true},
 		{Float, uint8(0x0), true},
 		{Float, "hello", true},
@@ -63,6 +69,7 @@ func TestPrimitive_Validate(t *testing.T) {
 		{Float, nil, true},
 		{Double, float64(0.0), false},
 		{Double, float64(1.5), false},
+		{Double, float32(1.5), false},
 		{Double, 0, true},
 		{Double, 0x0, true},
 		{Double, "hello", true},
@@ -70,12 +77,14 @@ func TestPrimitive_Validate(t *testing.T) {
 		{Double, nil, true},
 		{Bytes, []byte{0x0}, false},
 		{Bytes, []byte{}, false},
-		{Bytes, "hello", true},
+		{Bytes, "hello", false},
 		{Bytes, 0, true},
 		{Bytes, nil, true},
 		{String, "", false},
 		{String, "hello", false},
 		{String, 0, true},
+		{String, []byte("hello"), false},
+		{String, inferStatus("a"), false},
 		{String, nil, true},
 	}
 
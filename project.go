@@ -0,0 +1,242 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Project returns a reader schema for data written with schema s, which has
+// only the record fields at paths. A path is a dot-separated list of field
+// names, such as "user.address.city", and passes through arrays, maps and
+// unions to the records inside them. Selecting a field keeps all of it, and
+// the records it encloses keep the fields selected by any path, in their
+// original order. Use DecodeProjected or Reader.Project to decode data with
+// the result, skipping the fields not selected.
+func Project(s Schema, paths ...string) (Schema, error) {
+	if s == nil {
+		return nil, errors.New(`cannot project nil schema`)
+	}
+	if len(paths) == 0 {
+		return nil, errors.New(`no paths to project`)
+	}
+	p := projector{selected: map[string]map[string]bool{}, full: map[string]bool{}}
+	for _, path := range paths {
+		if !p.selectPath(s, strings.Split(path, ".")) {
+			return nil, fmt.Errorf(`no field at path "%s"`, path)
+		}
+	}
+	out, err := Transform(s, func(n Node) (Schema, error) {
+		r, ok := n.Schema.(Record)
+		if !ok || p.full[r.Fullname()] {
+			return n.Schema, nil
+		}
+		fields := []Field{}
+		for _, f := range r.Fields {
+			if p.selected[r.Fullname()][f.Name] {
+				fields = append(fields, f)
+			}
+		}
+		r.Fields = fields
+		return r, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Named types may now be first used where they used to be referenced.
+	return Standalone(out)
+}
+
+type projector struct {
+	selected map[string]map[string]bool // fields selected by record full name
+	full     map[string]bool            // records selected with all their fields
+}
+
+// selectPath selects the fields named by names in the records of s, and
+// reports whether any record has them.
+func (p projector) selectPath(s Schema, names []string) bool {
+	found := false
+	for _, r := range recordsIn(s) {
+		f, ok := r.GetField(names[0])
+		if !ok {
+			continue
+		}
+		if len(names) == 1 {
+			p.selectAll(f.Type)
+		} else if !p.selectPath(f.Type, names[1:]) {
+			continue
+		}
+		if p.selected[r.Fullname()] == nil {
+			p.selected[r.Fullname()] = map[string]bool{}
+		}
+		p.selected[r.Fullname()][f.Name] = true
+		found = true
+	}
+	return found
+}
+
+// selectAll selects every field of the records inside s.
+func (p projector) selectAll(s Schema) {
+	_ = Walk(s, func(n Node) error {
+		if r, ok := resolve(n.Schema).(Record); ok {
+			p.full[r.Fullname()] = true
+		}
+		return nil
+	})
+}
+
+// recordsIn returns the records which s is, or holds as array items, map
+// values or union branches.
+func recordsIn(s Schema) []Record {
+	switch s := resolve(s).(type) {
+	case Record:
+		return []Record{s}
+	case Array:
+		return recordsIn(s.Items)
+	case Map:
+		return recordsIn(s.Values)
+	case Union:
+		var rs []Record
+		for _, b := range s {
+			rs = append(rs, recordsIn(b)...)
+		}
+		return rs
+	}
+	return nil
+}
+
+// DecodeProjected reads the Avro binary encoding of a value with schema
+// writer from data, and stores the fields of schema reader in the value
+// pointed to by v, as Decode does. Each record of the reader, such as those
+// of a projection made by Project, is read from the writer record with its
+// full name or one of its aliases: fields are matched by name or reader field
+// alias, fields the reader does not have are skipped without being decoded,
+// and fields the writer does not have take their defaults. Primitive fields
+// are promoted to the reader type as the specification allows, such as int
+// to long or string to bytes.
+func DecodeProjected(writer, reader Schema, data []byte, v interface{}) error {
+	if writer == nil || reader == nil {
+		return errors.New(`cannot decode with nil schema`)
+	}
+	p, err := newProjection(writer, reader)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf(`cannot decode into non-pointer or nil value of type "%T"`, v)
+	}
+	d := decoder{buf: data, projection: p}
+	if err := d.decode(writer, rv.Elem()); err != nil {
+		return err
+	}
+	if rest := len(d.buf) - d.pos; rest > 0 {
+		return fmt.Errorf(`%d bytes remain after decoding`, rest)
+	}
+	return nil
+}
+
+// projection maps the full names of writer records to the reader records
+// read from them.
+type projection map[string]projectedRecord
+
+type projectedRecord struct {
+	reader   Record
+	index    []int          // reader field index by writer field index, or -1 to skip
+	promoted []Primitive    // reader type of writer fields it promotes, by writer field index
+	defaults []fieldDefault // reader fields missing from the writer
+}
+
+// fieldDefault is the binary encoding of the default of a reader field.
+type fieldDefault struct {
+	index int
+	data  []byte
+}
+
+// newProjection matches each record of reader with the writer record of the
+// same full name or one of its aliases, and their fields by name or reader
+// field alias. Matched fields have the same type, or primitive types the
+// specification promotes, such as int to long.
+func newProjection(writer, reader Schema) (projection, error) {
+	records := map[string]Record{}
+	if err := Walk(writer, func(n Node) error {
+		if r, ok := n.Schema.(Record); ok {
+			records[r.Fullname()] = r
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	p := projection{}
+	// Fields the writer does not have take their defaults, so the records
+	// inside them are not read from the writer.
+	defaulted := map[string]bool{}
+	err := Walk(reader, func(n Node) error {
+		if defaulted[n.Path] {
+			return SkipChildren
+		}
+		r, ok := n.Schema.(Record)
+		if !ok {
+			return nil
+		}
+		w, ok := records[r.Fullname()]
+		for _, a := range r.FullAliases() {
+			if !ok {
+				w, ok = records[a]
+			}
+		}
+		if !ok {
+			return fmt.Errorf(`%s: record "%s" is not in the writer schema`, n.Path, r.Fullname())
+		}
+		pr := projectedRecord{reader: r, index: make([]int, len(w.Fields))}
+		for i := range pr.index {
+			pr.index[i] = -1
+		}
+		for j, rf := range r.Fields {
+			if i := writerFieldIndex(rf, w); i >= 0 {
+				f := w.Fields[i]
+				if p, ok := promotedTo(f.Type, rf.Type); ok {
+					if pr.promoted == nil {
+						pr.promoted = make([]Primitive, len(w.Fields))
+					}
+					pr.promoted[i] = p
+				} else if rf.Type.Type() != f.Type.Type() {
+					return fmt.Errorf(`%s: field "%s" has type "%s" in the writer and "%s" in the reader`,
+						n.Path, rf.Name, f.Type.Type(), rf.Type.Type())
+				}
+				pr.index[i] = j
+				continue
+			}
+			path := n.Path + fieldPath(rf.Name)
+			data, err := rf.encodeDefault()
+			if err != nil {
+				return fmt.Errorf(`%s: %s`, path, err)
+			}
+			defaulted[path] = true
+			pr.defaults = append(pr.defaults, fieldDefault{index: j, data: data})
+		}
+		p[w.Fullname()] = pr
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// promotedTo returns the reader primitive r if it is a different primitive
+// which can read writer primitive w, as CheckCompatibility allows.
+func promotedTo(w, r Schema) (Primitive, bool) {
+	wp, ok := resolve(w).(Primitive)
+	rp, rok := resolve(r).(Primitive)
+	if !ok || !rok || wp == rp {
+		return "", false
+	}
+	for _, p := range promotions[rp] {
+		if p == wp {
+			return rp, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,203 @@
+package avro
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const projectTestSchema = `{"type": "record", "name": "ns.Event", "fields": [
+	{"name": "id", "type": "long"},
+	{"name": "payload", "type": "bytes"},
+	{"name": "user", "type": {"type": "record", "name": "User", "fields": [
+		{"name": "name", "type": "string"},
+		{"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [
+			{"name": "street", "type": "string"},
+			{"name": "city", "type": "string"}
+		]}]},
+		{"name": "tags", "type": {"type": "array", "items": "string"}}
+	]}},
+	{"name": "home", "type": ["null", "Address"]}
+]}`
+
+func TestProject(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(projectTestSchema))
+	is.NoErr(err)
+	p, err := Project(s, "id", "user.address.city")
+	is.NoErr(err)
+	is.NoErr(p.Valid())
+	b, err := Canonical(p)
+	is.NoErr(err)
+	is.Equal(string(b), `{"name":"ns.Event","type":"record","fields":[{"name":"id","type":"long"},`+
+		`{"name":"user","type":{"name":"ns.User","type":"record","fields":[{"name":"address","type":["null",`+
+		`{"name":"ns.Address","type":"record","fields":[{"name":"city","type":"string"}]}]}]}}]}`) // nested fields only
+
+	p, err = Project(s, "home", "user.name")
+	is.NoErr(err)
+	b, err = Canonical(p)
+	is.NoErr(err)
+	is.Equal(string(b), `{"name":"ns.Event","type":"record","fields":[{"name":"user","type":{"name":"ns.User",`+
+		`"type":"record","fields":[{"name":"name","type":"string"}]}},{"name":"home","type":["null",`+
+		`{"name":"ns.Address","type":"record","fields":[{"name":"street","type":"string"},{"name":"city","type":"string"}]}]}]}`) // whole field, defined where first used
+
+	_, err = Project(s, "user.nope")
+	is.True(err != nil) // unknown field
+	_, err = Project(s, "id.x")
+	is.True(err != nil) // not a record
+	_, err = Project(s)
+	is.True(err != nil) // no paths
+}
+
+func TestDecodeProjected(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(projectTestSchema))
+	is.NoErr(err)
+	data := []byte{
+		2,             // id 1
+		4, 0xff, 0xff, // payload
+		2, 'a', // user.name
+		2, 2, 'x', 2, 'y', // user.address street "x", city "y"
+		2, 2, 't', 0, // user.tags
+		0, // home null
+	}
+	p, err := Project(s, "id", "user.address.city")
+	is.NoErr(err)
+
+	var v interface{}
+	is.NoErr(DecodeProjected(s, p, data, &v))
+	r := v.(*GenericRecord)
+	is.Equal(r.Schema().Fullname(), "ns.Event")
+	is.Equal(r.GetIndex(0), int64(1)) // selected field
+	user := r.GetIndex(1).(*GenericRecord)
+	is.Equal(len(user.Schema().Fields), 1) // projected nested record
+	addr := user.GetIndex(0).(GenericUnion).Value.(*GenericRecord)
+	is.Equal(addr.GetIndex(0), "y") // nested field
+	is.NoErr(p.Validate(r))         // value has the projected schema
+
+	type event struct {
+		ID   int64 `avro:"id"`
+		User struct {
+			Name    string `avro:"name"`
+			Address *struct {
+				City string `avro:"city"`
+			} `avro:"address"`
+		} `avro:"user"`
+	}
+	var e event
+	is.NoErr(DecodeProjected(s, p, data, &e))
+	is.Equal(e.ID, int64(1))
+	is.Equal(e.User.Name, "") // not selected, skipped
+	is.Equal(e.User.Address.City, "y")
+
+	is.True(DecodeProjected(s, p, data[:5], &v) != nil) // truncated
+	added, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "ns.Event", "fields": [
+		{"name": "version", "type": "int", "default": 2},
+		{"name": "id", "type": "long"}
+	]}`))
+	is.NoErr(err)
+	var m map[string]interface{}
+	is.NoErr(DecodeProjected(s, added, data, &m))
+	is.Equal(m, map[string]interface{}{"version": int32(2), "id": int64(1)}) // missing field takes its default
+	renamed, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "v2.Message", "aliases": ["ns.Event"], "fields": [
+		{"name": "key", "type": "long", "aliases": ["id"]}
+	]}`))
+	is.NoErr(err)
+	m = nil
+	is.NoErr(DecodeProjected(s, renamed, data, &m))
+	is.Equal(m, map[string]interface{}{"key": int64(1)}) // record and field found by aliases
+	other, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "ns.Event", "fields": [{"name": "x", "type": "long"}]}`))
+	is.NoErr(err)
+	is.True(DecodeProjected(s, other, data, &v) != nil) // not a projection
+
+	writer, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}]}`))
+	is.NoErr(err)
+	nested, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [
+		{"name": "a", "type": "int"},
+		{"name": "b", "type": {"type": "record", "name": "S", "fields": [{"name": "x", "type": "int"}]}, "default": {"x": 1}}
+	]}`))
+	is.NoErr(err)
+	m = nil
+	is.NoErr(DecodeProjected(writer, nested, []byte{6}, &m))
+	is.Equal(m["a"], int32(3))
+	is.Equal(m["b"].(*GenericRecord).GetIndex(0), int32(1)) // records inside defaults need not be written
+}
+
+func TestDecodeProjected_promotions(t *testing.T) {
+	is := is.New(t)
+
+	writer, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [
+		{"name": "a", "type": "int"},
+		{"name": "b", "type": "long"},
+		{"name": "c", "type": "float"},
+		{"name": "d", "type": "string"}
+	]}`))
+	is.NoErr(err)
+	reader, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [
+		{"name": "a", "type": "long"},
+		{"name": "b", "type": "double"},
+		{"name": "c", "type": "double"},
+		{"name": "d", "type": "bytes"}
+	]}`))
+	is.NoErr(err)
+	is.NoErr(CheckCompatibility(reader, writer))
+	data, err := Encode(writer, map[string]interface{}{"a": 1, "b": 2, "c": float32(1.5), "d": "x"})
+	is.NoErr(err)
+
+	var m map[string]interface{}
+	is.NoErr(DecodeProjected(writer, reader, data, &m))
+	is.Equal(m, map[string]interface{}{"a": int64(1), "b": float64(2), "c": 1.5, "d": []byte("x")}) // values have the reader types
+
+	var v interface{}
+	is.NoErr(DecodeProjected(writer, reader, data, &v))
+	is.NoErr(reader.Validate(v)) // generic record has the reader types
+
+	var r struct {
+		A int64   `avro:"a"`
+		B float64 `avro:"b"`
+		C float32 `avro:"c"`
+		D []byte  `avro:"d"`
+	}
+	is.NoErr(DecodeProjected(writer, reader, data, &r))
+	is.Equal(r.B, float64(2)) // long read as double into a struct
+	is.Equal(r.C, float32(1.5))
+	is.Equal(r.D, []byte("x"))
+
+	narrowed, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [{"name": "b", "type": "int"}]}`))
+	is.NoErr(err)
+	is.True(DecodeProjected(writer, narrowed, data, &v) != nil) // long cannot be read as int
+}
+
+func TestReader_Project(t *testing.T) {
+	is := is.New(t)
+
+	var b bytes.Buffer
+	w, err := NewWriter(&b, containerSchema)
+	is.NoErr(err)
+	for i := int64(0); i < 3; i++ {
+		is.NoErr(w.Append(containerItem{ID: i, Name: "item"}))
+	}
+	is.NoErr(w.Close())
+
+	r, err := NewReader(&b)
+	is.NoErr(err)
+	p, err := r.Project("name")
+	is.NoErr(err)
+	is.Equal(len(p.(Record).Fields), 1) // projected schema
+	var got []map[string]interface{}
+	for {
+		var v map[string]interface{}
+		err := r.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		is.NoErr(err)
+		got = append(got, v)
+	}
+	is.Equal(len(got), 3)
+	is.Equal(got[2], map[string]interface{}{"name": "item"}) // only the selected field
+}
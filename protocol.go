@@ -0,0 +1,231 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Protocol represents an Avro protocol, which declares named types and the
+// messages which use them.
+type Protocol struct {
+	Protocol  string
+	Namespace string
+	Doc       string
+	Types     []Schema // named types, in order of definition
+	Messages  map[string]Message
+}
+
+// Message is a message of a Protocol.
+type Message struct {
+	Doc      string
+	Request  []Field // parameters
+	Response Schema
+	Errors   []Schema // declared errors, not including the implicit "string"
+	OneWay   bool
+}
+
+// Fullname returns the full namespaced name of the protocol.
+func (p Protocol) Fullname() string {
+	return NameFields{Name: p.Protocol, Namespace: p.Namespace}.Fullname()
+}
+
+// Valid checks that the protocol name, types and messages are valid.
+func (p Protocol) Valid() error {
+	if err := (NameFields{Name: p.Protocol}).Valid(); err != nil {
+		return err
+	}
+	errs := map[string]error{}
+	for i, t := range p.Types {
+		path := ".types" + indexPath(i)
+		if _, ok := t.(NamedSchema); !ok {
+			errs[path] = fmt.Errorf(`type "%s" is not a named type`, t.Type())
+			continue
+		}
+		if err := t.Valid(); err != nil {
+			errs[path] = err
+		}
+	}
+	for name, m := range p.Messages {
+		path := ".messages" + keyPath(name)
+		if !nameRegex.MatchString(name) {
+			errs[path] = fmt.Errorf(`"%s" is an invalid name`, name)
+			continue
+		}
+		if err := m.valid(); err != nil {
+			errs[path] = err
+		}
+	}
+	if len(errs) > 0 {
+		return ErrValidation{
+			Children: errs,
+		}
+	}
+	return nil
+}
+
+func (m Message) valid() error {
+	if m.Response == nil {
+		return errors.New(`missing response`)
+	}
+	if err := m.Response.Valid(); err != nil {
+		return fmt.Errorf(`response: %s`, err)
+	}
+	request := Record{NameFields: NameFields{Name: "request"}, Fields: m.Request}
+	if err := request.Valid(); err != nil {
+		return fmt.Errorf(`request: %s`, err)
+	}
+	for _, e := range m.Errors {
+		if r, ok := resolve(e).(Record); !ok {
+			return fmt.Errorf(`error "%s" is not a record`, e.Type())
+		} else if err := r.NameFields.Valid(); err != nil {
+			return fmt.Errorf(`error: %s`, err)
+		}
+	}
+	if m.OneWay && (m.Response != Null || len(m.Errors) > 0) {
+		return errors.New(`one-way message must have a null response and no errors`)
+	}
+	return nil
+}
+
+type jsonProtocol struct {
+	Protocol  string                 `json:"protocol"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Doc       string                 `json:"doc,omitempty"`
+	Types     []json.RawMessage      `json:"types"`
+	Messages  map[string]jsonMessage `json:"messages"`
+}
+
+type jsonMessage struct {
+	Doc      string          `json:"doc,omitempty"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+	Errors   json.RawMessage `json:"errors,omitempty"`
+	OneWay   bool            `json:"one-way,omitempty"`
+}
+
+// ParseProtocol creates a Protocol from an Avro protocol declaration, as found
+// in .avpr files.
+func ParseProtocol(spec []byte) (Protocol, error) {
+	p := newSchemaParser()
+	pr, err := p.parseProtocol(spec)
+	if err != nil {
+		return Protocol{}, err
+	}
+	if err := p.resolve(); err != nil {
+		return Protocol{}, err
+	}
+	return pr, pr.Valid()
+}
+
+// parseProtocol parses a protocol declaration without resolving references.
+func (p *schemaParser) parseProtocol(spec []byte) (Protocol, error) {
+	var raw jsonProtocol
+	if err := json.Unmarshal(spec, &raw); err != nil {
+		return Protocol{}, fmt.Errorf(`unmarshal protocol json: "%s"`, err)
+	}
+	if raw.Protocol == "" {
+		return Protocol{}, ErrMissingRequiredAttribute{"protocol"}
+	}
+	pr := Protocol{
+		Protocol:  raw.Protocol,
+		Namespace: raw.Namespace,
+		Doc:       raw.Doc,
+		Messages:  make(map[string]Message, len(raw.Messages)),
+	}
+	errorsBefore := p.errors
+	p.errors = true
+	defer func() { p.errors = errorsBefore }()
+	for i, rt := range raw.Types {
+		t, err := p.parse(rt, pr.Namespace)
+		if err != nil {
+			return Protocol{}, fmt.Errorf(`type #%d: %s`, i, err)
+		}
+		pr.Types = append(pr.Types, t)
+	}
+	for name, rm := range raw.Messages {
+		m, err := p.parseMessage(rm, name, pr.Namespace)
+		if err != nil {
+			return Protocol{}, fmt.Errorf(`message "%s": %s`, name, err)
+		}
+		pr.Messages[name] = m
+	}
+	return pr, nil
+}
+
+func (p *schemaParser) parseMessage(rm jsonMessage, name, namespace string) (Message, error) {
+	m := Message{Doc: rm.Doc, OneWay: rm.OneWay}
+	var request []jsonField
+	if err := json.Unmarshal(rm.Request, &request); err != nil {
+		return Message{}, fmt.Errorf(`request: %s`, err)
+	}
+	m.Request = make([]Field, len(request))
+	if err := p.parseFields(request, m.Request, namespace, name); err != nil {
+		return Message{}, fmt.Errorf(`request: %s`, err)
+	}
+	if rm.Response == nil {
+		return Message{}, ErrMissingRequiredAttribute{"response"}
+	}
+	var err error
+	if m.Response, err = p.parse(rm.Response, namespace); err != nil {
+		return Message{}, fmt.Errorf(`response: %s`, err)
+	}
+	if rm.Errors != nil {
+		var names []json.RawMessage
+		if err := json.Unmarshal(rm.Errors, &names); err != nil {
+			return Message{}, fmt.Errorf(`errors: %s`, err)
+		}
+		for _, n := range names {
+			e, err := p.parse(n, namespace)
+			if err != nil {
+				return Message{}, fmt.Errorf(`errors: %s`, err)
+			}
+			if e != String {
+				m.Errors = append(m.Errors, e)
+			}
+		}
+	}
+	return m, nil
+}
+
+// MarshalJSON validates before marshaling.
+func (p Protocol) MarshalJSON() ([]byte, error) {
+	if err := p.Valid(); err != nil {
+		return nil, err
+	}
+	raw := jsonProtocol{
+		Protocol:  p.Protocol,
+		Namespace: p.Namespace,
+		Doc:       p.Doc,
+		Types:     make([]json.RawMessage, len(p.Types)),
+		Messages:  make(map[string]jsonMessage, len(p.Messages)),
+	}
+	for i, t := range p.Types {
+		b, err := json.Marshal(t)
+		if err != nil {
+			return nil, err
+		}
+		raw.Types[i] = b
+	}
+	for name, m := range p.Messages {
+		request := m.Request
+		if request == nil {
+			request = []Field{}
+		}
+		rm := jsonMessage{Doc: m.Doc, OneWay: m.OneWay}
+		var err error
+		if rm.Request, err = json.Marshal(request); err != nil {
+			return nil, err
+		}
+		if rm.Response, err = json.Marshal(m.Response); err != nil {
+			return nil, err
+		}
+		if len(m.Errors) > 0 {
+			if rm.Errors, err = json.Marshal(m.Errors); err != nil {
+				return nil, err
+			}
+		}
+		raw.Messages[name] = rm
+	}
+	return json.Marshal(&raw)
+}
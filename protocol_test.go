@@ -0,0 +1,59 @@
+package avro
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const testProtocol = `{
+	"protocol": "Mail", "namespace": "test.mail", "doc": "sends mail",
+	"types": [
+		{"type": "record", "name": "Message", "fields": [
+			{"name": "to", "type": "string"},
+			{"name": "body", "type": "string", "default": ""}
+		]},
+		{"type": "error", "name": "Bounce", "fields": [{"name": "reason", "type": "string"}]}
+	],
+	"messages": {
+		"send": {
+			"doc": "sends a message",
+			"request": [{"name": "message", "type": "Message"}],
+			"response": "string",
+			"errors": ["Bounce"]
+		},
+		"ping": {"request": [], "response": "null", "one-way": true}
+	}
+}`
+
+func TestParseProtocol(t *testing.T) {
+	is := is.New(t)
+
+	p, err := ParseProtocol([]byte(testProtocol))
+	is.NoErr(err) // parse protocol
+	is.Equal(p.Fullname(), "test.mail.Mail")
+	is.Equal(p.Doc, "sends mail")
+	is.Equal(len(p.Types), 2)
+	is.Equal(p.Types[1].(Record).Fullname(), "test.mail.Bounce") // errors are records
+
+	send := p.Messages["send"]
+	is.Equal(send.Doc, "sends a message")
+	is.Equal(send.Response, String)
+	is.Equal(send.Request[0].Type.(Reference).Target().(Record).Fullname(), "test.mail.Message")
+	is.Equal(send.Errors[0].(Reference).Name, "test.mail.Bounce")
+	is.True(p.Messages["ping"].OneWay)
+
+	b, err := json.Marshal(p)
+	is.NoErr(err) // marshal protocol
+	again, err := ParseProtocol(b)
+	is.NoErr(err) // marshaled protocol parses
+	is.Equal(len(again.Messages), 2)
+
+	_, err = ParseProtocol([]byte(`{"protocol": "P", "messages": {"m": {"request": [], "response": "Missing"}}}`))
+	is.True(err != nil) // unknown type
+	_, err = ParseProtocol([]byte(`{"protocol": "P", "messages": {"m": {"request": [], "response": "int", "one-way": true}}}`))
+	is.True(err != nil) // one-way message with a response
+	_, err = ParseProtocol([]byte(`{"types": []}`))
+	is.True(err != nil) // missing protocol name
+}
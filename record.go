@@ -31,31 +31,36 @@ func (r Record) Valid() error {
 	}
 	errs := map[string]error{}
 	for i, f := range r.Fields {
-		name := fmt.Sprintf(`field #%d ("%s")`, i, f.Name)
+		path := ".fields" + indexPath(i)
 		if !nameRegex.MatchString(f.Name) {
-			errs[name] = errors.New("invalid name")
+			errs[path+".name"] = fmt.Errorf(`"%s" is an invalid name`, f.Name)
+		}
+		for j, a := range f.Aliases {
+			if !nameRegex.MatchString(a) {
+				errs[path+".aliases"+indexPath(j)] = fmt.Errorf(`"%s" is an invalid alias`, a)
+			}
 		}
 		// Check if the Type is missing.
 		if f.Type == nil {
-			errs[fmt.Sprintf(`%s type`, name)] = errors.New("missing type")
+			errs[path+".type"] = errors.New("missing type")
 			continue
 		}
 		// Check if the Type is valid.
 		if err := f.Type.Valid(); err != nil {
-			errs[fmt.Sprintf(`%s type`, name)] = err
+			errs[path+".type"] = err
 			continue
 		}
 		// Check if the Default value is valid.
 		if f.Default != nil {
-			if err := f.Type.Validate(f.Default); err != nil {
-				errs[fmt.Sprintf(`%s default`, name)] = err
+			if err := f.Type.Validate(*f.Default); err != nil {
+				errs[path+".default"] = err
 			}
 		}
 		// Check if the Order is valid.
 		switch f.Order {
 		case "", "ascending", "descending", "ignore":
 		default:
-			errs[fmt.Sprintf(`%s order`, name)] = fmt.Errorf(`"%s" is not a valid value`, f.Order)
+			errs[path+".order"] = fmt.Errorf(`"%s" is not a valid value`, f.Order)
 		}
 	}
 	if len(errs) > 0 {
@@ -74,6 +79,17 @@ func (r Record) Validate(v interface{}) error {
 		return fmt.Errorf(`validation aborted, record schema is invalid: %s`, err)
 	}
 
+	// Static check for generic records.
+	validateField := func(i int, v interface{}) error {
+		return r.Fields[i].Type.Validate(v)
+	}
+	switch g := v.(type) {
+	case *GenericRecord:
+		return g.validate(r, validateField)
+	case GenericRecord:
+		return g.validate(r, validateField)
+	}
+
 	// Static check for map[string]interface{} and pointer.
 	var msi map[string]interface{}
 	if p, ok := v.(*map[string]interface{}); ok {
@@ -93,11 +109,11 @@ func (r Record) Validate(v interface{}) error {
 		for k, val := range msi {
 			f, ok := r.GetField(k)
 			if !ok {
-				errs[k] = errors.New("record does not have a field with this name")
+				errs[fieldPath(k)] = errors.New("record does not have a field with this name")
 				continue
 			}
 			if err := f.Type.Validate(val); err != nil {
-				errs[k] = err
+				errs[fieldPath(k)] = err
 			}
 		}
 		if len(errs) > 0 {
@@ -119,22 +135,19 @@ func (r Record) Validate(v interface{}) error {
 		numField := rv.NumField()
 		t := rv.Type()
 		for i := 0; i < numField; i++ {
-			rf := t.Field(i)
-			// Check that name or tag exists in record type.
-			var name string
-			if tag := rf.Tag.Get("avro"); tag != "" {
-				name = tag
-			} else {
-				name = rf.Name
+			if skipStructField(t.Field(i)) {
+				continue
 			}
+			// Check that name or tag exists in record type.
+			name := structFieldName(t.Field(i))
 			field, ok := r.GetField(name)
 			if !ok {
-				errs[name] = errors.New("record does not have a field with this name")
+				errs[fieldPath(name)] = errors.New("record does not have a field with this name")
 				continue
 			}
 			// Check if field value is valid.
 			if err := field.Type.Validate(rv.Field(i).Interface()); err != nil {
-				errs[name] = err
+				errs[fieldPath(name)] = err
 			}
 		}
 		if len(errs) > 0 {
@@ -152,12 +165,12 @@ func (r Record) Validate(v interface{}) error {
 			name := k.String()
 			field, ok := r.GetField(name)
 			if !ok {
-				errs[name] = errors.New("record does not have a field with this name")
+				errs[fieldPath(name)] = errors.New("record does not have a field with this name")
 				continue
 			}
 			val := rv.MapIndex(k).Interface()
 			if err := field.Type.Validate(val); err != nil {
-				errs[name] = err
+				errs[fieldPath(name)] = err
 			}
 		}
 		if len(errs) > 0 {
@@ -171,41 +184,135 @@ func (r Record) Validate(v interface{}) error {
 	return fmt.Errorf(`value with type "%s" is not a valid record`, rv.Kind())
 }
 
+// structFieldName returns the record field name of a struct field, which is
+// taken from the "avro" tag if present.
+func structFieldName(rf reflect.StructField) string {
+	if tag := rf.Tag.Get("avro"); tag != "" {
+		return tag
+	}
+	return rf.Name
+}
+
+// skipStructField reports whether a struct field is left out of its record:
+// it is unexported or tagged `avro:"-"`.
+func skipStructField(rf reflect.StructField) bool {
+	return rf.PkgPath != "" || rf.Tag.Get("avro") == "-"
+}
+
+// GetField returns a copy of the field called name or, failing that, of the
+// field with name among its aliases, so that renamed fields are still found
+// by their old names.
 func (r Record) GetField(name string) (*Field, bool) {
-	for _, f := range r.Fields {
+	i := r.fieldIndex(name, true)
+	if i < 0 {
+		return nil, false
+	}
+	f := r.Fields[i]
+	return &f, true
+}
+
+// fieldIndex returns the index of the field called name or, if aliases is
+// true, with name among its aliases. It returns -1 if there is none.
+func (r Record) fieldIndex(name string, aliases bool) int {
+	for i, f := range r.Fields {
 		if f.Name == name {
-			return &f, true
+			return i
 		}
 	}
-	return nil, false
+	if aliases {
+		for i, f := range r.Fields {
+			for _, a := range f.Aliases {
+				if a == name {
+					return i
+				}
+			}
+		}
+	}
+	return -1
 }
 
 // UnmarshalJSON is implemented to support dynamic unmarshaling of Field Types.
-func (f Field) UnmarshalJSON(data []byte) error {
+func (f *Field) UnmarshalJSON(data []byte) error {
 	var raw struct {
-		Name    string          `json:"name"`
-		Doc     string          `json:"doc,omitempty"`
-		Type    json.RawMessage `json:"type"`
-		Default *interface{}    `json:"default,omitempty"`
-		Order   string          `json:"order,omitempty"`
-		Aliases []string        `json:"aliases,omitempty"`
-	}
-	err := json.Unmarshal(data, &raw)
+		Type json.RawMessage `json:"type"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf(`unmarshal field json: "%s"`, err)
+	}
+	// Parse the field as the only field of a record, so that its default is
+	// parsed for its type.
+	spec, err := json.Marshal(map[string]interface{}{
+		"type":   "record",
+		"name":   "Field",
+		"fields": []json.RawMessage{data},
+	})
+	if err != nil {
+		return err
+	}
+	p := newSchemaParser()
+	s, err := p.parse(spec, "")
 	if err != nil {
 		return fmt.Errorf(`unmarshal field json: "%s"`, err)
 	}
-	if f.Type, err = SchemaUnmarshalJSON(raw.Type); err != nil {
-		return fmt.Errorf(`unmarshal field.type json: "%s"`, err)
+	if err := p.resolve(); err != nil {
+		return fmt.Errorf(`unmarshal field json: "%s"`, err)
+	}
+	*f = s.(Record).Fields[0]
+	return nil
+}
+
+// MarshalJSON writes the default as it appears in a schema declaration.
+func (f Field) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		Name    string       `json:"name"`
+		Doc     string       `json:"doc,omitempty"`
+		Type    Schema       `json:"type"`
+		Default *interface{} `json:"default,omitempty"`
+		Order   string       `json:"order,omitempty"`
+		Aliases []string     `json:"aliases,omitempty"`
+	}{f.Name, f.Doc, f.Type, nil, f.Order, f.Aliases}
+	if f.Default != nil {
+		d, err := defaultJSON(f.Type, *f.Default)
+		if err != nil {
+			return nil, fmt.Errorf(`default of field "%s": %s`, f.Name, err)
+		}
+		raw.Default = &d
+	}
+	return json.Marshal(raw)
+}
+
+type jsonRecord struct {
+	Type string `json:"type"`
+	NameFields
+	Doc    string  `json:"doc,omitempty"`
+	Fields []Field `json:"fields"`
+}
+
+// UnmarshalJSON is implemented to check the "type" field and to support
+// dynamic unmarshaling of field types.
+func (r *Record) UnmarshalJSON(data []byte) error {
+	s, err := SchemaUnmarshalJSON(data)
+	if err != nil {
+		return fmt.Errorf(`unmarshal record json: "%s"`, err)
+	}
+	rs, ok := s.(Record)
+	if !ok {
+		return fmt.Errorf(`cannot read type "%s" into %s`, s.Type(), r.Type())
 	}
-	f.Name = raw.Name
-	f.Doc = raw.Doc
-	f.Default = raw.Default
-	f.Order = raw.Order
-	f.Aliases = raw.Aliases
+	*r = rs
 	return nil
 }
 
 // MarshalJSON adds the "type" field and validates before marshaling.
 func (r Record) MarshalJSON() ([]byte, error) {
-	return nil, nil
+	if err := r.Valid(); err != nil {
+		return nil, err
+	}
+	raw := jsonRecord{
+		Type:       r.Type(),
+		NameFields: r.NameFields,
+		Doc:        r.Doc,
+		Fields:     r.Fields,
+	}
+	return json.Marshal(&raw)
 }
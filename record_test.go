@@ -1,6 +1,7 @@
 package avro
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/matryer/is"
@@ -44,10 +45,49 @@ func TestRecord_Valid(t *testing.T) {
 	r.Fields[0].Order = "ascending"
 	is.NoErr(r.Valid()) // field with valid order should be valid
 
+	r.Fields[0].Aliases = []string{"old.name"}
+	is.True(r.Valid() != nil) // field alias must be a simple name
+	r.Fields[0].Aliases = []string{"old"}
+	is.NoErr(r.Valid())
+
 	r.Fields[0].Type = mockInvalidNamedSchema
 	is.True(r.Valid() != nil) // having a field with invalid schema should be invalid
 }
 
+func TestRecord_GetField(t *testing.T) {
+	is := is.New(t)
+
+	r := Record{
+		NameFields: NameFields{Name: "R"},
+		Fields: []Field{
+			{Name: "email", Type: String, Aliases: []string{"mail", "id"}},
+			{Name: "id", Type: Long},
+		},
+	}
+	f, ok := r.GetField("mail")
+	is.True(ok)
+	is.Equal(f.Name, "email") // found by alias
+	f, ok = r.GetField("id")
+	is.True(ok)
+	is.Equal(f.Name, "id") // names before aliases
+	_, ok = r.GetField("nope")
+	is.True(!ok)
+
+	type old struct {
+		Mail string `avro:"mail"`
+		ID   int64  `avro:"id"`
+	}
+	b, err := Encode(r, old{Mail: "a", ID: 1})
+	is.NoErr(err) // struct field found by alias
+	var v old
+	is.NoErr(Decode(r, b, &v))
+	is.Equal(v, old{Mail: "a", ID: 1})
+	is.NoErr(r.Validate(map[string]interface{}{"mail": "a", "id": int64(1)}))
+	b2, err := Encode(r, map[string]interface{}{"mail": "a", "id": int64(1)})
+	is.NoErr(err)
+	is.Equal(b2, b) // map key found by alias
+}
+
 func TestRecord_Validate(t *testing.T) {
 	is := is.New(t)
 
@@ -107,3 +147,46 @@ func TestRecord_Validate(t *testing.T) {
 
 	is.True(r.Validate(0) != nil) // invalid type should be invalid
 }
+
+func TestRecord_skippedStructFields(t *testing.T) {
+	is := is.New(t)
+
+	r := Record{NameFields: NameFields{Name: "R"}, Fields: []Field{{Name: "id", Type: Long}}}
+	type item struct {
+		ID     int64 `avro:"id"`
+		Cache  []int `avro:"-"`
+		hidden string
+	}
+	v := item{ID: 3, Cache: []int{1}, hidden: "x"}
+	is.NoErr(r.Validate(v)) // skipped fields need not be in the record
+	c, err := Compile(r)
+	is.NoErr(err)
+	is.NoErr(c.Validate(v)) // nor when compiled
+	b, err := Encode(r, v)
+	is.NoErr(err)
+	is.Equal(b, []byte{6}) // only the record fields are written
+	var out item
+	is.NoErr(Decode(r, b, &out))
+	is.Equal(out, item{ID: 3}) // and read
+}
+
+func TestRecord_JSON(t *testing.T) {
+	is := is.New(t)
+
+	spec := `{"type":"record","name":"Node","namespace":"test","doc":"a node","fields":[` +
+		`{"name":"data","type":"bytes","default":"ÿ"},` +
+		`{"name":"next","type":["null","test.Node"],"default":null,"order":"ignore"}]}`
+	var r Record
+	is.NoErr(json.Unmarshal([]byte(spec), &r)) // unmarshal record
+	is.Equal(r.Doc, "a node")
+	is.Equal(*r.Fields[0].Default, []byte{0xff})
+	b, err := json.Marshal(r)
+	is.NoErr(err)
+	is.Equal(string(b), spec) // marshals back to the same declaration
+
+	var f Field
+	is.NoErr(json.Unmarshal([]byte(`{"name":"n","type":"long","default":3}`), &f)) // unmarshal field
+	is.Equal(f.Name, "n")
+	is.Equal(*f.Default, int64(3))
+	is.True(json.Unmarshal([]byte(`{"type":"int"}`), &r) != nil) // not a record
+}
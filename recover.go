@@ -0,0 +1,116 @@
+package avro
+
+import (
+	"fmt"
+	"io"
+)
+
+// BadBlock is a block of an object container file which could not be read.
+type BadBlock struct {
+	Offset int64 // offset of the block in the file
+	Length int64 // bytes skipped, up to the end of the next sync marker or of the file
+	Count  int64 // values not read from the block, or -1 if not known
+	Err    error
+}
+
+// SkipBadBlocks makes the Reader skip blocks which cannot be read, such as
+// truncated or corrupt blocks, calling report with each rather than returning
+// its error. Blocks whose framing is corrupt are skipped up to the next sync
+// marker: if the file can be seeked, the search starts at the bad block, as
+// its size cannot be trusted, and otherwise where the error was found.
+// Blocks which cannot be decompressed or decoded are skipped whole, after any
+// values already read from them. During ReadParallel, report is called on the
+// goroutine calling fn, in the order fn gets values.
+func (r *Reader) SkipBadBlocks(report func(BadBlock)) {
+	if report == nil {
+		report = func(BadBlock) {}
+	}
+	r.bad = report
+}
+
+// dropBlock reports the last block read as bad, with count values not read,
+// and drops the values left in it. It returns false, doing nothing, unless
+// bad blocks are skipped.
+func (r *Reader) dropBlock(count int64, err error) bool {
+	if r.bad == nil {
+		return false
+	}
+	r.bad(BadBlock{Offset: r.blockStart, Length: r.blockEnd - r.blockStart, Count: count, Err: err})
+	r.block, r.left = decoder{}, 0
+	return true
+}
+
+// resync reports the block at start, which could not be read, and moves to
+// the next sync marker.
+func (r *Reader) resync(start, count int64, err error) error {
+	// Standard input, for one, may be an io.Seeker which cannot seek.
+	end := r.end
+	if err := r.SeekSync(start); err != nil {
+		if err := r.scanSync(); err != nil {
+			return err
+		}
+	}
+	r.end = end
+	r.bad(BadBlock{Offset: start, Length: r.offset() - start, Count: count, Err: err})
+	return nil
+}
+
+// Repair reads the object container file from r and writes a copy of it to
+// w, with its schema, codec, sync marker and user metadata, which has only
+// the blocks which can be read and whose values can be decoded. It calls
+// report, if not nil, with each other block, and returns the number of
+// values kept.
+func Repair(w io.Writer, r io.Reader, report func(BadBlock)) (int64, error) {
+	cr, err := NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	cr.SkipBadBlocks(report)
+	opts := []WriterOption{WithCodec(cr.codec), WithSyncMarker(cr.sync)}
+	for k, v := range cr.UserMetadata() {
+		opts = append(opts, WithMetadata(k, v))
+	}
+	cw, err := NewWriter(w, cr.schema, opts...)
+	if err != nil {
+		return 0, err
+	}
+	var kept int64
+	for {
+		b, err := cr.ReadRawBlock()
+		if err == io.EOF {
+			return kept, nil
+		} else if err != nil {
+			return kept, err
+		}
+		if err := cr.checkBlock(b); err != nil {
+			cr.dropBlock(b.Count, err)
+			continue
+		}
+		if err := cw.writeBlock(b.Count, b.Data); err != nil {
+			return kept, err
+		}
+		kept += b.Count
+	}
+}
+
+// checkBlock checks that b decompresses into exactly its values, and that
+// they can be decoded.
+func (r *Reader) checkBlock(b RawBlock) error {
+	data, err := decompressBlock(r.codec, b.Data)
+	if err != nil {
+		return err
+	}
+	if err := checkCount(r.schema, b.Count, len(data)); err != nil {
+		return err
+	}
+	d := decoder{buf: data}
+	for i := int64(0); i < b.Count; i++ {
+		if _, err := d.decodeGeneric(r.schema); err != nil {
+			return fmt.Errorf(`value %d: %s`, i, err)
+		}
+	}
+	if rest := len(d.buf) - d.pos; rest > 0 {
+		return fmt.Errorf(`%d bytes remain after the values of the block`, rest)
+	}
+	return nil
+}
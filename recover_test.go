@@ -0,0 +1,91 @@
+package avro
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+// blocksFile returns a container file with blocks of one value, and the
+// offsets of its blocks.
+func blocksFile(t *testing.T, codec string, n int) ([]byte, []int) {
+	is := is.New(t)
+	var b bytes.Buffer
+	w, err := NewWriter(&b, containerSchema, WithCodec(codec), WithBlockSize(1))
+	is.NoErr(err)
+	offsets := []int{b.Len()}
+	for i := 0; i < n; i++ {
+		is.NoErr(w.Append(containerItem{ID: int64(i), Name: "item"}))
+		offsets = append(offsets, b.Len())
+	}
+	is.NoErr(w.Close())
+	return b.Bytes(), offsets[:n]
+}
+
+func readIDs(is *is.I, r *Reader) []int64 {
+	var ids []int64
+	for {
+		var item containerItem
+		if err := r.Decode(&item); err == io.EOF {
+			return ids
+		} else {
+			is.NoErr(err)
+		}
+		ids = append(ids, item.ID)
+	}
+}
+
+func TestReader_SkipBadBlocks(t *testing.T) {
+	is := is.New(t)
+
+	data, offsets := blocksFile(t, CodecNull, 6)
+	data[offsets[1]] = 0xff   // block count of value 1: a long varint reaching into the block
+	data[offsets[2]+3] = 0x7f // value 2: a string length past the block
+	data[offsets[4]-1] ^= 1   // sync marker of value 3, so that value 4 cannot be found
+
+	for _, src := range []io.Reader{bytes.NewReader(data), bytes.NewBuffer(data)} {
+		r, err := NewReader(src)
+		is.NoErr(err)
+		var bad []BadBlock
+		r.SkipBadBlocks(func(b BadBlock) { bad = append(bad, b) })
+		is.Equal(readIDs(is, r), []int64{0, 5}) // good blocks
+		is.Equal(len(bad), 3)
+		is.Equal(bad[0].Offset, int64(offsets[1]))
+		is.Equal(bad[1].Offset, int64(offsets[2]))
+		is.Equal(bad[1].Count, int64(1)) // value not decoded
+		is.Equal(bad[2].Offset, int64(offsets[3]))
+		is.Equal(bad[2].Length, int64(offsets[5]-offsets[3])) // up to the sync marker of value 4
+	}
+
+	r, err := NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	var item containerItem
+	is.NoErr(r.Decode(&item))
+	is.True(r.Decode(&item) != nil) // errors unless skipping
+}
+
+func TestRepair(t *testing.T) {
+	is := is.New(t)
+
+	data, offsets := blocksFile(t, CodecDeflate, 4)
+	data[offsets[1]+3] ^= 0xff // compressed data of value 1
+	data = data[:len(data)-5]  // truncated last block
+	var out bytes.Buffer
+	var bad []BadBlock
+	kept, err := Repair(&out, bytes.NewReader(data), func(b BadBlock) { bad = append(bad, b) })
+	is.NoErr(err)
+	is.Equal(kept, int64(2))
+	is.Equal(len(bad), 2)
+	is.Equal(bad[1].Offset, int64(offsets[3]))
+	is.Equal(bad[1].Length, int64(len(data)-offsets[3])) // to the end of the file
+
+	r, err := NewReader(bytes.NewReader(out.Bytes()))
+	is.NoErr(err)
+	is.Equal(r.Codec(), CodecDeflate)
+	is.Equal(readIDs(is, r), []int64{0, 2}) // only the good blocks
+
+	_, err = Repair(io.Discard, bytes.NewReader(data[:3]), nil)
+	is.True(err != nil) // no header
+}
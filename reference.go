@@ -0,0 +1,203 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Reference refers to a named schema (Record, Enum or Fixed) by its full
+// name. References are how a schema uses a named type which is defined
+// elsewhere, including recursive types.
+//
+// A Reference must be bound to the schema it names before it can be used.
+// Copies of a Reference share the binding, so a Reference can be used inside
+// the schema it refers to and bound afterwards.
+type Reference struct {
+	Name   string
+	target *Schema
+}
+
+// NewReference returns an unbound Reference to the schema with full name name.
+func NewReference(name string) Reference {
+	return Reference{Name: name, target: new(Schema)}
+}
+
+// Bind binds r, and every copy of it, to the named schema s. The full name of
+// s must match.
+func (r Reference) Bind(s Schema) error {
+	if r.target == nil {
+		return fmt.Errorf(`reference "%s" was not created with NewReference`, r.Name)
+	}
+	n, ok := s.(NamedSchema)
+	if !ok {
+		return fmt.Errorf(`cannot bind reference "%s" to unnamed type "%s"`, r.Name, s.Type())
+	}
+	if n.Fullname() != r.Name {
+		return fmt.Errorf(`cannot bind reference "%s" to "%s"`, r.Name, n.Fullname())
+	}
+	*r.target = s
+	return nil
+}
+
+// Target returns the schema r is bound to, or nil if it is unbound.
+func (r Reference) Target() Schema {
+	if r.target == nil {
+		return nil
+	}
+	return *r.target
+}
+
+// Type returns the type of the referenced schema, or "reference" if r is
+// unbound.
+func (r Reference) Type() string {
+	if t := r.Target(); t != nil {
+		return t.Type()
+	}
+	return "reference"
+}
+
+// Valid checks that the name is valid and that r is bound. The referenced
+// schema is not checked, as it may contain r.
+func (r Reference) Valid() error {
+	if err := validFullname(r.Name); err != nil {
+		return err
+	}
+	if r.Target() == nil {
+		return fmt.Errorf(`reference "%s" is unbound`, r.Name)
+	}
+	return nil
+}
+
+// Validate checks if value conforms to the referenced schema.
+func (r Reference) Validate(v interface{}) error {
+	t := r.Target()
+	if t == nil {
+		return fmt.Errorf(`validation aborted, reference "%s" is unbound`, r.Name)
+	}
+	return t.Validate(v)
+}
+
+// GetNameFields returns the NameFields of the referenced schema. If r is
+// unbound, they are derived from the name.
+func (r Reference) GetNameFields() NameFields {
+	if n, ok := r.Target().(NamedSchema); ok {
+		return n.GetNameFields()
+	}
+	if i := strings.LastIndexByte(r.Name, '.'); i >= 0 {
+		return NameFields{Name: r.Name[i+1:], Namespace: r.Name[:i]}
+	}
+	return NameFields{Name: r.Name}
+}
+
+// Fullname returns the full name of the referenced schema.
+func (r Reference) Fullname() string {
+	return r.Name
+}
+
+// MarshalJSON marshals the reference as its full name.
+func (r Reference) MarshalJSON() ([]byte, error) {
+	if r.Name == "" {
+		return nil, errors.New(`reference name cannot be empty`)
+	}
+	return json.Marshal(r.Name)
+}
+
+// resolve returns the schema s refers to if it is a bound Reference, or s
+// itself otherwise.
+// Resolve returns the schema which s, if it is a bound Reference, refers to,
+// following references to references, or else s.
+func Resolve(s Schema) Schema {
+	return resolve(s)
+}
+
+func resolve(s Schema) Schema {
+	for {
+		r, ok := s.(Reference)
+		if !ok || r.Target() == nil {
+			return s
+		}
+		s = r.Target()
+	}
+}
+
+// validFullname checks a full name: a name optionally preceded by a
+// dot-separated namespace.
+func validFullname(fullname string) error {
+	if fullname == "" {
+		return errors.New(`name cannot be empty`)
+	}
+	for _, part := range strings.Split(fullname, ".") {
+		if !nameRegex.MatchString(part) {
+			return fmt.Errorf(`"%s" is an invalid name`, fullname)
+		}
+	}
+	return nil
+}
+
+// Standalone returns s with every named type defined where it is first used,
+// so that it can be marshaled as a complete schema declaration. References
+// to types not yet defined are replaced by their targets, and later
+// definitions of a type already defined are replaced by References.
+func Standalone(s Schema) (Schema, error) {
+	return standalone(s, map[string]bool{})
+}
+
+func standalone(s Schema, defined map[string]bool) (Schema, error) {
+	if n, ok := s.(NamedSchema); ok {
+		if defined[n.Fullname()] {
+			if r, ok := s.(Reference); ok {
+				return r, nil
+			}
+			r := NewReference(n.Fullname())
+			return r, r.Bind(s)
+		}
+	}
+	switch t := s.(type) {
+	case Reference:
+		if t.Target() == nil {
+			return nil, fmt.Errorf(`reference "%s" is unbound`, t.Name)
+		}
+		return standalone(t.Target(), defined)
+	case Record:
+		defined[t.Fullname()] = true
+		fields := make([]Field, len(t.Fields))
+		for i, f := range t.Fields {
+			ft, err := standalone(f.Type, defined)
+			if err != nil {
+				return nil, err
+			}
+			f.Type = ft
+			fields[i] = f
+		}
+		t.Fields = fields
+		return t, nil
+	case Enum:
+		defined[t.Fullname()] = true
+	case Fixed:
+		defined[t.Fullname()] = true
+	case Array:
+		items, err := standalone(t.Items, defined)
+		t.Items = items
+		return t, err
+	case Map:
+		values, err := standalone(t.Values, defined)
+		t.Values = values
+		return t, err
+	case Union:
+		u := make(Union, len(t))
+		for i, b := range t {
+			var err error
+			if u[i], err = standalone(b, defined); err != nil {
+				return nil, err
+			}
+		}
+		return u, nil
+	case Logical:
+		underlying, err := standalone(t.Schema, defined)
+		t.Schema = underlying
+		return t, err
+	}
+	return s, nil
+}
@@ -0,0 +1,75 @@
+package avro
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReference(t *testing.T) {
+	is := is.New(t)
+
+	ref := NewReference("test.Node")
+	is.True(ref.Valid() != nil) // unbound reference is invalid
+	is.Equal(ref.Type(), "reference")
+	is.Equal(ref.GetNameFields(), NameFields{Name: "Node", Namespace: "test"})
+
+	node := Record{
+		NameFields: NameFields{Name: "Node", Namespace: "test"},
+		Fields: []Field{
+			{Name: "value", Type: Long},
+			{Name: "next", Type: Union{Null, ref}},
+		},
+	}
+	is.True(ref.Bind(Int) != nil)                                           // cannot bind unnamed type
+	is.True(ref.Bind(Record{NameFields: NameFields{Name: "Other"}}) != nil) // name must match
+	is.NoErr(ref.Bind(node))
+	is.NoErr(ref.Valid())
+	is.Equal(ref.Type(), "record")                          // type of target
+	is.Equal(Resolve(ref).(Record).Fullname(), "test.Node") // resolves to the target
+	is.Equal(Resolve(Long), Long)                           // other schemas are themselves
+	is.NoErr(node.Valid())
+
+	v := map[string]interface{}{
+		"value": 1,
+		"next":  map[string]interface{}{"value": 2, "next": nil},
+	}
+	is.NoErr(node.Validate(v)) // validates through the reference
+	v["next"] = map[string]interface{}{"value": "x"}
+	is.True(node.Validate(v) != nil) // invalid nested value
+
+	b, err := ref.MarshalJSON()
+	is.NoErr(err)
+	is.Equal(string(b), `"test.Node"`)              // marshals as its name
+	is.True(Reference{Name: "x"}.Bind(node) != nil) // not created with NewReference
+}
+
+func TestStandalone(t *testing.T) {
+	is := is.New(t)
+
+	kind := Enum{NameFields: NameFields{Name: "Kind"}, Symbols: []string{"A"}}
+	ref := NewReference("Kind")
+	is.NoErr(ref.Bind(kind))
+	r := Record{
+		NameFields: NameFields{Name: "R"},
+		Fields: []Field{
+			{Name: "first", Type: ref},
+			{Name: "second", Type: kind},
+		},
+	}
+	s, err := Standalone(r)
+	is.NoErr(err)
+	fields := s.(Record).Fields
+	is.Equal(fields[0].Type, kind)                    // reference to undefined type is replaced
+	is.Equal(fields[1].Type.(Reference).Name, "Kind") // repeated definition becomes a reference
+	is.Equal(r.Fields[0].Type, ref)                   // original is unchanged
+
+	b, err := json.Marshal(s)
+	is.NoErr(err)
+	_, err = SchemaUnmarshalJSON(b)
+	is.NoErr(err) // standalone schema parses
+
+	_, err = Standalone(Array{Items: NewReference("Missing")})
+	is.True(err != nil) // unbound reference
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -58,17 +59,81 @@ func (n NameFields) Valid() error {
 	if !nameRegex.MatchString(n.Name) {
 		return fmt.Errorf(`"%s" is an invalid name`, n.Name)
 	}
+	if n.Namespace != "" && validFullname(n.Namespace) != nil {
+		return fmt.Errorf(`"%s" is an invalid namespace`, n.Namespace)
+	}
+	for _, a := range n.Aliases {
+		if validFullname(a) != nil {
+			return fmt.Errorf(`"%s" is an invalid alias`, a)
+		}
+	}
 	return nil
 }
 
-type Factories map[Reference]func() interface{} // TODO: maybe Schema as key?
+// FullAliases returns the full names of the aliases. Aliases without a
+// namespace are in the namespace of the type.
+func (n NameFields) FullAliases() []string {
+	aliases := make([]string, len(n.Aliases))
+	for i, a := range n.Aliases {
+		aliases[i] = qualify(NameFields{Name: a}, n.Namespace).Fullname()
+	}
+	return aliases
+}
 
+// Factories creates the Go values which logical types are decoded into when
+// the destination is an interface{}, keyed by logical type name. Each
+// function must return a pointer.
+type Factories map[string]func() interface{}
+
+// DefaultFactories is used by Decode.
 var DefaultFactories = Factories{
-	"date": func() interface{} { return new(time.Time) },
+	LogicalDate:            func() interface{} { return new(time.Time) },
+	LogicalTimestampMillis: func() interface{} { return new(time.Time) },
+	LogicalTimestampMicros: func() interface{} { return new(time.Time) },
+	LogicalTimeMillis:      func() interface{} { return new(time.Duration) },
+	LogicalTimeMicros:      func() interface{} { return new(time.Duration) },
 }
 
 // SchemaUnmarshalJSON creates a Schema from an Avro schema declaration.
+// Named types may be used by name after, or within, their definition; such
+// uses become References bound to the definition.
 func SchemaUnmarshalJSON(spec []byte) (Schema, error) {
+	p := newSchemaParser()
+	s, err := p.parse(spec, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.resolve(); err != nil {
+		return nil, err
+	}
+	return s, s.Valid()
+}
+
+// schemaParser parses schema declarations, keeping track of the named types
+// they define and refer to.
+type schemaParser struct {
+	defined  map[string]Schema    // named types by full name
+	known    map[string]Schema    // named types defined elsewhere, as in a SchemaSet
+	refs     map[string]Reference // references by full name
+	defaults []pendingDefault     // field defaults to parse once resolved
+	errors   bool                 // whether "error" declares a record, as in protocols
+}
+
+type pendingDefault struct {
+	field  *Field
+	path   string
+	source string // declaration of the field, in a SchemaSet
+}
+
+func newSchemaParser() *schemaParser {
+	return &schemaParser{
+		defined: map[string]Schema{},
+		refs:    map[string]Reference{},
+	}
+}
+
+// parse parses a schema declaration within the enclosing namespace.
+func (p *schemaParser) parse(spec []byte, namespace string) (Schema, error) {
 	var i interface{}
 	if err := json.Unmarshal(spec, &i); err != nil {
 		return nil, fmt.Errorf("unmarshal schema json: %s", err)
@@ -79,41 +144,253 @@ func SchemaUnmarshalJSON(spec []byte) (Schema, error) {
 		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
 			return Primitive(s), nil
 		}
-		// TODO assume it's a named reference? Use a Registry?
-		return nil, fmt.Errorf(`unsupported type: "%s"`, s)
+		if err := validFullname(s); err != nil {
+			return nil, fmt.Errorf(`unsupported type: "%s"`, s)
+		}
+		return p.reference(s, namespace), nil
 	case []interface{}:
-		return unmarshalAndValidate(spec, Union(nil))
+		var raw []json.RawMessage
+		if err := json.Unmarshal(spec, &raw); err != nil {
+			return nil, fmt.Errorf(`unmarshal union json: "%s"`, err)
+		}
+		u := make(Union, len(raw))
+		for i, r := range raw {
+			var err error
+			if u[i], err = p.parse(r, namespace); err != nil {
+				return nil, fmt.Errorf(`union schema #%d: %s`, i, err)
+			}
+		}
+		return u, nil
 	case map[string]interface{}:
 		// Decode based on "type" field.
-		t, ok := s["type"].(string)
-		if !ok {
+		var t string
+		switch st := s["type"].(type) {
+		case string:
+			t = st
+		case map[string]interface{}, []interface{}:
+			// A type may itself be a schema declaration.
+			var raw struct {
+				Type json.RawMessage `json:"type"`
+			}
+			if err := json.Unmarshal(spec, &raw); err != nil {
+				return nil, err
+			}
+			return p.parse(raw.Type, namespace)
+		default:
 			return nil, invalidAttributeType("type", "string", s["type"])
 		}
 		if t == "" {
 			return nil, ErrMissingRequiredAttribute{"type"}
 		}
+		_, logical := s["logicalType"]
 		switch t {
 		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			if logical {
+				return unmarshalLogical(spec, Primitive(t)), nil
+			}
 			return Primitive(t), nil
 		case "record":
-			return unmarshalAndValidate(spec, Record{})
+			return p.parseRecord(spec, namespace)
+		case "error":
+			if p.errors {
+				return p.parseRecord(spec, namespace)
+			}
 		case "enum":
-			return unmarshalAndValidate(spec, Enum{})
+			var e Enum
+			if err := e.UnmarshalJSON(spec); err != nil {
+				return nil, err
+			}
+			e.NameFields = qualify(e.NameFields, namespace)
+			return e, p.define(e)
+		case "fixed":
+			var f Fixed
+			if err := f.UnmarshalJSON(spec); err != nil {
+				return nil, err
+			}
+			f.NameFields = qualify(f.NameFields, namespace)
+			if err := p.define(f); err != nil {
+				return nil, err
+			}
+			if logical {
+				return unmarshalLogical(spec, f), nil
+			}
+			return f, nil
 		case "array":
-			return unmarshalAndValidate(spec, Array{})
+			var raw struct {
+				Items json.RawMessage `json:"items"`
+			}
+			if err := json.Unmarshal(spec, &raw); err != nil {
+				return nil, fmt.Errorf(`unmarshal array json: "%s"`, err)
+			}
+			items, err := p.parse(raw.Items, namespace)
+			if err != nil {
+				return nil, fmt.Errorf(`array items: %s`, err)
+			}
+			return Array{Items: items}, nil
 		case "map":
-			return unmarshalAndValidate(spec, Map{})
-		case "fixed":
-			return unmarshalAndValidate(spec, Fixed{})
+			var raw struct {
+				Values json.RawMessage `json:"values"`
+			}
+			if err := json.Unmarshal(spec, &raw); err != nil {
+				return nil, fmt.Errorf(`unmarshal map json: "%s"`, err)
+			}
+			values, err := p.parse(raw.Values, namespace)
+			if err != nil {
+				return nil, fmt.Errorf(`map values: %s`, err)
+			}
+			return Map{Values: values}, nil
+		default:
+			// The name of a named type may also be given as "type".
+			if validFullname(t) == nil {
+				return p.reference(t, namespace), nil
+			}
 		}
 		return nil, ErrInvalidValue{"type", t}
 	}
 	return nil, errors.New("the provided avro spec was not valid json")
 }
 
-func unmarshalAndValidate(spec []byte, s Schema) (Schema, error) {
-	if err := json.Unmarshal(spec, s); err != nil {
-		return nil, fmt.Errorf("unmarshal %s json: %s", s.Type(), err)
+// jsonField is a record field or message parameter as declared in JSON.
+type jsonField struct {
+	Name    string          `json:"name"`
+	Doc     string          `json:"doc"`
+	Type    json.RawMessage `json:"type"`
+	Default json.RawMessage `json:"default"` // nil if absent, "null" if null
+	Order   string          `json:"order"`
+	Aliases []string        `json:"aliases"`
+}
+
+func (p *schemaParser) parseRecord(spec []byte, namespace string) (Schema, error) {
+	var raw struct {
+		NameFields
+		Doc    string      `json:"doc"`
+		Fields []jsonField `json:"fields"`
 	}
-	return s, s.Valid()
+	if err := json.Unmarshal(spec, &raw); err != nil {
+		return nil, fmt.Errorf(`unmarshal record json: "%s"`, err)
+	}
+	if raw.Fields == nil {
+		return nil, ErrMissingRequiredAttribute{"fields"}
+	}
+	r := Record{
+		NameFields: qualify(raw.NameFields, namespace),
+		Doc:        raw.Doc,
+		Fields:     make([]Field, len(raw.Fields)),
+	}
+	// Define the record first, so its fields can refer to it.
+	if err := p.define(r); err != nil {
+		return nil, err
+	}
+	return r, p.parseFields(raw.Fields, r.Fields, r.Namespace, r.Fullname())
+}
+
+// parseFields parses the fields of owner into fields, which must have the
+// same length as raw.
+func (p *schemaParser) parseFields(raw []jsonField, fields []Field, namespace, owner string) error {
+	for i, rf := range raw {
+		if rf.Type == nil {
+			return fmt.Errorf(`field "%s": %s`, rf.Name, ErrMissingRequiredAttribute{"type"})
+		}
+		t, err := p.parse(rf.Type, namespace)
+		if err != nil {
+			return fmt.Errorf(`field "%s": %s`, rf.Name, err)
+		}
+		fields[i] = Field{
+			Name:    rf.Name,
+			Doc:     rf.Doc,
+			Type:    t,
+			Order:   rf.Order,
+			Aliases: rf.Aliases,
+		}
+		if rf.Default != nil {
+			d, err := unmarshalDefault(rf.Default)
+			if err != nil {
+				return fmt.Errorf(`field "%s": %s`, rf.Name, err)
+			}
+			fields[i].Default = &d
+		}
+		p.deferDefault(&fields[i], owner)
+	}
+	return nil
+}
+
+// deferDefault arranges for the default of f to be parsed by resolve.
+func (p *schemaParser) deferDefault(f *Field, owner string) {
+	if f.Default != nil {
+		p.defaults = append(p.defaults, pendingDefault{
+			field: f,
+			path:  owner + fieldPath(f.Name),
+		})
+	}
+}
+
+// qualify splits a dotted name into its namespace and name, or gives the name
+// the enclosing namespace if it has none.
+func qualify(nf NameFields, namespace string) NameFields {
+	if i := strings.LastIndexByte(nf.Name, '.'); i >= 0 {
+		nf.Namespace, nf.Name = nf.Name[:i], nf.Name[i+1:]
+	} else if nf.Namespace == "" {
+		nf.Namespace = namespace
+	}
+	return nf
+}
+
+// define records a named type, which may only be defined once.
+func (p *schemaParser) define(s Schema) error {
+	n := s.(NamedSchema)
+	if err := n.GetNameFields().Valid(); err != nil {
+		return err
+	}
+	name := n.Fullname()
+	if _, ok := p.defined[name]; ok {
+		return fmt.Errorf(`"%s" is defined more than once`, name)
+	}
+	p.defined[name] = s
+	return nil
+}
+
+// reference returns a Reference to a named type. A name without a namespace
+// is looked up in the enclosing namespace and then the null namespace.
+func (p *schemaParser) reference(name, namespace string) Reference {
+	if !strings.Contains(name, ".") && namespace != "" {
+		full := namespace + "." + name
+		if p.isDefined(full) || !p.isDefined(name) {
+			name = full
+		}
+	}
+	r, ok := p.refs[name]
+	if !ok {
+		r = NewReference(name)
+		p.refs[name] = r
+	}
+	return r
+}
+
+func (p *schemaParser) isDefined(name string) bool {
+	_, defined := p.defined[name]
+	_, known := p.known[name]
+	return defined || known
+}
+
+// resolve binds every reference to its definition and then parses field
+// defaults, which may depend on referenced types.
+func (p *schemaParser) resolve() error {
+	for name, r := range p.refs {
+		s, ok := p.defined[name]
+		if !ok {
+			return fmt.Errorf(`unknown type "%s"`, name)
+		}
+		if err := r.Bind(s); err != nil {
+			return err
+		}
+	}
+	for _, d := range p.defaults {
+		v, err := parseDefault(d.field.Type, *d.field.Default)
+		if err != nil {
+			return fmt.Errorf(`default of %s: %s`, d.path, err)
+		}
+		*d.field.Default = v
+	}
+	p.defaults = nil
+	return nil
 }
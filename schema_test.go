@@ -14,6 +14,26 @@ func TestNameRegex(t *testing.T) {
 	is.True(!nameRegex.MatchString(invalidName)) // invalidName does not match nameRegex
 }
 
+func TestNameFields(t *testing.T) {
+	is := is.New(t)
+
+	n := NameFields{Name: "User", Namespace: "com.example", Aliases: []string{"Person", "org.old.User"}}
+	is.NoErr(n.Valid())
+	is.Equal(n.FullAliases(), []string{"com.example.Person", "org.old.User"}) // relative aliases in the namespace
+
+	n.Namespace = "com..example"
+	is.True(n.Valid() != nil) // invalid namespace
+	n.Namespace = "com.1example"
+	is.True(n.Valid() != nil) // invalid namespace part
+	n.Namespace = ""
+	is.Equal(n.FullAliases()[0], "Person") // null namespace
+	n.Aliases = []string{"bad-alias"}
+	is.True(n.Valid() != nil) // invalid alias
+
+	_, err := SchemaUnmarshalJSON([]byte(`{"type": "fixed", "name": "F", "namespace": "a b", "size": 1}`))
+	is.True(err != nil) // namespace checked when parsing
+}
+
 type mockPrimitiveSchema struct {
 	typeFunc     func() string
 	validFunc    func() error
@@ -131,13 +151,89 @@ func TestSchemaUnmarshalJSON(t *testing.T) {
 
 	t.Run("json array to union", func(t *testing.T) {
 		t.Parallel()
+
+		s, err := SchemaUnmarshalJSON([]byte(`["null", {"type": "array", "items": "int"}]`))
+		is.NoErr(err)                               // unmarshal union without error
+		is.Equal(s, Union{Null, Array{Items: Int}}) // returns union of contained schemas
+		_, err = SchemaUnmarshalJSON([]byte(`["int", "int"]`))
+		is.True(err != nil) // invalid union returns error
 	})
 
 	t.Run("json object to primitive", func(t *testing.T) {
 		t.Parallel()
+
+		s, err := SchemaUnmarshalJSON([]byte(`{"type": "long"}`))
+		is.NoErr(err)      // unmarshal valid json object without error
+		is.True(s == Long) // returns correct primitive schema
 	})
 
 	t.Run("json object to complex", func(t *testing.T) {
 		t.Parallel()
+
+		s, err := SchemaUnmarshalJSON([]byte(`{
+			"type": "record", "name": "Node", "namespace": "test",
+			"fields": [
+				{"name": "value", "type": "int", "default": 1},
+				{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}},
+				{"name": "other", "type": "test.Kind", "default": "B"},
+				{"name": "next", "type": ["null", "Node"], "default": null},
+				{"name": "id", "type": {"type": "fixed", "name": "other.Id", "size": 2}, "default": "\u00ff\u0000"}
+			]
+		}`))
+		is.NoErr(err) // unmarshal record without error
+		r := s.(Record)
+		is.Equal(r.Fullname(), "test.Node")
+		is.Equal(*r.Fields[0].Default, int32(1))                  // default parsed for its type
+		is.Equal(r.Fields[1].Type.(Enum).Fullname(), "test.Kind") // namespace of enclosing record
+		ref := r.Fields[2].Type.(Reference)
+		is.Equal(ref.Target().(Enum).Fullname(), "test.Kind") // reference bound to definition
+		next := r.Fields[3].Type.(Union)[1].(Reference)
+		is.Equal(next.Target().(Record).Fullname(), "test.Node")  // recursive reference
+		is.Equal(r.Fields[4].Type.(Fixed).Fullname(), "other.Id") // dotted name has its own namespace
+		is.Equal(*r.Fields[4].Default, []byte{0xff, 0})           // fixed default is ISO-8859-1
+
+		_, err = SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [{"name": "f", "type": "Missing"}]}`))
+		is.True(err != nil) // unknown type returns error
+		_, err = SchemaUnmarshalJSON([]byte(`["null", {"type": "fixed", "name": "F", "size": 1}, {"type": "fixed", "name": "F", "size": 2}]`))
+		is.True(err != nil) // redefined type returns error
+		_, err = SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [{"name": "f", "type": "int", "default": "x"}]}`))
+		is.True(err != nil) // invalid default returns error
 	})
 }
+
+func TestSchemaUnmarshalJSON_defaults(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [
+		{"name": "l", "type": "long", "default": 2},
+		{"name": "f", "type": "float", "default": 0.5},
+		{"name": "b", "type": "bytes", "default": "ÿ"},
+		{"name": "u", "type": ["int", "null"], "default": 1},
+		{"name": "a", "type": {"type": "array", "items": "int"}, "default": [1]},
+		{"name": "m", "type": {"type": "map", "values": "double"}, "default": {"k": 1}},
+		{"name": "r", "type": {"type": "record", "name": "P", "fields": [{"name": "x", "type": "int"}]}, "default": {"x": 1}}
+	]}`))
+	is.NoErr(err)
+	want := []interface{}{
+		int64(2),
+		float32(0.5),
+		[]byte{0xff},
+		int32(1),
+		[]interface{}{int32(1)},
+		map[string]interface{}{"k": float64(1)},
+		map[string]interface{}{"x": int32(1)},
+	}
+	for i, f := range s.(Record).Fields {
+		is.Equal(*f.Default, want[i]) // parsed for the field type
+	}
+
+	for _, field := range []string{
+		`{"name": "l", "type": "long", "default": 1.5}`,
+		`{"name": "u", "type": ["null", "int"], "default": 1}`,
+		`{"name": "a", "type": {"type": "array", "items": "int"}, "default": ["x"]}`,
+		`{"name": "r", "type": {"type": "record", "name": "P", "fields": [{"name": "x", "type": "int"}]}, "default": {}}`,
+	} {
+		_, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [` + field + `]}`))
+		is.True(err != nil) // default not of the field type
+	}
+}
@@ -0,0 +1,235 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SchemaSet holds the named types of many schema declarations, such as a
+// directory of .avsc files whose types refer to each other by full name.
+// References between declarations are bound once the types they name are
+// added, so declarations may be added in any order. A type may be defined by
+// several declarations only if the definitions are the same.
+type SchemaSet struct {
+	defined  map[string]Schema // named types by full name
+	sources  map[string]string // where each named type was first defined
+	refs     []Reference       // references not yet bound
+	defaults []pendingDefault  // field defaults whose types are not yet bound
+}
+
+// NewSchemaSet returns an empty SchemaSet.
+func NewSchemaSet() *SchemaSet {
+	return &SchemaSet{defined: map[string]Schema{}, sources: map[string]string{}}
+}
+
+// Add parses a schema declaration, which may refer to the named types of the
+// set and to types added later, and returns it. The source, such as a file
+// name, is used in errors. Declarations which define a type of the set
+// differently are not added.
+func (ss *SchemaSet) Add(spec []byte, source string) (Schema, error) {
+	p := newSchemaParser()
+	p.known = ss.defined
+	s, err := p.parse(spec, "")
+	if err != nil {
+		return nil, fmt.Errorf(`%s: %s`, source, err)
+	}
+	for i := range p.defaults {
+		p.defaults[i].source = source
+	}
+	// Bind the references of the declaration to its own types first, so that
+	// types it defines again can be compared with the set's.
+	var refs []Reference
+	for _, r := range p.refs {
+		refs = append(refs, r)
+	}
+	refs, defaults, _, err := bindPending(refs, p.defaults, p.defined, ss.defined)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	added := map[string]Schema{}
+	for name := range p.defined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if prev, ok := ss.defined[name]; !ok {
+			added[name] = p.defined[name]
+		} else if !SchemasEqual(prev, p.defined[name]) {
+			return nil, fmt.Errorf(`%s: "%s" conflicts with its definition in %s`, source, name, ss.sources[name])
+		}
+	}
+	// Types defined again are used as first defined.
+	for name, r := range p.refs {
+		if s, ok := ss.defined[name]; ok {
+			if err := r.Bind(s); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// The references and defaults of the set may now be bound to the added
+	// types, which are only added if all of them can be.
+	refs, defaults, bound, err := bindPending(append(ss.refs, refs...), append(ss.defaults, defaults...), ss.defined, added)
+	if err != nil {
+		for _, r := range bound {
+			*r.target = nil
+		}
+		// A default which cannot be parsed is left as it is, so that it does
+		// not stop other types from being added.
+		var de pendingDefaultError
+		if errors.As(err, &de) {
+			ss.defaults = dropDefault(ss.defaults, de.field)
+		}
+		return nil, err
+	}
+	for name, t := range added {
+		ss.defined[name] = t
+		ss.sources[name] = source
+	}
+	ss.refs, ss.defaults = refs, defaults
+	return s, nil
+}
+
+// dropDefault returns defaults without the default of f.
+func dropDefault(defaults []pendingDefault, f *Field) []pendingDefault {
+	var kept []pendingDefault
+	for _, d := range defaults {
+		if d.field != f {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// AddFile adds the schema declaration in the file at path.
+func (ss *SchemaSet) AddFile(path string) (Schema, error) {
+	spec, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ss.Add(spec, path)
+}
+
+// AddDir adds every .avsc file in dir and its subdirectories, in lexical
+// order.
+func (ss *SchemaSet) AddDir(dir string) error {
+	return filepath.WalkDir(dir, func(path string, e fs.DirEntry, err error) error {
+		if err != nil || e.IsDir() || !strings.HasSuffix(path, ".avsc") {
+			return err
+		}
+		_, err = ss.AddFile(path)
+		return err
+	})
+}
+
+// Names returns the full names of the types of the set, sorted.
+func (ss *SchemaSet) Names() []string {
+	names := make([]string, 0, len(ss.defined))
+	for name := range ss.defined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named type with full name name. It returns an error if the
+// type is not in the set, refers to types not in the set or is invalid.
+func (ss *SchemaSet) Get(name string) (Schema, error) {
+	s, ok := ss.defined[name]
+	if !ok {
+		return nil, fmt.Errorf(`unknown type "%s"`, name)
+	}
+	if missing := unboundName(s); missing != "" {
+		return nil, fmt.Errorf(`type "%s" refers to unknown type "%s"`, name, missing)
+	}
+	return s, s.Valid()
+}
+
+// Resolve checks that every type referred to by the set is in it.
+func (ss *SchemaSet) Resolve() error {
+	if len(ss.refs) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var names []string
+	for _, r := range ss.refs {
+		if !seen[r.Name] {
+			seen[r.Name] = true
+			names = append(names, `"`+r.Name+`"`)
+		}
+	}
+	sort.Strings(names)
+	return fmt.Errorf(`unknown types %s`, strings.Join(names, ", "))
+}
+
+// bindPending binds the references in refs to the types of the first of
+// defined which has them, and then parses the defaults whose types are
+// bound. It returns the references and defaults left, and the references it
+// bound. Defaults are only set if they can all be parsed; otherwise the
+// error is a pendingDefaultError.
+func bindPending(refs []Reference, defaults []pendingDefault, defined ...map[string]Schema) (unbound []Reference, pending []pendingDefault, bound []Reference, err error) {
+	for _, r := range refs {
+		var s Schema
+		for _, d := range defined {
+			if s = d[r.Name]; s != nil {
+				break
+			}
+		}
+		if s == nil {
+			unbound = append(unbound, r)
+			continue
+		}
+		if err := r.Bind(s); err != nil {
+			return nil, nil, bound, err
+		}
+		bound = append(bound, r)
+	}
+	var parsed []pendingDefault
+	var values []interface{}
+	for _, d := range defaults {
+		if unboundName(d.field.Type) != "" {
+			pending = append(pending, d)
+			continue
+		}
+		v, err := parseDefault(d.field.Type, *d.field.Default)
+		if err != nil {
+			return nil, nil, bound, pendingDefaultError{d, err}
+		}
+		parsed = append(parsed, d)
+		values = append(values, v)
+	}
+	for i, d := range parsed {
+		*d.field.Default = values[i]
+	}
+	return unbound, pending, bound, nil
+}
+
+// pendingDefaultError is the error parsing a pending default.
+type pendingDefaultError struct {
+	pendingDefault
+	err error
+}
+
+func (e pendingDefaultError) Error() string {
+	return fmt.Sprintf(`%s: default of %s: %s`, e.source, e.path, e.err)
+}
+
+var errUnbound = errors.New(`unbound reference`)
+
+// unboundName returns the name of an unbound Reference in s, or "".
+func unboundName(s Schema) string {
+	var name string
+	_ = Walk(s, func(n Node) error {
+		if r, ok := n.Schema.(Reference); ok && r.Target() == nil {
+			name = r.Name
+			return errUnbound
+		}
+		return nil
+	})
+	return name
+}
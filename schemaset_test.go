@@ -0,0 +1,89 @@
+package avro
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSchemaSet(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		// Sorts first, so its references are bound later.
+		"a_order.avsc": `{"type": "record", "name": "shop.Order", "fields": [
+			{"name": "total", "type": "shared.Money", "default": {"amount": 0, "currency": "EUR"}},
+			{"name": "ship", "type": "shared.Address"}
+		]}`,
+		"shared/money.avsc": `{"type": "record", "name": "Money", "namespace": "shared", "fields": [
+			{"name": "amount", "type": "long"},
+			{"name": "currency", "type": {"type": "enum", "name": "Currency", "symbols": ["EUR", "USD"]}}
+		]}`,
+		"shared/address.avsc": `{"type": "record", "name": "shared.Address", "fields": [{"name": "city", "type": "string"}]}`,
+		"notes.txt":           `not a schema`,
+	}
+	for name, spec := range files {
+		path := filepath.Join(dir, name)
+		is.NoErr(os.MkdirAll(filepath.Dir(path), 0o755))
+		is.NoErr(os.WriteFile(path, []byte(spec), 0o644))
+	}
+
+	ss := NewSchemaSet()
+	is.NoErr(ss.AddDir(dir))
+	is.NoErr(ss.Resolve()) // every reference bound
+	is.Equal(ss.Names(), []string{"shared.Address", "shared.Currency", "shared.Money", "shop.Order"})
+	s, err := ss.Get("shop.Order")
+	is.NoErr(err)
+	f, _ := s.(Record).GetField("total")
+	is.Equal(*f.Default, map[string]interface{}{"amount": int64(0), "currency": "EUR"}) // default parsed once bound
+	b, err := Encode(s, map[string]interface{}{"total": *f.Default, "ship": map[string]interface{}{"city": "x"}})
+	is.NoErr(err)
+	is.Equal(b, []byte{0, 0, 2, 'x'}) // usable schema
+
+	_, err = ss.Add([]byte(`{"type": "record", "name": "shared.Address", "fields": [{"name": "city", "type": "string"}]}`), "same.avsc")
+	is.NoErr(err) // same definition again
+	_, err = ss.Add([]byte(`{"type": "record", "name": "shared.Address", "fields": [{"name": "town", "type": "string"}]}`), "other.avsc")
+	is.True(err != nil) // conflicting definition
+	_, err = ss.Add([]byte(`{"type": "record", "name": "New", "fields": [{"name": "a", "type": {"type": "enum", "name": "shared.Currency", "symbols": ["EUR"]}}]}`), "new.avsc")
+	is.True(err != nil) // conflicting nested definition
+	_, err = ss.Get("New")
+	is.True(err != nil) // not added
+
+	_, err = ss.Add([]byte(`{"type": "record", "name": "Pending", "fields": [{"name": "a", "type": "Later"}]}`), "pending.avsc")
+	is.NoErr(err)
+	is.Equal(ss.Resolve().Error(), `unknown types "Later"`)
+	_, err = ss.Get("Pending")
+	is.Equal(err.Error(), `type "Pending" refers to unknown type "Later"`)
+	_, err = ss.Add([]byte(`{"type": "fixed", "name": "Later", "size": 2}`), "later.avsc")
+	is.NoErr(err)
+	is.NoErr(ss.Resolve()) // bound once added
+	_, err = ss.Get("Pending")
+	is.NoErr(err)
+
+	_, err = ss.Get("Nope")
+	is.True(err != nil) // unknown type
+}
+
+func TestSchemaSet_badPendingDefault(t *testing.T) {
+	is := is.New(t)
+
+	ss := NewSchemaSet()
+	_, err := ss.Add([]byte(`{"type": "record", "name": "a.X", "fields": [{"name": "y", "type": "a.Y", "default": "nope"}]}`), "x.avsc")
+	is.NoErr(err)
+	y := []byte(`{"type": "enum", "name": "a.Y", "symbols": ["A"]}`)
+	_, err = ss.Add(y, "y.avsc")
+	is.Equal(err.Error(), `x.avsc: default of a.X.y: symbol "nope" does not exist in the enum`) // blamed on the declaration of the default
+	is.Equal(ss.Names(), []string{"a.X"})                                                       // not added
+	is.Equal(ss.Resolve().Error(), `unknown types "a.Y"`)                                       // and not bound
+
+	_, err = ss.Add(y, "y.avsc")
+	is.NoErr(err) // the bad default no longer stops it
+	is.NoErr(ss.Resolve())
+	_, err = ss.Get("a.X")
+	is.True(err != nil) // whose default is still invalid
+	_, err = ss.Add([]byte(`{"type": "fixed", "name": "a.Z", "size": 1}`), "z.avsc")
+	is.NoErr(err)
+}
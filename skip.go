@@ -0,0 +1,190 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Skip reads past the binary encoding of a value of schema s from r without
+// decoding it. Array and map blocks written with their size in bytes are
+// skipped whole. It returns io.EOF if r has no more data, so that a stream of
+// values can be counted by skipping until io.EOF. r is read one byte at a time
+// unless it is an io.ByteReader, so that nothing past the value is read.
+// Values encoded in no bytes, such as nulls, can only be told from the end of
+// r if it is an io.ByteScanner; Skip returns an error for them otherwise.
+func Skip(s Schema, r io.Reader) error {
+	if s == nil {
+		return errors.New(`cannot skip with nil schema`)
+	}
+	if zeroWidth(s, map[string]bool{}) {
+		bs, ok := r.(io.ByteScanner)
+		if !ok {
+			return fmt.Errorf(`cannot find the end of values of schema "%s" without an io.ByteScanner`, s.Type())
+		}
+		if _, err := bs.ReadByte(); err != nil {
+			return err
+		}
+		return bs.UnreadByte()
+	}
+	sk := skipper{r: r}
+	sk.br, _ = r.(io.ByteReader)
+	err := sk.skip(s)
+	if err == io.EOF && sk.n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// skipper skips values read from r, as decoder.skip does for values in
+// memory.
+type skipper struct {
+	r  io.Reader
+	br io.ByteReader // r, if it is one
+	n  int64         // bytes read
+	b  [1]byte
+}
+
+func (sk *skipper) ReadByte() (byte, error) {
+	if sk.br != nil {
+		c, err := sk.br.ReadByte()
+		if err == nil {
+			sk.n++
+		}
+		return c, err
+	}
+	if _, err := io.ReadFull(sk.r, sk.b[:]); err != nil {
+		return 0, err
+	}
+	sk.n++
+	return sk.b[0], nil
+}
+
+func (sk *skipper) readLong() (int64, error) {
+	return binary.ReadVarint(sk)
+}
+
+func (sk *skipper) discard(n int64) error {
+	if n < 0 {
+		return fmt.Errorf(`invalid length %d`, n)
+	}
+	m, err := io.CopyN(io.Discard, sk.r, n)
+	sk.n += m
+	return err
+}
+
+func (sk *skipper) skip(s Schema) error {
+	switch s := resolve(s).(type) {
+	case Primitive:
+		switch s {
+		case Null:
+			return nil
+		case Boolean:
+			return sk.discard(1)
+		case Int, Long:
+			_, err := sk.readLong()
+			return err
+		case Float:
+			return sk.discard(4)
+		case Double:
+			return sk.discard(8)
+		case Bytes, String:
+			n, err := sk.readLong()
+			if err != nil {
+				return err
+			}
+			return sk.discard(n)
+		}
+	case Record:
+		for _, f := range s.Fields {
+			if err := sk.skip(f.Type); err != nil {
+				return err
+			}
+		}
+		return nil
+	case Enum:
+		_, err := sk.readLong()
+		return err
+	case Fixed:
+		return sk.discard(int64(s.Size))
+	case Array:
+		return sk.skipBlocks(s.Items, nil)
+	case Map:
+		return sk.skipBlocks(s.Values, String)
+	case Union:
+		i, err := sk.readLong()
+		if err != nil {
+			return err
+		}
+		if i < 0 || i >= int64(len(s)) {
+			return fmt.Errorf(`union has no schema at index %d`, i)
+		}
+		return sk.skip(s[i])
+	case Logical:
+		return sk.skip(s.Schema)
+	}
+	return fmt.Errorf(`cannot skip schema type "%s"`, s.Type())
+}
+
+// skipBlocks skips array or map blocks as decoder.skipBlocks does.
+func (sk *skipper) skipBlocks(items Schema, key Schema) error {
+	// Items encoded in no bytes are skipped whatever their count.
+	none := key == nil && zeroWidth(items, map[string]bool{})
+	for {
+		n, err := sk.readLong()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if n < 0 {
+			size, err := sk.readLong()
+			if err != nil {
+				return err
+			}
+			if err := sk.discard(size); err != nil {
+				return err
+			}
+			continue
+		}
+		if none {
+			continue
+		}
+		for i := int64(0); i < n; i++ {
+			if key != nil {
+				if err := sk.skip(key); err != nil {
+					return err
+				}
+			}
+			if err := sk.skip(items); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// zeroWidth reports whether values of s are encoded in no bytes.
+func zeroWidth(s Schema, seen map[string]bool) bool {
+	switch s := resolve(s).(type) {
+	case Primitive:
+		return s == Null
+	case Fixed:
+		return s.Size == 0
+	case Logical:
+		return zeroWidth(s.Schema, seen)
+	case Record:
+		if seen[s.Fullname()] {
+			return false
+		}
+		seen[s.Fullname()] = true
+		for _, f := range s.Fields {
+			if !zeroWidth(f.Type, seen) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/matryer/is"
+)
+
+func TestSkip(t *testing.T) {
+	is := is.New(t)
+
+	s := Record{
+		NameFields: NameFields{Name: "R"},
+		Fields: []Field{
+			{Name: "a", Type: Union{Null, String}},
+			{Name: "b", Type: Map{Values: Array{Items: Double}}},
+			{Name: "c", Type: Fixed{NameFields: NameFields{Name: "F"}, Size: 2}},
+		},
+	}
+	v := map[string]interface{}{
+		"a": GenericUnion{Index: 1, Value: "x"},
+		"b": map[string]interface{}{"k": []interface{}{1.0, 2.0}},
+		"c": GenericFixed{1, 2},
+	}
+	one, err := Encode(s, v)
+	is.NoErr(err)
+	var sized bytes.Buffer
+	e, err := NewEncoder(&sized, s, WithSizedBlocks())
+	is.NoErr(err)
+	is.NoErr(e.Encode(v))
+	is.NoErr(e.Flush())
+
+	data := append(append([]byte{}, one...), sized.Bytes()...)
+	r := bytes.NewReader(append(data, 42))
+	is.NoErr(Skip(s, r))
+	is.NoErr(Skip(s, r))
+	is.Equal(r.Len(), 1) // stops after the values
+
+	r = bytes.NewReader(data)
+	is.NoErr(Skip(s, iotest.OneByteReader(r)))
+	is.Equal(r.Len(), sized.Len()) // reads nothing past the value of a plain reader
+
+	n := 0
+	r = bytes.NewReader(data)
+	for {
+		err := Skip(s, r)
+		if err == io.EOF {
+			break
+		}
+		is.NoErr(err)
+		n++
+	}
+	is.Equal(n, 2) // counts values
+
+	is.Equal(Skip(s, bytes.NewReader(one[:len(one)-1])), io.ErrUnexpectedEOF) // truncated value
+	is.True(Skip(Union{Null}, bytes.NewReader([]byte{2})) != nil)             // no branch
+	is.True(Skip(nil, r) != nil)                                              // nil schema
+}
+
+func TestSkip_zeroWidth(t *testing.T) {
+	is := is.New(t)
+
+	empty := Record{NameFields: NameFields{Name: "Empty"}, Fields: []Field{{Name: "n", Type: Null}}}
+	for _, s := range []Schema{Null, empty} {
+		is.Equal(Skip(s, bytes.NewReader(nil)), io.EOF) // ends with the data
+		r := bytes.NewReader([]byte{42})
+		is.NoErr(Skip(s, r))
+		is.Equal(r.Len(), 1)                                                // reads nothing
+		is.True(Skip(s, iotest.OneByteReader(bytes.NewReader(nil))) != nil) // end unknown
+	}
+	is.Equal(Skip(Array{Items: empty}, bytes.NewReader(nil)), io.EOF) // arrays read their block counts
+}
+
+func TestSkip_blockCounts(t *testing.T) {
+	is := is.New(t)
+
+	huge := binary.AppendVarint(nil, 1<<60)
+	is.NoErr(Skip(Array{Items: Null}, bytes.NewReader(append(huge, 0))))           // items encoded in no bytes skipped at once
+	is.Equal(Skip(Array{Items: Null}, bytes.NewReader(huge)), io.ErrUnexpectedEOF) // then the next count is missing
+	is.Equal(Skip(Array{Items: Long}, bytes.NewReader(huge)), io.ErrUnexpectedEOF) // items read until the data ends
+	is.Equal(Skip(Map{Values: Null}, bytes.NewReader(huge)), io.ErrUnexpectedEOF)  // as are map keys
+}
@@ -0,0 +1,104 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// Split is a byte range of an object container file, from Start up to End.
+// Splits are read as in Hadoop: a block belongs to the split in which the
+// sync marker before it starts, so that splits covering a file read each
+// block once.
+type Split struct {
+	Start int64
+	End   int64
+}
+
+// Splits divides a container file of size bytes into splits of splitSize
+// bytes, the last of which may be shorter, to be read in parallel with
+// Reader.ReadSplit. A splitSize of 0 or less makes a single split.
+func Splits(size, splitSize int64) []Split {
+	if splitSize <= 0 {
+		splitSize = size
+	}
+	var splits []Split
+	for start := int64(0); start < size; start += splitSize {
+		end := start + splitSize
+		if end > size {
+			end = size
+		}
+		splits = append(splits, Split{Start: start, End: end})
+	}
+	return splits
+}
+
+// SeekSync moves to the first block after a sync marker starting at or after
+// offset, or to the end of the file if there is none, so that reading can
+// start from an arbitrary byte offset. It reads to the end of the file, even
+// after ReadSplit. The file must be read from an io.Seeker.
+func (r *Reader) SeekSync(offset int64) error {
+	s, ok := r.src.r.(io.Seeker)
+	if !ok {
+		return errors.New(`cannot seek a reader which is not an io.Seeker`)
+	}
+	// The header ends with a sync marker, which comes before the first block.
+	if offset < r.header {
+		offset = r.header
+	}
+	if _, err := s.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.src.n = offset
+	r.r.Reset(r.src)
+	r.block, r.left, r.end = decoder{}, 0, 0
+	return r.scanSync()
+}
+
+// scanSync reads up to the end of the next sync marker, or of the file.
+func (r *Reader) scanSync() error {
+	for {
+		if _, err := r.r.Peek(syncSize); err == io.EOF {
+			_, err = r.r.Discard(r.r.Buffered())
+			return err
+		} else if err != nil {
+			return err
+		}
+		b, _ := r.r.Peek(r.r.Buffered())
+		if i := bytes.Index(b, r.sync[:]); i >= 0 {
+			_, err := r.r.Discard(i + syncSize)
+			return err
+		}
+		// The marker may start in the last bytes.
+		if _, err := r.r.Discard(len(b) - syncSize + 1); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadSplit moves to the first block of split s, and makes the Reader
+// return io.EOF after its last block.
+func (r *Reader) ReadSplit(s Split) error {
+	if err := r.SeekSync(s.Start); err != nil {
+		return err
+	}
+	r.end = s.End
+	return nil
+}
+
+// offset returns the offset in the file of the next byte to read.
+func (r *Reader) offset() int64 {
+	return r.src.n - int64(r.r.Buffered())
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
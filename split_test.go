@@ -0,0 +1,78 @@
+package avro
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSplits(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(Splits(10, 4), []Split{{0, 4}, {4, 8}, {8, 10}})
+	is.Equal(Splits(10, 0), []Split{{0, 10}}) // single split
+	is.Equal(len(Splits(0, 4)), 0)            // empty file
+}
+
+func TestReader_ReadSplit(t *testing.T) {
+	is := is.New(t)
+
+	var b bytes.Buffer
+	w, err := NewWriter(&b, containerSchema, WithCodec(CodecDeflate), WithBlockSize(20))
+	is.NoErr(err)
+	for i := int64(0); i < 50; i++ {
+		is.NoErr(w.Append(containerItem{ID: i, Name: "item"}))
+	}
+	is.NoErr(w.Close())
+	data := b.Bytes()
+
+	readAll := func(r *Reader) []int64 {
+		var ids []int64
+		for {
+			var item containerItem
+			if err := r.Decode(&item); err == io.EOF {
+				return ids
+			} else {
+				is.NoErr(err)
+			}
+			ids = append(ids, item.ID)
+		}
+	}
+	for _, size := range []int64{1, 7, 50, 1000} {
+		var ids []int64
+		nonEmpty := 0
+		for _, s := range Splits(int64(len(data)), size) {
+			r, err := NewReader(bytes.NewReader(data))
+			is.NoErr(err)
+			is.NoErr(r.ReadSplit(s))
+			got := readAll(r)
+			if len(got) > 0 {
+				nonEmpty++
+			}
+			ids = append(ids, got...)
+		}
+		is.Equal(len(ids), 50) // every value once
+		for i, id := range ids {
+			is.Equal(id, int64(i)) // in order
+		}
+		if size < 100 {
+			is.True(nonEmpty > 1) // spread across splits
+		}
+	}
+
+	r, err := NewReader(bytes.NewReader(data))
+	is.NoErr(err)
+	is.NoErr(r.SeekSync(int64(len(data) / 2)))
+	ids := readAll(r)
+	is.True(len(ids) > 0 && len(ids) < 50) // resynchronized in the middle
+	is.Equal(ids[len(ids)-1], int64(49))   // to the end
+
+	is.NoErr(r.SeekSync(0))
+	is.Equal(len(readAll(r)), 50) // from the first block
+
+	r, err = NewReader(bytes.NewBuffer(data))
+	is.NoErr(err)
+	is.True(r.SeekSync(0) != nil) // not seekable
+}
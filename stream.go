@@ -0,0 +1,120 @@
+package avro
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// DefaultBufferSize is the number of encoded bytes an Encoder buffers before
+// writing them.
+const DefaultBufferSize = 32 << 10
+
+// EncoderOption configures an Encoder.
+type EncoderOption func(*encoderOptions)
+
+type encoderOptions struct {
+	bufferSize  int
+	blockItems  int
+	sizedBlocks bool
+}
+
+// WithBufferSize sets the number of encoded bytes at which an Encoder writes
+// its buffer, DefaultBufferSize by default. A size of 0 writes each value as
+// soon as it is encoded.
+func WithBufferSize(size int) EncoderOption {
+	return func(o *encoderOptions) { o.bufferSize = size }
+}
+
+// WithCollectionBlockSize splits arrays and maps into blocks of at most items
+// items. By default each is written as a single block.
+func WithCollectionBlockSize(items int) EncoderOption {
+	return func(o *encoderOptions) { o.blockItems = items }
+}
+
+// WithSizedBlocks writes the count of each array and map block as a negative
+// number followed by the size of the block in bytes, so that readers can
+// skip blocks without decoding their items.
+func WithSizedBlocks() EncoderOption {
+	return func(o *encoderOptions) { o.sizedBlocks = true }
+}
+
+// Encoder writes the binary encodings of values of a schema to an io.Writer,
+// one after the other. Values are encoded into a buffer which is reused, and
+// written once it reaches the buffer size or when Flush is called.
+type Encoder struct {
+	w          io.Writer
+	schema     Schema
+	enc        encoder
+	bufferSize int
+	count      int64 // values encoded
+	written    int64 // bytes written to w
+}
+
+// NewEncoder returns an Encoder of values of schema s to w.
+func NewEncoder(w io.Writer, s Schema, opts ...EncoderOption) (*Encoder, error) {
+	if s == nil {
+		return nil, errors.New(`cannot encode with nil schema`)
+	}
+	o := encoderOptions{bufferSize: DefaultBufferSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Encoder{
+		w:          w,
+		schema:     s,
+		enc:        encoder{blockItems: o.blockItems, sizedBlocks: o.sizedBlocks},
+		bufferSize: o.bufferSize,
+	}, nil
+}
+
+// Encode encodes v, which may be of any Go type accepted by Encode, and
+// writes the buffer once it reaches the buffer size. Nothing of v is kept if
+// it cannot be encoded.
+func (e *Encoder) Encode(v interface{}) error {
+	n := len(e.enc.buf)
+	if err := e.enc.encode(e.schema, reflect.ValueOf(v)); err != nil {
+		e.enc.buf = e.enc.buf[:n]
+		return err
+	}
+	e.count++
+	if len(e.enc.buf) >= e.bufferSize {
+		return e.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered bytes to the underlying writer.
+func (e *Encoder) Flush() error {
+	if len(e.enc.buf) == 0 {
+		return nil
+	}
+	n, err := e.w.Write(e.enc.buf)
+	e.written += int64(n)
+	if err == nil && n < len(e.enc.buf) {
+		err = io.ErrShortWrite
+	}
+	// Keep what was not written for the next Flush.
+	e.enc.buf = e.enc.buf[:copy(e.enc.buf, e.enc.buf[n:])]
+	return err
+}
+
+// Buffered returns the number of encoded bytes not yet written.
+func (e *Encoder) Buffered() int {
+	return len(e.enc.buf)
+}
+
+// Written returns the number of bytes written to the underlying writer.
+func (e *Encoder) Written() int64 {
+	return e.written
+}
+
+// Encoded returns the number of bytes encoded, written or not.
+func (e *Encoder) Encoded() int64 {
+	return e.written + int64(len(e.enc.buf))
+}
+
+// Count returns the number of values encoded.
+func (e *Encoder) Count() int64 {
+	return e.count
+}
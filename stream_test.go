@@ -0,0 +1,95 @@
+package avro
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestEncoder(t *testing.T) {
+	is := is.New(t)
+
+	var b bytes.Buffer
+	e, err := NewEncoder(&b, containerSchema, WithBufferSize(8))
+	is.NoErr(err)
+	is.NoErr(e.Encode(containerItem{ID: 1, Name: "a"}))
+	is.Equal(b.Len(), 0)      // buffered
+	is.Equal(e.Buffered(), 3) // id and name
+	is.True(e.Encode(1) != nil)
+	is.Equal(e.Buffered(), 3) // invalid value not kept
+	is.NoErr(e.Encode(containerItem{ID: 2, Name: "bcdefg"}))
+	is.Equal(e.Buffered(), 0) // written at the buffer size
+	is.Equal(e.Written(), int64(11))
+	is.NoErr(e.Encode(containerItem{ID: 3, Name: ""}))
+	is.NoErr(e.Flush())
+	is.Equal(e.Encoded(), int64(13))
+	is.Equal(e.Count(), int64(3))
+	is.Equal(b.Bytes(), []byte{2, 2, 'a', 4, 12, 'b', 'c', 'd', 'e', 'f', 'g', 6, 0}) // values one after the other
+
+	_, err = NewEncoder(&b, nil)
+	is.True(err != nil) // nil schema
+}
+
+func TestEncoder_blocks(t *testing.T) {
+	is := is.New(t)
+
+	var b bytes.Buffer
+	e, err := NewEncoder(&b, Array{Items: Int}, WithCollectionBlockSize(2))
+	is.NoErr(err)
+	is.NoErr(e.Encode([]int{1, 2, 3}))
+	is.NoErr(e.Flush())
+	is.Equal(b.Bytes(), []byte{4, 2, 4, 2, 6, 0}) // blocks of 2 items
+
+	b.Reset()
+	e, err = NewEncoder(&b, Map{Values: Array{Items: Int}}, WithCollectionBlockSize(1), WithSizedBlocks())
+	is.NoErr(err)
+	is.NoErr(e.Encode(map[string][]int{"a": {1, 2}, "b": nil}))
+	is.NoErr(e.Flush())
+	is.Equal(b.Bytes(), []byte{
+		1, 18, 2, 'a', 1, 2, 2, 1, 2, 4, 0, // key "a" and its array, in sized blocks of 1
+		1, 6, 2, 'b', 0, // key "b" and an empty array
+		0,
+	})
+	var m map[string][]int
+	is.NoErr(Decode(Map{Values: Array{Items: Int}}, b.Bytes(), &m))
+	is.Equal(m, map[string][]int{"a": {1, 2}, "b": {}}) // decodes
+	d := decoder{buf: b.Bytes()}
+	is.NoErr(d.skip(Map{Values: Array{Items: Int}}))
+	is.Equal(d.pos, b.Len()) // skips by block sizes
+}
+
+type shortWriter struct{ n int }
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		return w.n, errors.New("full")
+	}
+	return len(p), nil
+}
+
+func TestEncoder_writeError(t *testing.T) {
+	is := is.New(t)
+
+	e, err := NewEncoder(&shortWriter{n: 1}, String, WithBufferSize(0))
+	is.NoErr(err)
+	is.True(e.Encode("abc") != nil) // write fails
+	is.Equal(e.Written(), int64(1))
+	is.Equal(e.Buffered(), 3) // rest kept
+}
+
+func BenchmarkEncoder_Encode(b *testing.B) {
+	e, err := NewEncoder(io.Discard, containerSchema)
+	if err != nil {
+		b.Fatal(err)
+	}
+	item := containerItem{ID: 1, Name: "item"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := e.Encode(item); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -47,6 +47,9 @@ func (u Union) Valid() error {
 }
 
 func typeKey(s Schema) string {
+	if l, ok := s.(Logical); ok {
+		return typeKey(l.Schema)
+	}
 	t := s.Type()
 	switch t {
 	case "fixed", "enum", "record":
@@ -55,17 +58,43 @@ func typeKey(s Schema) string {
 	return t
 }
 
+// unionName returns the name identifying branch s of a union: the fullname of
+// named types and the type name of others, as used by the JSON encoding.
+func unionName(s Schema) string {
+	if l, ok := s.(Logical); ok {
+		return unionName(l.Schema)
+	}
+	if n, ok := s.(NamedSchema); ok {
+		return n.Fullname()
+	}
+	return s.Type()
+}
+
 func (u Union) Validate(v interface{}) error {
 	if err := u.Valid(); err != nil {
 		return err
 	}
+	validateBranch := func(i int, v interface{}) error {
+		return u[i].Validate(v)
+	}
+	switch g := v.(type) {
+	case GenericUnion:
+		return g.validate(u, validateBranch)
+	case *GenericUnion:
+		return g.validate(u, validateBranch)
+	}
+	if w, ok := asUnionWrapper(v); ok {
+		return genericUnion(w).validate(u, validateBranch)
+	}
 	errs := map[string]error{}
 	for _, s := range u {
-		if err := s.Validate(v); err != nil {
-			errs[typeKey(s)] = err
+		err := s.Validate(v)
+		if err == nil {
+			return nil
 		}
+		errs[unionPath(s)] = err
 	}
-	if len(errs) > 0 {
+	if len(errs) == len(u) {
 		return ErrValidation{
 			error:    errors.New("value does not match any type in the union; here is a breakdown"),
 			Children: errs,
@@ -61,6 +61,30 @@ func TestUnion_Validate(t *testing.T) {
 	u = Union{mockValidPrimitiveSchema}
 	is.NoErr(u.Validate(mockValue)) // valid union validates value
 	is.True(u.Validate(0) != nil)   // valid union invalidates value
+
+	u = Union{Null, mockValidPrimitiveSchema}
+	is.NoErr(u.Validate(nil))       // value matching first type is valid
+	is.NoErr(u.Validate(mockValue)) // value matching second type is valid
+	is.True(u.Validate(0) != nil)   // value matching no type is invalid
+}
+
+func TestUnion_Validate_firstMatch(t *testing.T) {
+	is := is.New(t)
+
+	calls := 0
+	counted := mockPrimitiveSchema{
+		typeFunc:  func() string { return "counted" },
+		validFunc: func() error { return nil },
+		validateFunc: func(interface{}) error {
+			calls++
+			return nil
+		},
+	}
+	u := Union{Null, counted}
+	is.NoErr(u.Validate(nil))
+	is.Equal(calls, 0) // later branches are not tried once one matches
+	is.NoErr(u.Validate(1))
+	is.Equal(calls, 1) // tried when earlier ones do not match
 }
 
 func TestUnion_UnmarshalJSON(t *testing.T) {
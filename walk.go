@@ -0,0 +1,238 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Node is a part of a schema visited by Walk or Transform.
+type Node struct {
+	// Path locates the node from the root "$", with the segments used by
+	// ErrValidation: ".name" for record fields, ".items" for array items,
+	// ".values" for map values and "{type}" for union branches.
+	Path string
+	// Schema is the schema at the node. It is a Reference where the schema
+	// uses one.
+	Schema Schema
+	// Field is the record field whose type is Schema, or nil.
+	Field *Field
+	// Seen is true if Schema is a named type, or a Reference to one, whose
+	// children were already walked elsewhere. Its children are not walked
+	// again, which is how recursive types end.
+	Seen bool
+}
+
+// Visitor is called by Walk for each node of a schema.
+type Visitor func(n Node) error
+
+// SkipChildren is returned by a Visitor to skip the children of a node.
+var SkipChildren = errors.New(`skip children`)
+
+// Walk calls visit for s and then for each schema inside it, depth first and
+// in order: record fields, array items, map values and union branches, with
+// Logical nodes followed by their underlying schema. Each named type is
+// walked once, through its first occurrence. Walk stops at the first error
+// returned by visit, other than SkipChildren, and returns it.
+func Walk(s Schema, visit Visitor) error {
+	if s == nil {
+		return errors.New(`cannot walk nil schema`)
+	}
+	w := walker{visit: visit, seen: map[string]bool{}}
+	return w.walk(Node{Path: "$", Schema: s})
+}
+
+type walker struct {
+	visit Visitor
+	seen  map[string]bool
+}
+
+func (w walker) walk(n Node) error {
+	named, isNamed := n.Schema.(NamedSchema)
+	if isNamed {
+		n.Seen = w.seen[named.Fullname()]
+	}
+	err := w.visit(n)
+	if err == SkipChildren || n.Seen {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// A named type is seen once its children are walked, not when they are
+	// skipped.
+	if isNamed {
+		w.seen[named.Fullname()] = true
+	}
+	if r, ok := n.Schema.(Reference); ok {
+		return w.children(n.Path, r.Target())
+	}
+	return w.children(n.Path, n.Schema)
+}
+
+// children walks the schemas inside s.
+func (w walker) children(path string, s Schema) error {
+	switch s := s.(type) {
+	case Record:
+		for i := range s.Fields {
+			f := &s.Fields[i]
+			if err := w.walk(Node{Path: path + fieldPath(f.Name), Schema: f.Type, Field: f}); err != nil {
+				return err
+			}
+		}
+	case Array:
+		return w.walk(Node{Path: path + ".items", Schema: s.Items})
+	case Map:
+		return w.walk(Node{Path: path + ".values", Schema: s.Values})
+	case Union:
+		for _, t := range s {
+			if err := w.walk(Node{Path: path + unionPath(t), Schema: t}); err != nil {
+				return err
+			}
+		}
+	case Logical:
+		return w.walk(Node{Path: path, Schema: s.Schema})
+	}
+	return nil
+}
+
+// TransformFunc is called by Transform for each node of a schema, after the
+// children of the node have been transformed, and returns the schema to use
+// in its place. For field nodes, Field is a copy of the field which may be
+// modified, and returning nil removes the field.
+type TransformFunc func(n Node) (Schema, error)
+
+// Transform returns a copy of s rebuilt by calling fn for each node, in the
+// order of Walk but with children before their parents, so that fn sees the
+// transformed children. Named types are transformed once, and References to
+// them are bound to the transformed type. The result is not validated.
+func Transform(s Schema, fn TransformFunc) (Schema, error) {
+	if s == nil {
+		return nil, errors.New(`cannot transform nil schema`)
+	}
+	t := transformer{fn: fn, done: map[string]Schema{}, refs: map[string][]Reference{}}
+	out, err := t.transform(Node{Path: "$", Schema: s})
+	if err != nil {
+		return nil, err
+	}
+	if out == nil {
+		return nil, errors.New(`transform removed the root schema`)
+	}
+	for name, refs := range t.refs {
+		target, ok := t.done[name]
+		if !ok {
+			return nil, fmt.Errorf(`type "%s" was not transformed`, name)
+		}
+		for _, r := range refs {
+			if err := r.Bind(target); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+type transformer struct {
+	fn   TransformFunc
+	done map[string]Schema      // transformed named types, and those in progress
+	refs map[string][]Reference // new references to bind to the transformed types
+}
+
+func (t transformer) transform(n Node) (Schema, error) {
+	if named, ok := n.Schema.(NamedSchema); ok {
+		name := named.Fullname()
+		if _, ok := t.done[name]; ok {
+			n.Seen = true
+			n.Schema = t.reference(name)
+			return t.call(n)
+		}
+		if r, ok := n.Schema.(Reference); ok {
+			target := r.Target()
+			if target == nil {
+				return nil, fmt.Errorf(`%s: reference "%s" is not bound`, n.Path, r.Name)
+			}
+			// Transform the definition first, at the same path, and keep
+			// the reference to it.
+			if _, err := t.transform(Node{Path: n.Path, Schema: target}); err != nil {
+				return nil, err
+			}
+			n.Schema = t.reference(name)
+			return t.call(n)
+		}
+		t.done[name] = nil
+	}
+	s, err := t.children(n.Path, n.Schema)
+	if err != nil {
+		return nil, err
+	}
+	n.Schema = s
+	out, err := t.call(n)
+	if err != nil {
+		return nil, err
+	}
+	if named, ok := s.(NamedSchema); ok {
+		t.done[named.Fullname()] = out
+	}
+	return out, nil
+}
+
+// reference returns a new Reference to the transformed type name, bound when
+// the transform ends.
+func (t transformer) reference(name string) Reference {
+	r := NewReference(name)
+	t.refs[name] = append(t.refs[name], r)
+	return r
+}
+
+func (t transformer) call(n Node) (Schema, error) {
+	out, err := t.fn(n)
+	if err != nil {
+		return nil, err
+	}
+	if out == nil && n.Field == nil {
+		return nil, fmt.Errorf(`%s: only fields can be removed`, n.Path)
+	}
+	return out, nil
+}
+
+// children returns a copy of s with the schemas inside it transformed.
+func (t transformer) children(path string, s Schema) (Schema, error) {
+	switch s := s.(type) {
+	case Record:
+		fields := make([]Field, 0, len(s.Fields))
+		for _, f := range s.Fields {
+			f := f
+			ft, err := t.transform(Node{Path: path + fieldPath(f.Name), Schema: f.Type, Field: &f})
+			if err != nil {
+				return nil, err
+			}
+			if ft != nil {
+				f.Type = ft
+				fields = append(fields, f)
+			}
+		}
+		s.Fields = fields
+		return s, nil
+	case Array:
+		items, err := t.transform(Node{Path: path + ".items", Schema: s.Items})
+		s.Items = items
+		return s, err
+	case Map:
+		values, err := t.transform(Node{Path: path + ".values", Schema: s.Values})
+		s.Values = values
+		return s, err
+	case Union:
+		u := make(Union, len(s))
+		for i, b := range s {
+			var err error
+			if u[i], err = t.transform(Node{Path: path + unionPath(b), Schema: b}); err != nil {
+				return nil, err
+			}
+		}
+		return u, nil
+	case Logical:
+		underlying, err := t.transform(Node{Path: path, Schema: s.Schema})
+		s.Schema = underlying
+		return s, err
+	}
+	return s, nil
+}
@@ -0,0 +1,123 @@
+package avro
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const walkTestSchema = `{"type": "record", "name": "ns.Node", "fields": [
+	{"name": "id", "type": "long", "doc": "pii"},
+	{"name": "tags", "type": {"type": "array", "items": "string"}},
+	{"name": "attrs", "type": {"type": "map", "values": {"type": "enum", "name": "Kind", "symbols": ["A"]}}},
+	{"name": "next", "type": ["null", "Node"]},
+	{"name": "kind", "type": "Kind"},
+	{"name": "at", "type": {"type": "long", "logicalType": "timestamp-millis"}}
+]}`
+
+func TestWalk(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(walkTestSchema))
+	is.NoErr(err)
+	var paths []string
+	var seen []string
+	is.NoErr(Walk(s, func(n Node) error {
+		paths = append(paths, n.Path+" "+n.Schema.Type())
+		if n.Seen {
+			seen = append(seen, n.Path)
+		}
+		if n.Field != nil && n.Field.Name == "tags" {
+			return SkipChildren
+		}
+		return nil
+	}))
+	is.Equal(paths, []string{
+		"$ record",
+		"$.id long",
+		"$.tags array", // children skipped
+		"$.attrs map",
+		"$.attrs.values enum",
+		"$.next union",
+		"$.next{null} null",
+		"$.next{ns.Node} record",
+		"$.kind enum",
+		"$.at long",
+		"$.at long",
+	}) // depth first, in order, with paths
+	is.Equal(seen, []string{"$.next{ns.Node}", "$.kind"}) // recursion and repeats end
+
+	stop := errors.New("stop")
+	n := 0
+	err = Walk(s, func(Node) error {
+		n++
+		return stop
+	})
+	is.Equal(err, stop) // errors stop the walk
+	is.Equal(n, 1)
+}
+
+func TestWalk_skipNamedOnce(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(`{"type": "record", "name": "R", "fields": [
+		{"name": "a", "type": {"type": "record", "name": "S", "fields": [{"name": "x", "type": "int"}]}},
+		{"name": "b", "type": "S"}
+	]}`))
+	is.NoErr(err)
+	var paths []string
+	is.NoErr(Walk(s, func(n Node) error {
+		paths = append(paths, n.Path)
+		if n.Path == "$.a" {
+			is.True(!n.Seen) // first occurrence
+			return SkipChildren
+		}
+		if n.Path == "$.b" {
+			is.True(!n.Seen) // children of the first occurrence were skipped
+		}
+		return nil
+	}))
+	is.Equal(paths, []string{"$", "$.a", "$.b", "$.b.x"}) // later occurrences are walked
+}
+
+func TestTransform(t *testing.T) {
+	is := is.New(t)
+
+	s, err := SchemaUnmarshalJSON([]byte(walkTestSchema))
+	is.NoErr(err)
+	out, err := Transform(s, func(n Node) (Schema, error) {
+		if n.Field != nil && n.Field.Doc == "pii" {
+			return nil, nil // remove the field
+		}
+		if n.Field != nil && n.Field.Name == "tags" {
+			n.Field.Doc = "labels"
+		}
+		if e, ok := n.Schema.(Enum); ok {
+			e.Symbols = append(e.Symbols, "B")
+			return e, nil
+		}
+		if n.Schema == String {
+			return Bytes, nil
+		}
+		return n.Schema, nil
+	})
+	is.NoErr(err)
+	r := out.(Record)
+	is.Equal(len(r.Fields), 5) // field removed
+	f, _ := r.GetField("tags")
+	is.Equal(f.Doc, "labels")             // field modified
+	is.Equal(f.Type, Array{Items: Bytes}) // type replaced
+	f, _ = r.GetField("kind")
+	is.Equal(resolve(f.Type).(Enum).Symbols, []string{"A", "B"}) // reference bound to transformed type
+	f, _ = r.GetField("next")
+	next := resolve(f.Type.(Union)[1]).(Record)
+	is.Equal(len(next.Fields), 5) // recursive reference bound to transformed record
+	is.NoErr(out.Valid())
+
+	orig, _ := s.(Record).GetField("id")
+	is.Equal(orig.Doc, "pii") // original unchanged
+
+	_, err = Transform(s, func(n Node) (Schema, error) { return nil, nil })
+	is.True(err != nil) // only fields can be removed
+}